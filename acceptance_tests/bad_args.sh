@@ -36,6 +36,14 @@ testNullWithFilesEvalAll() {
   assertEquals "Error: cannot pass files in when using null-input flag" "$result"
 }
 
+testDirectoryWithoutRecursive() {
+  mkdir -p test.dir
+  result=$(./yq e '.a' test.dir 2>&1)
+  assertEquals 1 $?
+  assertEquals "Error: 'test.dir' is a directory, use the recursive flag to read the files in it" "$result"
+  rm -rf test.dir
+}
+
 
 
 source ./scripts/shunit2
\ No newline at end of file
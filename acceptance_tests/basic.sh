@@ -172,6 +172,39 @@ EOL
   assertEquals "10" "$X"
 }
 
+testBasicUpdateInPlaceRecursive() {
+  mkdir -p test.dir/sub
+  printf 'a: 0\n' > test.dir/test.yml
+  printf 'a: 1\n' > test.dir/sub/test.yml
+  printf 'a: 2\n' > test.dir/sub/test.txt
+  ./yq -i -r '.a += 10' test.dir
+  X=$(./yq '.a' test.dir/test.yml test.dir/sub/test.yml test.dir/sub/test.txt)
+  assertEquals "$(printf '10\n---\n11\n---\n2')" "$X"
+  rm -rf test.dir
+}
+
+testBasicUpdateInPlaceRecursiveEvalAll() {
+  mkdir -p test.dir/sub
+  printf 'a: 0\n---\na: 1\n' > test.dir/test.yml
+  printf 'a: 2\n' > test.dir/sub/test.yml
+  ./yq ea -i -r '.a += 10 | .file = fileIndex' test.dir
+  X=$(./yq -o=json -I=0 '.' test.dir/test.yml test.dir/sub/test.yml)
+  assertEquals "$(printf '{"a":10,"file":0}\n{"a":11,"file":0}\n{"a":12,"file":0}')" "$X"
+  rm -rf test.dir
+}
+
+testBasicUpdateInPlaceGlobWithBadFile() {
+  mkdir -p test.dir/sub
+  printf 'a: 0\n' > test.dir/test.yml
+  printf 'a: [\n' > test.dir/sub/bad.yml
+  X=$(./yq -i '.a = 10' 'test.dir/**/*.yml' 2>&1)
+  assertEquals 1 $?
+  assertEquals "$(printf "Error: test.dir/sub/bad.yml: bad file 'test.dir/sub/bad.yml': yaml: line 1: did not find expected node content\nError: failed to update 1 of 2 files")" "$X"
+  X=$(./yq '.a' test.dir/test.yml)
+  assertEquals "10" "$X"
+  rm -rf test.dir
+}
+
 testBasicNoExitStatus() {
   echo "a: cat" > test.yml
   X=$(./yq e '.z' test.yml)
@@ -190,6 +223,85 @@ testBasicExitStatusNoEval() {
   assertEquals 1 "$?"
 }
 
+testBasicUserError() {
+  echo "a: cat" > test.yml
+  X=$(./yq e 'error("bad " + .a)' test.yml 2>&1)
+  assertEquals 1 "$?"
+  assertEquals "Error: bad cat" "$X"
+}
+
+testBasicNamedArguments() {
+  echo "a: cat" > test.yml
+  X=$(./yq --arg b dog --argjson c '[1, 2]' '.b = $b | .c = $c[1]' test.yml)
+  assertEquals "$(printf 'a: cat\nb: dog\nc: 2')" "$X"
+
+  X=$(./yq ea --rawfile d test.yml -n '$d')
+  assertEquals "a: cat" "$X"
+}
+
+testBasicDiff() {
+  printf 'a: cat\nb: 1\n' > test.yml
+  printf 'b: 2\na: cat\n' > test2.yml
+  X=$(./yq diff --ignore-key-order test.yml test2.yml)
+  assertEquals 1 "$?"
+  assertEquals "~ .b: 1 -> 2" "$X"
+
+  X=$(./yq diff --ignore-key-order --ignore-path '.b' test.yml test2.yml)
+  assertEquals 0 "$?"
+
+  X=$(./yq diff test.yml missing.yml 2>/dev/null)
+  assertEquals 2 "$?"
+}
+
+testBasicValidate() {
+  printf '{"properties": {"a": {"type": "integer"}}}' > test-schema.json
+  printf 'a: 1\n' > test.yml
+  printf 'a: cat\n' > test2.yml
+  X=$(./yq validate --schema test-schema.json test.yml)
+  assertEquals 0 "$?"
+
+  X=$(./yq validate --schema test-schema.json test2.yml)
+  assertEquals 1 "$?"
+  assertEquals "test2.yml:1:4: .a: expected integer, got string" "$X"
+
+  X=$(./yq validate --schema missing.json test.yml 2>/dev/null)
+  assertEquals 2 "$?"
+
+  X=$(./yq validate --schema - test2.yml < test-schema.json)
+  assertEquals 1 "$?"
+  assertEquals "test2.yml:1:4: .a: expected integer, got string" "$X"
+  rm -f test-schema.json
+}
+
+testBasicInferSchema() {
+  printf 'a: cat\n' > test.yml
+  printf 'a: 1\nb: true\n' > test2.yml
+  X=$(./yq infer-schema -o=json -I=0 test.yml test2.yml)
+  assertEquals '{"$schema":"https://json-schema.org/draft/2020-12/schema","type":"object","properties":{"a":{"type":["string","integer"]},"b":{"type":"boolean"}},"required":["a"]}' "$X"
+}
+
+testBasicGrep() {
+  mkdir -p test.dir/sub
+  printf 'a: cat\n---\nb:\n  c: cat\n' > test.dir/test.yml
+  printf '{"a": "dog"}\n' > test.dir/sub/test.json
+  printf 'a: cat\n' > test.dir/sub/test.txt
+  X=$(./yq grep '.. | select(. == "cat")' test.dir)
+  assertEquals "$(printf 'test.dir/test.yml:0:1: .a = cat\ntest.dir/test.yml:1:4: .b.c = cat')" "$X"
+  X=$(./yq grep -c '.. | select(. == "cat")' test.dir)
+  assertEquals "$(printf 'test.dir/sub/test.json:0\ntest.dir/test.yml:2')" "$X"
+  X=$(./yq grep -l --include '*.txt' '.a' test.dir)
+  assertEquals "test.dir/sub/test.txt" "$X"
+  ./yq grep '.nope' test.dir
+  assertEquals 1 $?
+  rm -rf test.dir
+}
+
+testBasicPosition() {
+  printf '# comment\na:\n  b: cat\n' > test.yml
+  X=$(./yq --position '.a.b' test.yml)
+  assertEquals "test.yml:3:6: .a.b = cat" "$X"
+}
+
 testBasicExtractFieldWithSeperator() {
     cat >test.yml <<EOL
 ---
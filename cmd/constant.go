@@ -21,12 +21,15 @@ var nullInput = false
 var verbose = false
 var version = false
 var prettyPrint = false
+var showPosition = false
+
+var recursive = false
+var includePatterns = []string{}
+var excludePatterns = []string{}
 
 // can be either "" (off), "extract" or "process"
 var frontMatter = ""
 
 var splitFileExp = ""
 
-var completedSuccessfully = false
-
 var forceExpression = ""
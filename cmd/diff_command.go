@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"errors"
+
+	"github.com/mikefarah/yq/v4/pkg/yqlib"
+	"github.com/spf13/cobra"
+)
+
+var ignoreKeyOrder = false
+var ignoreComments = false
+var ignorePaths = []string{}
+
+// ExitStatusError is returned by commands that need yq to exit with a particular status.
+type ExitStatusError struct {
+	Status int
+	Err    error
+}
+
+func (e *ExitStatusError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ExitStatusError) Unwrap() error {
+	return e.Err
+}
+
+// GetExitStatus returns the status yq should exit with when the command returned the given error.
+func GetExitStatus(err error) int {
+	var exitStatusError *ExitStatusError
+	if errors.As(err, &exitStatusError) {
+		return exitStatusError.Status
+	}
+	return 1
+}
+
+func createDiffCommand() *cobra.Command {
+	var cmdDiff = &cobra.Command{
+		Use:   "diff [yaml_file1] [yaml_file2]",
+		Short: "Compare the documents in two yaml files",
+		Example: `
+# Prints the paths that were added, removed and changed
+yq diff old.yml new.yml
+
+# Ignores reordered keys, comment changes and everything under .metadata.annotations
+yq diff --ignore-key-order --ignore-comments --ignore-path '.metadata.annotations' old.yml new.yml
+
+# Prints the differences as json
+yq diff -o=json old.yml new.yml
+`,
+		Long: `yq is a portable command-line YAML processor (https://github.com/mikefarah/yq/)
+See https://mikefarah.gitbook.io/yq/ for detailed documentation and examples.
+
+## Diff ##
+Compares the data of each document in the first file with the document at the same index in the second file,
+ignoring formatting. Each difference is printed on its own line, or as a list when an output format is given.
+The exit status is 0 when the files are the same, 1 when there are differences and 2 if there was an error.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			foundDifferences, err := diff(cmd, args)
+			if err != nil {
+				return &ExitStatusError{Status: 2, Err: err}
+			}
+			if foundDifferences {
+				// the differences have already been printed, no need for an error message
+				cmd.SilenceErrors = true
+				return &ExitStatusError{Status: 1, Err: errors.New("differences found")}
+			}
+			return nil
+		},
+	}
+	cmdDiff.Flags().BoolVarP(&ignoreKeyOrder, "ignore-key-order", "", false, "don't report maps with the same keys in a different order")
+	cmdDiff.Flags().BoolVarP(&ignoreComments, "ignore-comments", "", false, "don't report changes to comments")
+	cmdDiff.Flags().StringArrayVarP(&ignorePaths, "ignore-path", "", []string{}, "expression of paths to skip, e.g. '.metadata.annotations'. Can be given more than once.")
+	return cmdDiff
+}
+
+func diff(cmd *cobra.Command, args []string) (bool, error) {
+	if len(args) != 2 {
+		return false, errors.New("diff expects two files")
+	}
+	if _, err := initCommand(cmd, args); err != nil {
+		return false, err
+	}
+	decoder, err := configureDecoder()
+	if err != nil {
+		return false, err
+	}
+
+	differences, err := yqlib.DiffFiles(args[0], args[1], decoder, yqlib.DiffPreferences{
+		IgnoreKeyOrder: ignoreKeyOrder,
+		IgnoreComments: ignoreComments,
+		IgnorePaths:    ignorePaths,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	out := cmd.OutOrStdout()
+	if cmd.Flags().Changed("output-format") || outputToJSON {
+		// machine readable output, printed like any other result
+		format, err := yqlib.OutputFormatFromString(outputFormat)
+		if err != nil {
+			return false, err
+		}
+		printer := yqlib.NewPrinter(configureEncoder(format), yqlib.NewSinglePrinterWriter(out))
+		node := &yqlib.CandidateNode{Node: yqlib.DifferencesToNode(differences)}
+		if err := printer.PrintResults(node.AsList()); err != nil {
+			return false, err
+		}
+	} else {
+		formatted, err := yqlib.FormatDifferences(differences)
+		if err != nil {
+			return false, err
+		}
+		cmd.Print(formatted)
+	}
+	return len(differences) > 0, nil
+}
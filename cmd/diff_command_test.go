@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestGetExitStatus(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{
+			name: "Plain error",
+			err:  errors.New("bad"),
+			want: 1,
+		},
+		{
+			name: "Exit status error",
+			err:  &ExitStatusError{Status: 2, Err: errors.New("bad")},
+			want: 2,
+		},
+		{
+			name: "Wrapped exit status error",
+			err:  fmt.Errorf("wrapped: %w", &ExitStatusError{Status: 3, Err: errors.New("bad")}),
+			want: 3,
+		},
+	}
+	for _, tt := range tests {
+		if got := GetExitStatus(tt.err); got != tt.want {
+			t.Errorf("%q. GetExitStatus() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
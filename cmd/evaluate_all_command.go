@@ -2,7 +2,9 @@ package cmd
 
 import (
 	"errors"
+	"io"
 	"os"
+	"reflect"
 
 	"github.com/mikefarah/yq/v4/pkg/yqlib"
 	"github.com/spf13/cobra"
@@ -23,6 +25,9 @@ yq ea -i 'select(fi == 0) * select(fi == 1)' f1.yml f2.yml
 # Merge all given files
 yq ea '. as $item ireduce ({}; . * $item )' file1.yml file2.yml ...
 
+# Merge all given files using JSON Merge Patch, where null removes a key
+yq ea 'merge_patch' file1.yml file2.yml ...
+
 # Pipe from STDIN
 ## use '-' as a filename to pipe from STDIN
 cat file2.yml | yq ea '.a.b' file1.yml - file3.yml
@@ -39,7 +44,7 @@ Note that it consumes more memory than eval.
 	}
 	return cmdEvalAll
 }
-func evaluateAll(cmd *cobra.Command, args []string) (cmdError error) {
+func evaluateAll(cmd *cobra.Command, args []string) error {
 	// 0 args, read std in
 	// 1 arg, null input, process expression
 	// 1 arg, read file in sequence
@@ -47,7 +52,7 @@ func evaluateAll(cmd *cobra.Command, args []string) (cmdError error) {
 
 	var err error
 
-	firstFileIndex, err := initCommand(cmd, args)
+	_, err = initCommand(cmd, args)
 	if err != nil {
 		return err
 	}
@@ -71,25 +76,32 @@ func evaluateAll(cmd *cobra.Command, args []string) (cmdError error) {
 	yqlib.GetLogger().Debug("ModeSticky: %v", stat.Mode()&os.ModeSticky)
 	yqlib.GetLogger().Debug("ModeIrregular: %v", stat.Mode()&os.ModeIrregular)
 
-	out := cmd.OutOrStdout()
+	expression, givenFiles := processArgs(pipingStdIn, args)
+	yqlib.GetLogger().Debugf("processed args: %v", givenFiles)
+	args, err = expandFiles(givenFiles)
+	if err != nil {
+		return err
+	}
 
 	if writeInplace {
 		// only use colors if its forced
 		colorsEnabled = forceColor
-		writeInPlaceHandler := yqlib.NewWriteInPlaceHandler(args[firstFileIndex])
-		out, err = writeInPlaceHandler.CreateTempFile()
-		if err != nil {
-			return err
+		if len(args) > 1 && !reflect.DeepEqual(args, givenFiles) {
+			// files found in directories and globs are each updated on their own, rather than
+			// merging them all into the first one
+			return writeFilesInPlace(cmd, args, func(out io.Writer, filename string) error {
+				return evaluateAllFiles(cmd, expression, []string{filename}, out)
+			})
 		}
-		// need to indirectly call the function so  that completedSuccessfully is
-		// passed when we finish execution as opposed to now
-		defer func() {
-			if cmdError == nil {
-				cmdError = writeInPlaceHandler.FinishWriteInPlace(completedSuccessfully)
-			}
-		}()
+		// the files given are evaluated together, so only the first can be updated
+		return writeFileInPlace(args[0], func(out io.Writer, filename string) error {
+			return evaluateAllFiles(cmd, expression, args, out)
+		})
 	}
+	return evaluateAllFiles(cmd, expression, args, cmd.OutOrStdout())
+}
 
+func evaluateAllFiles(cmd *cobra.Command, expression string, args []string, out io.Writer) error {
 	format, err := yqlib.OutputFormatFromString(outputFormat)
 	if err != nil {
 		return err
@@ -106,15 +118,15 @@ func evaluateAll(cmd *cobra.Command, args []string) (cmdError error) {
 	}
 	encoder := configureEncoder(format)
 
-	printer := yqlib.NewPrinter(encoder, printerWriter)
+	printer := configurePrinter(encoder, printerWriter)
 
-	if frontMatter != "" {
-		frontMatterHandler := yqlib.NewFrontMatterHandler(args[firstFileIndex])
+	if frontMatter != "" && len(args) > 0 {
+		frontMatterHandler := yqlib.NewFrontMatterHandler(args[0])
 		err = frontMatterHandler.Split()
 		if err != nil {
 			return err
 		}
-		args[firstFileIndex] = frontMatterHandler.GetYamlFrontMatterFilename()
+		args = append([]string{frontMatterHandler.GetYamlFrontMatterFilename()}, args[1:]...)
 
 		if frontMatter == "process" {
 			reader := frontMatterHandler.GetContentReader()
@@ -126,9 +138,6 @@ func evaluateAll(cmd *cobra.Command, args []string) (cmdError error) {
 
 	allAtOnceEvaluator := yqlib.NewAllAtOnceEvaluator()
 
-	expression, args := processArgs(pipingStdIn, args)
-	yqlib.GetLogger().Debugf("processed args: %v", args)
-
 	switch len(args) {
 	case 0:
 		if nullInput {
@@ -141,8 +150,6 @@ func evaluateAll(cmd *cobra.Command, args []string) (cmdError error) {
 		err = allAtOnceEvaluator.EvaluateFiles(processExpression(expression), args, printer, leadingContentPreProcessing, decoder)
 	}
 
-	completedSuccessfully = err == nil
-
 	if err == nil && exitStatus && !printer.PrintedAnything() {
 		return errors.New("no matches found")
 	}
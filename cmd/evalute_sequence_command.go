@@ -3,6 +3,7 @@ package cmd
 import (
 	"errors"
 	"fmt"
+	"io"
 	"os"
 
 	"github.com/mikefarah/yq/v4/pkg/yqlib"
@@ -53,14 +54,13 @@ func processExpression(expression string) string {
 	return expression
 }
 
-func evaluateSequence(cmd *cobra.Command, args []string) (cmdError error) {
+func evaluateSequence(cmd *cobra.Command, args []string) error {
 	// 0 args, read std in
 	// 1 arg, null input, process expression
 	// 1 arg, read file in sequence
 	// 2+ args, [0] = expression, file the rest
 
-	var err error
-	firstFileIndex, err := initCommand(cmd, args)
+	_, err := initCommand(cmd, args)
 	if err != nil {
 		return err
 	}
@@ -89,25 +89,23 @@ func evaluateSequence(cmd *cobra.Command, args []string) (cmdError error) {
 
 	yqlib.GetLogger().Debug("ModePerm: %v", stat.Mode()&os.ModePerm)
 
-	out := cmd.OutOrStdout()
+	expression, args := processArgs(pipingStdIn, args)
+	args, err = expandFiles(args)
+	if err != nil {
+		return err
+	}
 
 	if writeInplace {
 		// only use colors if its forced
 		colorsEnabled = forceColor
-		writeInPlaceHandler := yqlib.NewWriteInPlaceHandler(args[firstFileIndex])
-		out, err = writeInPlaceHandler.CreateTempFile()
-		if err != nil {
-			return err
-		}
-		// need to indirectly call the function so  that completedSuccessfully is
-		// passed when we finish execution as opposed to now
-		defer func() {
-			if cmdError == nil {
-				cmdError = writeInPlaceHandler.FinishWriteInPlace(completedSuccessfully)
-			}
-		}()
+		return writeFilesInPlace(cmd, args, func(out io.Writer, filename string) error {
+			return evaluateSequenceFiles(cmd, expression, []string{filename}, out)
+		})
 	}
+	return evaluateSequenceFiles(cmd, expression, args, cmd.OutOrStdout())
+}
 
+func evaluateSequenceFiles(cmd *cobra.Command, expression string, args []string, out io.Writer) error {
 	format, err := yqlib.OutputFormatFromString(outputFormat)
 	if err != nil {
 		return err
@@ -119,7 +117,7 @@ func evaluateSequence(cmd *cobra.Command, args []string) (cmdError error) {
 	}
 	encoder := configureEncoder(format)
 
-	printer := yqlib.NewPrinter(encoder, printerWriter)
+	printer := configurePrinter(encoder, printerWriter)
 
 	decoder, err := configureDecoder()
 	if err != nil {
@@ -127,14 +125,14 @@ func evaluateSequence(cmd *cobra.Command, args []string) (cmdError error) {
 	}
 	streamEvaluator := yqlib.NewStreamEvaluator()
 
-	if frontMatter != "" {
+	if frontMatter != "" && len(args) > 0 {
 		yqlib.GetLogger().Debug("using front matter handler")
-		frontMatterHandler := yqlib.NewFrontMatterHandler(args[firstFileIndex])
+		frontMatterHandler := yqlib.NewFrontMatterHandler(args[0])
 		err = frontMatterHandler.Split()
 		if err != nil {
 			return err
 		}
-		args[firstFileIndex] = frontMatterHandler.GetYamlFrontMatterFilename()
+		args = append([]string{frontMatterHandler.GetYamlFrontMatterFilename()}, args[1:]...)
 
 		if frontMatter == "process" {
 			reader := frontMatterHandler.GetContentReader()
@@ -143,7 +141,6 @@ func evaluateSequence(cmd *cobra.Command, args []string) (cmdError error) {
 		}
 		defer frontMatterHandler.CleanUp()
 	}
-	expression, args := processArgs(pipingStdIn, args)
 
 	switch len(args) {
 	case 0:
@@ -156,7 +153,6 @@ func evaluateSequence(cmd *cobra.Command, args []string) (cmdError error) {
 	default:
 		err = streamEvaluator.EvaluateFiles(processExpression(expression), args, printer, leadingContentPreProcessing, decoder)
 	}
-	completedSuccessfully = err == nil
 
 	if err == nil && exitStatus && !printer.PrintedAnything() {
 		return errors.New("no matches found")
@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/mikefarah/yq/v4/pkg/yqlib"
+	"github.com/spf13/cobra"
+)
+
+var filesWithMatches = false
+var countMatches = false
+
+func createGrepCommand() *cobra.Command {
+	var cmdGrep = &cobra.Command{
+		Use:   "grep [expression] [path...]",
+		Short: "Search the documents of many files, printing where the expression matches",
+		Example: `
+# Prints where the image is set to foo in any yaml, json, xml or properties file under ./deploy
+yq grep '.. | select(.image? == "foo")' ./deploy
+
+# Only prints the names of the files
+yq grep -l '.. | select(.image? == "foo")' ./deploy
+
+# Counts the Deployments in each helm template
+yq grep -c --include '*.yaml' --include '*.tpl' 'select(.kind == "Deployment")' ./charts
+`,
+		Long: `yq is a portable command-line YAML processor (https://github.com/mikefarah/yq/)
+See https://mikefarah.gitbook.io/yq/ for detailed documentation and examples.
+
+## Grep ##
+Evaluates the expression against each document of each file, like eval, and prints each result that
+isn't null or false as 'file:document:line: path = value'. Directories are searched recursively for files
+with a yaml, yml, json, xml or properties extension, skipping hidden directories; the format of each file
+is detected from its extension. Searches the current directory when no paths are given.
+The exit status is 0 when something matched, 1 when nothing matched and 2 if there was an error.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			matched, err := grep(cmd, args)
+			if err != nil {
+				return &ExitStatusError{Status: 2, Err: err}
+			}
+			if !matched {
+				cmd.SilenceErrors = true
+				return &ExitStatusError{Status: 1, Err: errors.New("no matches found")}
+			}
+			return nil
+		},
+	}
+	cmdGrep.Flags().BoolVarP(&filesWithMatches, "files-with-matches", "l", false, "only print the names of files with matches")
+	cmdGrep.Flags().BoolVarP(&countMatches, "count", "c", false, "only print the number of matches in each file")
+	return cmdGrep
+}
+
+func grep(cmd *cobra.Command, args []string) (bool, error) {
+	if len(args) == 0 {
+		return false, errors.New("grep expects an expression")
+	}
+	if filesWithMatches && countMatches {
+		return false, errors.New("grep cannot use both files-with-matches and count")
+	}
+	cmd.SilenceUsage = true
+	expression, paths := args[0], args[1:]
+	if len(paths) == 0 {
+		paths = []string{"."}
+	}
+
+	node, err := yqlib.ExpressionParser.ParseExpression(expression)
+	if err != nil {
+		return false, err
+	}
+	defaultDecoder, err := configureDecoder()
+	if err != nil {
+		return false, err
+	}
+	filenames, err := yqlib.FindFiles(paths, true, includePatterns, excludePatterns)
+	if err != nil {
+		return false, err
+	}
+
+	mode := yqlib.GrepMatches
+	if filesWithMatches {
+		mode = yqlib.GrepFilesWithMatches
+	} else if countMatches {
+		mode = yqlib.GrepCount
+	}
+	printer := yqlib.NewGrepPrinter(yqlib.NewSinglePrinterWriter(cmd.OutOrStdout()), mode)
+	streamEvaluator := yqlib.NewStreamEvaluator()
+
+	var failed error
+	for _, filename := range filenames {
+		decoder := defaultDecoder
+		if format, knownFormat := yqlib.InputFormatFromFilename(filename); knownFormat {
+			decoder = configureDecoderForFormat(format)
+		}
+		if err := grepFile(streamEvaluator, filename, node, printer, decoder); err != nil {
+			// keep searching the other files, like grep does
+			cmd.PrintErrf("Error: %v\n", err)
+			failed = errors.New("failed to search some files")
+		}
+		if err := printer.FinishFile(filename); err != nil {
+			return false, err
+		}
+	}
+	if failed != nil {
+		cmd.SilenceErrors = true
+		return printer.PrintedAnything(), failed
+	}
+	return printer.PrintedAnything(), nil
+}
+
+func grepFile(streamEvaluator yqlib.StreamEvaluator, filename string, node *yqlib.ExpressionNode, printer yqlib.GrepPrinter, decoder yqlib.Decoder) error {
+	file, err := os.Open(filename) // #nosec
+	if err != nil {
+		return err
+	}
+	defer yqlib.SafelyCloseReader(file)
+	_, err = streamEvaluator.Evaluate(filename, file, node, printer, "", decoder)
+	if err != nil && !strings.Contains(err.Error(), filename) {
+		// errors from the expression don't say which file they were in
+		return fmt.Errorf("%v: %w", filename, err)
+	}
+	return err
+}
@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"errors"
+
+	"github.com/mikefarah/yq/v4/pkg/yqlib"
+	"github.com/spf13/cobra"
+)
+
+func createInferSchemaCommand() *cobra.Command {
+	var cmdInferSchema = &cobra.Command{
+		Use:   "infer-schema [yaml_file1]...",
+		Short: "Generates a JSON Schema that all the documents of all the yaml files match",
+		Example: `
+# Prints a schema for all the values files, as yaml
+yq infer-schema values-*.yaml
+
+# Prints the schema as json
+yq infer-schema -o=json values-*.yaml > schema.json
+
+# The same as
+yq ea -o=json 'infer_schema' values-*.yaml
+`,
+		Long: `yq is a portable command-line YAML processor (https://github.com/mikefarah/yq/)
+See https://mikefarah.gitbook.io/yq/ for detailed documentation and examples.
+
+## Infer Schema ##
+Loads _all_ yaml documents of _all_ yaml files, like eval-all, and prints a draft 2020-12 JSON Schema
+as a starting point for validating them. See the infer_schema operator for details.`,
+		RunE: inferSchema,
+	}
+	return cmdInferSchema
+}
+
+func inferSchema(cmd *cobra.Command, args []string) error {
+	if len(args) == 0 {
+		return errors.New("infer-schema expects at least one file, use '-' to read from STDIN")
+	}
+	if _, err := initCommand(cmd, args); err != nil {
+		return err
+	}
+	format, err := yqlib.OutputFormatFromString(outputFormat)
+	if err != nil {
+		return err
+	}
+	decoder, err := configureDecoder()
+	if err != nil {
+		return err
+	}
+	printer := yqlib.NewPrinter(configureEncoder(format), yqlib.NewSinglePrinterWriter(cmd.OutOrStdout()))
+	return yqlib.NewAllAtOnceEvaluator().EvaluateFiles("infer_schema", args, printer, false, decoder)
+}
@@ -0,0 +1,165 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/mikefarah/yq/v4/pkg/yqlib"
+	"github.com/spf13/cobra"
+)
+
+// namedArgumentFlags take two values, a variable name and its value. Cobra can't parse flags
+// like that, so they are pulled out of the arguments (by ExtractNamedArguments) before cobra sees them.
+var namedArgumentFlags = []struct {
+	name  string
+	usage string
+	add   func(name string, value string) error
+}{
+	{
+		name:  "arg",
+		usage: "set $name to the given string.",
+		add:   yqlib.NamedArguments.AddString,
+	},
+	{
+		name:  "argjson",
+		usage: "set $name to the given json.",
+		add: func(name string, value string) error {
+			if !json.Valid([]byte(value)) {
+				return fmt.Errorf("--argjson value for $%v is not valid json", name)
+			}
+			return yqlib.NamedArguments.AddDocument(name, value)
+		},
+	},
+	{
+		name:  "argyaml",
+		usage: "set $name to the given yaml.",
+		add:   yqlib.NamedArguments.AddDocument,
+	},
+	{
+		name:  "rawfile",
+		usage: "set $name to the contents of the given file, as a string.",
+		add: func(name string, filename string) error {
+			content, err := os.ReadFile(filename) // #nosec
+			if err != nil {
+				return err
+			}
+			return yqlib.NamedArguments.AddString(name, string(content))
+		},
+	},
+	{
+		name:  "slurpfile",
+		usage: "set $name to an array of all the documents in the given file.",
+		add: func(name string, filename string) error {
+			content, err := os.ReadFile(filename) // #nosec
+			if err != nil {
+				return err
+			}
+			return yqlib.NamedArguments.AddDocuments(name, bytes.NewReader(content), yqlib.NewYamlDecoder())
+		},
+	},
+}
+
+// namedArgumentFlag only exists so the flags are listed in the help, the values are set by ExtractNamedArguments.
+type namedArgumentFlag struct {
+	name string
+}
+
+func (f *namedArgumentFlag) String() string {
+	return ""
+}
+
+func (f *namedArgumentFlag) Set(value string) error {
+	return fmt.Errorf("--%v expects a name and a value, e.g. --%v name value", f.name, f.name)
+}
+
+func (f *namedArgumentFlag) Type() string {
+	return "name value"
+}
+
+func addNamedArgumentFlags(rootCmd *cobra.Command) {
+	for _, flag := range namedArgumentFlags {
+		rootCmd.PersistentFlags().Var(&namedArgumentFlag{name: flag.name}, flag.name, flag.usage)
+	}
+}
+
+// flagTakesNextArgument checks if the argument is a flag of the command, or one of its sub commands, whose
+// value is the next argument, e.g. "-s" or "--split-exp" but not "--split-exp=.a" or "-n".
+func flagTakesNextArgument(rootCmd *cobra.Command, arg string) bool {
+	if !strings.HasPrefix(arg, "-") || strings.Contains(arg, "=") {
+		return false
+	}
+	commands := append([]*cobra.Command{rootCmd}, rootCmd.Commands()...)
+	if strings.HasPrefix(arg, "--") {
+		for _, command := range commands {
+			flag := command.Flags().Lookup(arg[2:])
+			if flag == nil {
+				flag = command.PersistentFlags().Lookup(arg[2:])
+			}
+			if flag != nil {
+				return flag.NoOptDefVal == ""
+			}
+		}
+		return false
+	}
+	// in a group of shorthands like "-Ps", a flag that takes a value uses the rest of the group, if there is any
+	shorthands := arg[1:]
+	for index := range shorthands {
+		takesValue := false
+		found := false
+		for _, command := range commands {
+			flag := command.Flags().ShorthandLookup(shorthands[index : index+1])
+			if flag == nil {
+				flag = command.PersistentFlags().ShorthandLookup(shorthands[index : index+1])
+			}
+			if flag != nil {
+				found = true
+				takesValue = flag.NoOptDefVal == ""
+				break
+			}
+		}
+		if !found {
+			return false
+		} else if takesValue {
+			return index == len(shorthands)-1
+		}
+	}
+	return false
+}
+
+// ExtractNamedArguments sets the variables given by the named argument flags (e.g. --arg name value)
+// and returns the rest of the arguments. The values of other flags, like "-s --arg", are left alone.
+func ExtractNamedArguments(rootCmd *cobra.Command, args []string) ([]string, error) {
+	remaining := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		if args[i] == "--" {
+			return append(remaining, args[i:]...), nil
+		}
+		found := false
+		for _, flag := range namedArgumentFlags {
+			if args[i] != "--"+flag.name {
+				continue
+			}
+			found = true
+			if i+2 >= len(args) {
+				return nil, fmt.Errorf("%v expects a name and a value, e.g. %v name value", args[i], args[i])
+			}
+			if err := flag.add(args[i+1], args[i+2]); err != nil {
+				return nil, err
+			}
+			i = i + 2
+			break
+		}
+		if found {
+			continue
+		}
+		remaining = append(remaining, args[i])
+		if flagTakesNextArgument(rootCmd, args[i]) && i+1 < len(args) {
+			remaining = append(remaining, args[i+1])
+			i++
+		}
+	}
+	return remaining, nil
+}
@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mikefarah/yq/v4/pkg/yqlib"
+)
+
+func TestExtractNamedArguments(t *testing.T) {
+	defer yqlib.NamedArguments.Clear()
+
+	args, err := ExtractNamedArguments(New(), []string{"-n", "--arg", "a", "cat", "--argjson", "b", `{"c": 1}`, ".d = $a", "--", "--arg"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"-n", ".d = $a", "--", "--arg"}
+	if !reflect.DeepEqual(args, expected) {
+		t.Errorf("expected %v, got %v", expected, args)
+	}
+}
+
+func TestExtractNamedArgumentsSkipsFlagValues(t *testing.T) {
+	defer yqlib.NamedArguments.Clear()
+
+	tests := []struct {
+		args []string
+		want []string
+	}{
+		{args: []string{"-s", "--arg", "--arg", "a", "cat", ".b = $a"}, want: []string{"-s", "--arg", ".b = $a"}},
+		{args: []string{"--split-exp", "--arg", ".b"}, want: []string{"--split-exp", "--arg", ".b"}},
+		{args: []string{"-Ps", "--arg", ".b"}, want: []string{"-Ps", "--arg", ".b"}},
+		{args: []string{"-s.a", "--arg", "a", "cat", ".b"}, want: []string{"-s.a", ".b"}},
+		{args: []string{"--split-exp=.a", "--arg", "a", "cat", ".b"}, want: []string{"--split-exp=.a", ".b"}},
+		{args: []string{"-n", "--arg", "a", "cat", ".b"}, want: []string{"-n", ".b"}},
+		{args: []string{"validate", "--schema", "--arg", "file.yml"}, want: []string{"validate", "--schema", "--arg", "file.yml"}},
+	}
+	for _, tt := range tests {
+		args, err := ExtractNamedArguments(New(), tt.args)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(args, tt.want) {
+			t.Errorf("%v: expected %v, got %v", tt.args, tt.want, args)
+		}
+	}
+}
+
+func TestExtractNamedArgumentsErrors(t *testing.T) {
+	defer yqlib.NamedArguments.Clear()
+
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{args: []string{"--arg", "a"}, want: "--arg expects a name and a value, e.g. --arg name value"},
+		{args: []string{"--argjson", "a", "{b"}, want: "--argjson value for $a is not valid json"},
+		{args: []string{"--arg", "a.b", "c"}, want: "'a.b' is not a valid variable name"},
+	}
+	for _, tt := range tests {
+		_, err := ExtractNamedArguments(New(), tt.args)
+		if err == nil || err.Error() != tt.want {
+			t.Errorf("%v: expected error '%v', got %v", tt.args, tt.want, err)
+		}
+	}
+}
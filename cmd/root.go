@@ -73,9 +73,13 @@ yq -i '.stuff = "foo"' myfile.yml # update myfile.yml inplace
 
 	rootCmd.PersistentFlags().IntVarP(&indent, "indent", "I", 2, "sets indent level for output")
 	rootCmd.Flags().BoolVarP(&version, "version", "V", false, "Print version information and quit")
-	rootCmd.PersistentFlags().BoolVarP(&writeInplace, "inplace", "i", false, "update the file inplace of first file given.")
+	rootCmd.PersistentFlags().BoolVarP(&writeInplace, "inplace", "i", false, "update each file given inplace. eval-all updates the first file given, as it evaluates all the files together, but updates the files found in directories and globs one by one.")
+	rootCmd.PersistentFlags().BoolVarP(&recursive, "recursive", "r", false, "read the yaml, json, xml and properties files in the directories given, and their sub directories")
+	rootCmd.PersistentFlags().StringArrayVar(&includePatterns, "include", []string{}, "only read files in directories and globs that match the glob, e.g. '*.yaml' or 'templates/*'. Can be given many times.")
+	rootCmd.PersistentFlags().StringArrayVar(&excludePatterns, "exclude", []string{}, "skip files and directories that match the glob. Can be given many times.")
 	rootCmd.PersistentFlags().BoolVarP(&unwrapScalar, "unwrapScalar", "", true, "unwrap scalar, print the value with no quotes, colors or comments")
 	rootCmd.PersistentFlags().BoolVarP(&prettyPrint, "prettyPrint", "P", false, "pretty print, shorthand for '... style = \"\"'")
+	rootCmd.PersistentFlags().BoolVarP(&showPosition, "position", "", false, "print each result on one line with its filename, line and column, e.g. 'file.yml:3:5: .a.b = cat'")
 	rootCmd.PersistentFlags().BoolVarP(&exitStatus, "exit-status", "e", false, "set exit status if there are no matches or null or false is returned")
 
 	rootCmd.PersistentFlags().BoolVarP(&forceColor, "colors", "C", false, "force print with colors")
@@ -86,9 +90,15 @@ yq -i '.stuff = "foo"' myfile.yml # update myfile.yml inplace
 
 	rootCmd.PersistentFlags().StringVarP(&splitFileExp, "split-exp", "s", "", "print each result (or doc) into a file named (exp). [exp] argument must return a string. You can use $index in the expression as the result counter.")
 
+	addNamedArgumentFlags(rootCmd)
+
 	rootCmd.AddCommand(
 		createEvaluateSequenceCommand(),
 		createEvaluateAllCommand(),
+		createDiffCommand(),
+		createValidateCommand(),
+		createInferSchemaCommand(),
+		createGrepCommand(),
 		completionCmd,
 	)
 	return rootCmd
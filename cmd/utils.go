@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"strings"
 
 	"github.com/mikefarah/yq/v4/pkg/yqlib"
 	"github.com/spf13/cobra"
@@ -39,6 +40,10 @@ func initCommand(cmd *cobra.Command, args []string) (firstFileIndex int, err err
 		return 0, fmt.Errorf("write inplace cannot be used with split file")
 	}
 
+	if writeInplace && showPosition {
+		return 0, fmt.Errorf("write inplace cannot be used with position")
+	}
+
 	if nullInput && len(args) > 1 {
 		return 0, fmt.Errorf("cannot pass files in when using null-input flag")
 	}
@@ -46,19 +51,70 @@ func initCommand(cmd *cobra.Command, args []string) (firstFileIndex int, err err
 	return firstFileIndex, nil
 }
 
+// expandFiles reads the files in the directories given when recursive, and expands globs like 'charts/**/*.yaml'.
+func expandFiles(args []string) ([]string, error) {
+	if len(args) == 0 {
+		return args, nil
+	}
+	filenames, err := yqlib.FindFiles(args, recursive, includePatterns, excludePatterns)
+	if err != nil {
+		return nil, err
+	}
+	if len(filenames) == 0 {
+		return nil, fmt.Errorf("no files found in %v", strings.Join(args, ", "))
+	}
+	return filenames, nil
+}
+
+func writeFileInPlace(filename string, evaluate func(out io.Writer, filename string) error) error {
+	writeInPlaceHandler := yqlib.NewWriteInPlaceHandler(filename)
+	out, err := writeInPlaceHandler.CreateTempFile()
+	if err != nil {
+		return err
+	}
+	err = evaluate(out, filename)
+	if finishErr := writeInPlaceHandler.FinishWriteInPlace(err == nil); err == nil {
+		err = finishErr
+	}
+	return err
+}
+
+// writeFilesInPlace updates each file independently, so that an error in one of them
+// is reported without stopping the others from being updated.
+func writeFilesInPlace(cmd *cobra.Command, filenames []string, evaluate func(out io.Writer, filename string) error) error {
+	if len(filenames) == 1 {
+		return writeFileInPlace(filenames[0], evaluate)
+	}
+	failed := 0
+	for _, filename := range filenames {
+		if err := writeFileInPlace(filename, evaluate); err != nil {
+			cmd.PrintErrf("Error: %v: %v\n", filename, err)
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("failed to update %v of %v files", failed, len(filenames))
+	}
+	return nil
+}
+
 func configureDecoder() (yqlib.Decoder, error) {
 	yqlibInputFormat, err := yqlib.InputFormatFromString(inputFormat)
 	if err != nil {
 		return nil, err
 	}
+	return configureDecoderForFormat(yqlibInputFormat), nil
+}
+
+func configureDecoderForFormat(yqlibInputFormat yqlib.InputFormat) yqlib.Decoder {
 	switch yqlibInputFormat {
 	case yqlib.XMLInputFormat:
-		return yqlib.NewXMLDecoder(xmlAttributePrefix, xmlContentName), nil
+		return yqlib.NewXMLDecoder(xmlAttributePrefix, xmlContentName)
 	case yqlib.PropertiesInputFormat:
-		return yqlib.NewPropertiesDecoder(), nil
+		return yqlib.NewPropertiesDecoder()
 	}
 
-	return yqlib.NewYamlDecoder(), nil
+	return yqlib.NewYamlDecoder()
 }
 
 func configurePrinterWriter(format yqlib.PrinterOutputFormat, out io.Writer) (yqlib.PrinterWriter, error) {
@@ -78,6 +134,13 @@ func configurePrinterWriter(format yqlib.PrinterOutputFormat, out io.Writer) (yq
 	return printerWriter, nil
 }
 
+func configurePrinter(encoder yqlib.Encoder, printerWriter yqlib.PrinterWriter) yqlib.Printer {
+	if showPosition {
+		return yqlib.NewPositionPrinter(printerWriter)
+	}
+	return yqlib.NewPrinter(encoder, printerWriter)
+}
+
 func configureEncoder(format yqlib.PrinterOutputFormat) yqlib.Encoder {
 	switch format {
 	case yqlib.JSONOutputFormat:
@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"errors"
+
+	"github.com/mikefarah/yq/v4/pkg/yqlib"
+	"github.com/spf13/cobra"
+)
+
+var schemaFile = ""
+
+func createValidateCommand() *cobra.Command {
+	var cmdValidate = &cobra.Command{
+		Use:   "validate --schema [schema_file] [yaml_file...]",
+		Short: "Validate the documents in yaml files against a JSON Schema",
+		Example: `
+# Prints each value that doesn't match the schema, with its line and column
+yq validate --schema schema.json values.yaml
+
+# Prints the violations as json
+yq validate --schema schema.json -o=json values.yaml
+`,
+		Long: `yq is a portable command-line YAML processor (https://github.com/mikefarah/yq/)
+See https://mikefarah.gitbook.io/yq/ for detailed documentation and examples.
+
+## Validate ##
+Validates each document of the given files against a draft 2020-12 or draft-07 JSON Schema, given as json or yaml.
+Each violation is printed on its own line, or as a list when an output format is given. Relative $refs are loaded
+from files relative to the schema file.
+The exit status is 0 when the files are valid, 1 when there are violations and 2 if there was an error.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			foundViolations, err := validate(cmd, args)
+			if err != nil {
+				return &ExitStatusError{Status: 2, Err: err}
+			}
+			if foundViolations {
+				// the violations have already been printed, no need for an error message
+				cmd.SilenceErrors = true
+				return &ExitStatusError{Status: 1, Err: errors.New("schema violations found")}
+			}
+			return nil
+		},
+	}
+	cmdValidate.Flags().StringVarP(&schemaFile, "schema", "", "", "JSON Schema file to validate against, '-' reads it from STDIN")
+	return cmdValidate
+}
+
+func validate(cmd *cobra.Command, args []string) (bool, error) {
+	if schemaFile == "" {
+		return false, errors.New("validate expects a --schema file")
+	}
+	if len(args) == 0 {
+		return false, errors.New("validate expects at least one file")
+	}
+	for _, arg := range args {
+		if schemaFile == "-" && arg == "-" {
+			return false, errors.New("validate cannot read both the schema and the files from STDIN")
+		}
+	}
+	if _, err := initCommand(cmd, args); err != nil {
+		return false, err
+	}
+	decoder, err := configureDecoder()
+	if err != nil {
+		return false, err
+	}
+
+	schema, err := yqlib.LoadJSONSchema(schemaFile)
+	if err != nil {
+		return false, err
+	}
+	violations, err := schema.ValidateFiles(args, decoder)
+	if err != nil {
+		return false, err
+	}
+
+	out := cmd.OutOrStdout()
+	if cmd.Flags().Changed("output-format") || outputToJSON {
+		// machine readable output, printed like any other result
+		format, err := yqlib.OutputFormatFromString(outputFormat)
+		if err != nil {
+			return false, err
+		}
+		printer := yqlib.NewPrinter(configureEncoder(format), yqlib.NewSinglePrinterWriter(out))
+		node := &yqlib.CandidateNode{Node: yqlib.SchemaViolationsToNode(violations)}
+		if err := printer.PrintResults(node.AsList()); err != nil {
+			return false, err
+		}
+	} else {
+		for _, violation := range violations {
+			cmd.Println(violation.String())
+		}
+	}
+	return len(violations) > 0, nil
+}
@@ -38,7 +38,11 @@ func (e *allAtOnceEvaluator) EvaluateCandidateNodes(expression string, inputCand
 	if err != nil {
 		return nil, err
 	}
-	context, err := e.treeNavigator.GetMatchingNodes(Context{MatchingNodes: inputCandidates}, node)
+	context, err := NamedArguments.createContext(inputCandidates)
+	if err != nil {
+		return nil, err
+	}
+	context, err = e.treeNavigator.GetMatchingNodes(context, node)
 	if err != nil {
 		return nil, err
 	}
@@ -34,6 +34,24 @@ func (n *CandidateNode) GetKey() string {
 	return fmt.Sprintf("%v%v - %v", keyPrefix, n.Document, n.Path)
 }
 
+// GetLine returns the line the node was parsed from, or 0 if it was created by an expression.
+func (n *CandidateNode) GetLine() int {
+	return unwrapDoc(n.Node).Line
+}
+
+// GetColumn returns the column the node was parsed from, or 0 if it was created by an expression.
+func (n *CandidateNode) GetColumn() int {
+	return unwrapDoc(n.Node).Column
+}
+
+// GetKeyLine returns the line of the node's key, or 0 if it isn't a value of a map.
+func (n *CandidateNode) GetKeyLine() int {
+	if n.Key == nil || n.Parent == nil || unwrapDoc(n.Parent.Node).Kind != yaml.MappingNode {
+		return 0
+	}
+	return n.Key.Line
+}
+
 func (n *CandidateNode) GetNiceTag() string {
 	return unwrapDoc(n.Node).Tag
 }
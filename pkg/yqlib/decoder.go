@@ -3,6 +3,8 @@ package yqlib
 import (
 	"fmt"
 	"io"
+	"path/filepath"
+	"strings"
 
 	yaml "gopkg.in/yaml.v3"
 )
@@ -32,3 +34,17 @@ func InputFormatFromString(format string) (InputFormat, error) {
 		return 0, fmt.Errorf("unknown format '%v' please use [yaml|xml|props]", format)
 	}
 }
+
+// InputFormatFromFilename detects the format from the file's extension, returning false if it isn't one yq reads.
+// Json files are read as yaml.
+func InputFormatFromFilename(filename string) (InputFormat, bool) {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".yaml", ".yml", ".json":
+		return YamlInputFormat, true
+	case ".xml":
+		return XMLInputFormat, true
+	case ".properties":
+		return PropertiesInputFormat, true
+	}
+	return 0, false
+}
@@ -0,0 +1,458 @@
+package yqlib
+
+import (
+	"container/list"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/jinzhu/copier"
+	yaml "gopkg.in/yaml.v3"
+)
+
+type DifferenceType string
+
+const (
+	DifferenceAdded     DifferenceType = "added"
+	DifferenceRemoved   DifferenceType = "removed"
+	DifferenceChanged   DifferenceType = "changed"
+	DifferenceReordered DifferenceType = "reordered"
+	DifferenceComment   DifferenceType = "comment"
+)
+
+// Difference is a single change between two documents. For reordered maps From and To are the
+// keys in their original order, for comments they are the comment strings.
+type Difference struct {
+	Type     DifferenceType
+	Document uint
+	Path     []interface{}
+	From     *yaml.Node
+	To       *yaml.Node
+}
+
+type DiffPreferences struct {
+	IgnoreKeyOrder bool
+	IgnoreComments bool
+	// IgnorePaths are expressions (e.g. '.metadata.annotations') of the paths to skip.
+	IgnorePaths []string
+}
+
+type differ struct {
+	prefs             DiffPreferences
+	ignoreExpressions []*ExpressionNode
+	ignoredPaths      [][]interface{}
+	document          uint
+	differences       []*Difference
+}
+
+func newDiffer(prefs DiffPreferences) (*differ, error) {
+	d := &differ{prefs: prefs}
+	for _, path := range prefs.IgnorePaths {
+		expression, err := ExpressionParser.ParseExpression(path)
+		if err != nil {
+			return nil, fmt.Errorf("bad ignore path '%v': %w", path, err)
+		}
+		d.ignoreExpressions = append(d.ignoreExpressions, expression)
+	}
+	return d, nil
+}
+
+// DiffFiles compares each document in the first file with the document at the same index in the second.
+func DiffFiles(lhsFilename string, rhsFilename string, decoder Decoder, prefs DiffPreferences) ([]*Difference, error) {
+	lhsDocuments, err := readDiffDocuments(lhsFilename, 0, decoder)
+	if err != nil {
+		return nil, err
+	}
+	rhsDocuments, err := readDiffDocuments(rhsFilename, 1, decoder)
+	if err != nil {
+		return nil, err
+	}
+	d, err := newDiffer(prefs)
+	if err != nil {
+		return nil, err
+	}
+	lhsEl := lhsDocuments.Front()
+	rhsEl := rhsDocuments.Front()
+	for lhsEl != nil || rhsEl != nil {
+		var lhs, rhs *yaml.Node
+		if lhsEl != nil {
+			lhs = lhsEl.Value.(*CandidateNode).Node
+			lhsEl = lhsEl.Next()
+		}
+		if rhsEl != nil {
+			rhs = rhsEl.Value.(*CandidateNode).Node
+			rhsEl = rhsEl.Next()
+		}
+		if err := d.diffDocument(lhs, rhs); err != nil {
+			return nil, err
+		}
+	}
+	return d.differences, nil
+}
+
+func readDiffDocuments(filename string, fileIndex int, decoder Decoder) (*list.List, error) {
+	reader, _, err := readStream(filename, false)
+	if err != nil {
+		return nil, err
+	}
+	return readDocuments(reader, filename, fileIndex, decoder)
+}
+
+// DiffNodes compares two documents.
+func DiffNodes(lhs *yaml.Node, rhs *yaml.Node, prefs DiffPreferences) ([]*Difference, error) {
+	d, err := newDiffer(prefs)
+	if err != nil {
+		return nil, err
+	}
+	if err := d.diffDocument(lhs, rhs); err != nil {
+		return nil, err
+	}
+	return d.differences, nil
+}
+
+// diffDocument compares the next pair of documents, either may be nil when one file has more documents than the other.
+func (d *differ) diffDocument(lhs *yaml.Node, rhs *yaml.Node) error {
+	d.ignoredPaths = nil
+	for _, node := range []*yaml.Node{lhs, rhs} {
+		if node == nil {
+			continue
+		}
+		if err := d.addIgnoredPaths(node); err != nil {
+			return err
+		}
+	}
+
+	if lhs == nil {
+		d.add(DifferenceAdded, []interface{}{}, nil, unwrapDoc(rhs))
+	} else if rhs == nil {
+		d.add(DifferenceRemoved, []interface{}{}, unwrapDoc(lhs), nil)
+	} else {
+		if !d.prefs.IgnoreComments {
+			d.diffComments([]interface{}{}, []*yaml.Node{lhs, unwrapDoc(lhs)}, []*yaml.Node{rhs, unwrapDoc(rhs)})
+		}
+		d.diffNodes([]interface{}{}, unwrapDoc(lhs), unwrapDoc(rhs))
+	}
+	d.document++
+	return nil
+}
+
+func (d *differ) addIgnoredPaths(document *yaml.Node) error {
+	navigator := NewDataTreeNavigator()
+	for _, expression := range d.ignoreExpressions {
+		context := Context{MatchingNodes: (&CandidateNode{Node: document}).AsList()}
+		result, err := navigator.GetMatchingNodes(context.ReadOnlyClone(), expression)
+		if err != nil {
+			return err
+		}
+		for el := result.MatchingNodes.Front(); el != nil; el = el.Next() {
+			d.ignoredPaths = append(d.ignoredPaths, el.Value.(*CandidateNode).Path)
+		}
+	}
+	return nil
+}
+
+func (d *differ) isIgnored(path []interface{}) bool {
+	for _, ignored := range d.ignoredPaths {
+		if len(ignored) > len(path) {
+			continue
+		}
+		matches := true
+		for i := range ignored {
+			if fmt.Sprintf("%v", ignored[i]) != fmt.Sprintf("%v", path[i]) {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			return true
+		}
+	}
+	return false
+}
+
+func (d *differ) add(differenceType DifferenceType, path []interface{}, from *yaml.Node, to *yaml.Node) {
+	d.differences = append(d.differences, &Difference{
+		Type:     differenceType,
+		Document: d.document,
+		Path:     path,
+		From:     from,
+		To:       to,
+	})
+}
+
+func childPath(path []interface{}, child interface{}) []interface{} {
+	newPath := make([]interface{}, len(path), len(path)+1)
+	copy(newPath, path)
+	return append(newPath, child)
+}
+
+func followAlias(node *yaml.Node) *yaml.Node {
+	for node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	return node
+}
+
+func (d *differ) diffNodes(path []interface{}, lhs *yaml.Node, rhs *yaml.Node) {
+	if d.isIgnored(path) {
+		return
+	}
+	lhs = followAlias(lhs)
+	rhs = followAlias(rhs)
+
+	if lhs.Kind != rhs.Kind {
+		d.add(DifferenceChanged, path, lhs, rhs)
+	} else if lhs.Kind == yaml.MappingNode {
+		d.diffMaps(path, lhs, rhs)
+	} else if lhs.Kind == yaml.SequenceNode {
+		d.diffSequences(path, lhs, rhs)
+	} else if !recursiveNodeEqual(lhs, rhs) {
+		d.add(DifferenceChanged, path, lhs, rhs)
+	}
+}
+
+func (d *differ) diffMaps(path []interface{}, lhs *yaml.Node, rhs *yaml.Node) {
+	if !d.prefs.IgnoreKeyOrder {
+		d.diffKeyOrder(path, lhs, rhs)
+	}
+	for index := 0; index < len(lhs.Content); index = index + 2 {
+		key := lhs.Content[index]
+		keyPath := childPath(path, key.Value)
+		indexInRHS := findKeyInMap(rhs, key)
+		if indexInRHS == -1 {
+			if !d.isIgnored(keyPath) {
+				d.add(DifferenceRemoved, keyPath, followAlias(lhs.Content[index+1]), nil)
+			}
+			continue
+		}
+		if !d.prefs.IgnoreComments && !d.isIgnored(keyPath) {
+			d.diffComments(keyPath, lhs.Content[index:index+2], rhs.Content[indexInRHS:indexInRHS+2])
+		}
+		d.diffNodes(keyPath, lhs.Content[index+1], rhs.Content[indexInRHS+1])
+	}
+	for index := 0; index < len(rhs.Content); index = index + 2 {
+		key := rhs.Content[index]
+		keyPath := childPath(path, key.Value)
+		if findKeyInMap(lhs, key) == -1 && !d.isIgnored(keyPath) {
+			d.add(DifferenceAdded, keyPath, nil, followAlias(rhs.Content[index+1]))
+		}
+	}
+}
+
+// diffKeyOrder only compares the order of the keys in both maps, added and removed keys are reported separately.
+func (d *differ) diffKeyOrder(path []interface{}, lhs *yaml.Node, rhs *yaml.Node) {
+	commonKeys := func(node *yaml.Node, other *yaml.Node) []*yaml.Node {
+		var keys []*yaml.Node
+		for index := 0; index < len(node.Content); index = index + 2 {
+			if findKeyInMap(other, node.Content[index]) != -1 {
+				keys = append(keys, node.Content[index])
+			}
+		}
+		return keys
+	}
+	lhsOrder := commonKeys(lhs, rhs)
+	rhsOrder := commonKeys(rhs, lhs)
+	for i := range lhsOrder {
+		if lhsOrder[i].Value != rhsOrder[i].Value {
+			d.add(DifferenceReordered, path, createKeysNode(lhsOrder), createKeysNode(rhsOrder))
+			return
+		}
+	}
+}
+
+// findKeyInMap returns the index of the key in the map content, unlike findInArray it never matches a value.
+func findKeyInMap(node *yaml.Node, key *yaml.Node) int {
+	for index := 0; index < len(node.Content); index = index + 2 {
+		if recursiveNodeEqual(node.Content[index], key) {
+			return index
+		}
+	}
+	return -1
+}
+
+func createKeysNode(keys []*yaml.Node) *yaml.Node {
+	node := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+	for _, key := range keys {
+		node.Content = append(node.Content, createScalarNode(key.Value, key.Value))
+	}
+	return node
+}
+
+func (d *differ) diffSequences(path []interface{}, lhs *yaml.Node, rhs *yaml.Node) {
+	for index := 0; index < len(lhs.Content) || index < len(rhs.Content); index++ {
+		indexPath := childPath(path, index)
+		if d.isIgnored(indexPath) {
+			continue
+		}
+		if index >= len(rhs.Content) {
+			d.add(DifferenceRemoved, indexPath, followAlias(lhs.Content[index]), nil)
+		} else if index >= len(lhs.Content) {
+			d.add(DifferenceAdded, indexPath, nil, followAlias(rhs.Content[index]))
+		} else {
+			if !d.prefs.IgnoreComments {
+				d.diffComments(indexPath, lhs.Content[index:index+1], rhs.Content[index:index+1])
+			}
+			d.diffNodes(indexPath, lhs.Content[index], rhs.Content[index])
+		}
+	}
+}
+
+// diffComments compares all the comments on the given nodes, e.g. a map key and its value.
+func (d *differ) diffComments(path []interface{}, lhs []*yaml.Node, rhs []*yaml.Node) {
+	lhsComments := collectComments(lhs)
+	rhsComments := collectComments(rhs)
+	if lhsComments != rhsComments {
+		d.add(DifferenceComment, path, createScalarNode(lhsComments, lhsComments), createScalarNode(rhsComments, rhsComments))
+	}
+}
+
+func collectComments(nodes []*yaml.Node) string {
+	var comments []string
+	for _, node := range nodes {
+		for _, comment := range []string{node.HeadComment, node.LineComment, node.FootComment} {
+			if comment != "" {
+				comments = append(comments, comment)
+			}
+		}
+	}
+	return strings.Join(comments, "\n")
+}
+
+var simplePathKeyRegex = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_-]*$`)
+
+// PathToString formats a path the same way it would be written in an expression, e.g. .a.b[0]
+func PathToString(path []interface{}) string {
+	if len(path) == 0 {
+		return "."
+	}
+	var builder strings.Builder
+	for index, element := range path {
+		switch element := element.(type) {
+		case int:
+			if index == 0 {
+				builder.WriteString(".")
+			}
+			builder.WriteString(fmt.Sprintf("[%v]", element))
+		default:
+			key := fmt.Sprintf("%v", element)
+			if simplePathKeyRegex.MatchString(key) {
+				builder.WriteString("." + key)
+			} else {
+				builder.WriteString("." + strconv.Quote(key))
+			}
+		}
+	}
+	return builder.String()
+}
+
+// formatFlowNode prints the node on a single line, for the human readable diff.
+func formatFlowNode(node *yaml.Node) (string, error) {
+	copied := &yaml.Node{}
+	if err := copier.CopyWithOption(copied, node, copier.Option{DeepCopy: true}); err != nil {
+		return "", err
+	}
+	setFlowStyle(copied)
+	bytes, err := yaml.Marshal(copied)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(string(bytes), "\n"), nil
+}
+
+func setFlowStyle(node *yaml.Node) {
+	node.HeadComment = ""
+	node.LineComment = ""
+	node.FootComment = ""
+	if node.Kind == yaml.ScalarNode {
+		if strings.Contains(node.Value, "\n") {
+			node.Style = yaml.DoubleQuotedStyle
+		} else if node.Style == yaml.LiteralStyle || node.Style == yaml.FoldedStyle {
+			node.Style = 0
+		}
+	} else {
+		node.Style = yaml.FlowStyle
+	}
+	for _, child := range node.Content {
+		setFlowStyle(child)
+	}
+}
+
+// String formats the difference as a single line, e.g. "~ .a: 1 -> 2".
+func (diff *Difference) String() (string, error) {
+	path := PathToString(diff.Path)
+	switch diff.Type {
+	case DifferenceComment:
+		return fmt.Sprintf("# %v: comment %v -> %v", path, strconv.Quote(diff.From.Value), strconv.Quote(diff.To.Value)), nil
+	case DifferenceAdded:
+		to, err := formatFlowNode(diff.To)
+		return fmt.Sprintf("+ %v: %v", path, to), err
+	case DifferenceRemoved:
+		from, err := formatFlowNode(diff.From)
+		return fmt.Sprintf("- %v: %v", path, from), err
+	}
+	from, err := formatFlowNode(diff.From)
+	if err != nil {
+		return "", err
+	}
+	to, err := formatFlowNode(diff.To)
+	if err != nil {
+		return "", err
+	}
+	if diff.Type == DifferenceReordered {
+		return fmt.Sprintf("~ %v: key order %v -> %v", path, from, to), nil
+	}
+	return fmt.Sprintf("~ %v: %v -> %v", path, from, to), nil
+}
+
+// FormatDifferences prints one difference per line. When there are differences past the first
+// document, each line is prefixed with its document index.
+func FormatDifferences(differences []*Difference) (string, error) {
+	showDocument := false
+	for _, diff := range differences {
+		showDocument = showDocument || diff.Document > 0
+	}
+	var builder strings.Builder
+	for _, diff := range differences {
+		line, err := diff.String()
+		if err != nil {
+			return "", err
+		}
+		if showDocument {
+			builder.WriteString(fmt.Sprintf("[%v] ", diff.Document))
+		}
+		builder.WriteString(line + "\n")
+	}
+	return builder.String(), nil
+}
+
+// DifferencesToNode creates an array with a map for each difference, for printing as yaml or json.
+func DifferencesToNode(differences []*Difference) *yaml.Node {
+	seq := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+	for _, diff := range differences {
+		pathNode := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		for _, element := range diff.Path {
+			switch element := element.(type) {
+			case int:
+				pathNode.Content = append(pathNode.Content, createIntNode(element))
+			default:
+				key := fmt.Sprintf("%v", element)
+				pathNode.Content = append(pathNode.Content, createScalarNode(key, key))
+			}
+		}
+		entry := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		entry.Content = append(entry.Content,
+			createScalarNode("type", "type"), createScalarNode(string(diff.Type), string(diff.Type)),
+			createScalarNode("document", "document"), createIntNode(int(diff.Document)),
+			createScalarNode("path", "path"), pathNode,
+		)
+		if diff.From != nil {
+			entry.Content = append(entry.Content, createScalarNode("from", "from"), diff.From)
+		}
+		if diff.To != nil {
+			entry.Content = append(entry.Content, createScalarNode("to", "to"), diff.To)
+		}
+		seq.Content = append(seq.Content, entry)
+	}
+	return seq
+}
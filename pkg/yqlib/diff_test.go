@@ -0,0 +1,139 @@
+package yqlib
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/mikefarah/yq/v4/test"
+)
+
+type diffScenario struct {
+	description string
+	lhs         string
+	rhs         string
+	prefs       DiffPreferences
+	expected    string
+}
+
+var diffScenarios = []diffScenario{
+	{
+		description: "Same data, different formatting",
+		lhs:         "a: {b: [1, 2]}\n",
+		rhs:         "a:\n  b:\n    - 1\n    - 2\n",
+		expected:    "",
+	},
+	{
+		description: "Added, removed and changed",
+		lhs:         "a: 1\nb: {c: cat}\nd: [1, 2, 3]\n",
+		rhs:         "a: 2\nb: {c: dog, e: [x]}\nd: [1, 2]\n",
+		expected: `~ .a: 1 -> 2
+~ .b.c: cat -> dog
++ .b.e: [x]
+- .d[2]: 3
+`,
+	},
+	{
+		description: "Types are compared too",
+		lhs:         "a: 1\nb: [1]\n",
+		rhs:         "a: \"1\"\nb: {c: 1}\n",
+		expected:    "~ .a: 1 -> \"1\"\n~ .b: [1] -> {c: 1}\n",
+	},
+	{
+		description: "Reordered keys",
+		lhs:         "a: 1\nb: 2\nc: 3\n",
+		rhs:         "c: 3\nb: 2\nd: 4\n",
+		expected:    "~ .: key order [b, c] -> [c, b]\n- .a: 1\n+ .d: 4\n",
+	},
+	{
+		description: "Ignoring key order",
+		lhs:         "a: 1\nb: 2\n",
+		rhs:         "b: 2\na: 1\n",
+		prefs:       DiffPreferences{IgnoreKeyOrder: true},
+		expected:    "",
+	},
+	{
+		description: "Comments",
+		lhs:         "# head\na: 1 # one\nb: [x]\n",
+		rhs:         "# head\na: 1\nb:\n  # first\n  - x\n",
+		expected:    "# .a: comment \"# head\\n# one\" -> \"# head\"\n# .b[0]: comment \"\" -> \"# first\"\n",
+	},
+	{
+		description: "Ignoring comments",
+		lhs:         "a: 1 # one\n",
+		rhs:         "# head\na: 1\n",
+		prefs:       DiffPreferences{IgnoreComments: true},
+		expected:    "",
+	},
+	{
+		description: "Ignoring paths",
+		lhs:         "metadata: {name: a, labels: {x: 1}}\nitems: [{id: 1, time: 1}, {id: 2, time: 1}]\n",
+		rhs:         "metadata: {name: b, labels: {x: 2}}\nitems: [{id: 1, time: 2}, {id: 2, time: 3}]\n",
+		prefs:       DiffPreferences{IgnorePaths: []string{".metadata.labels", ".items[].time"}},
+		expected:    "~ .metadata.name: a -> b\n",
+	},
+	{
+		description: "Aliases are followed",
+		lhs:         "a: &x {b: 1}\nc: *x\n",
+		rhs:         "a: {b: 1}\nc: {b: 1}\n",
+		expected:    "",
+	},
+	{
+		description: "Root arrays",
+		lhs:         "[1, 2]\n",
+		rhs:         "[1, 3]\n",
+		expected:    "~ .[1]: 2 -> 3\n",
+	},
+	{
+		description: "Keys that need quoting",
+		lhs:         "a.b: 1\n\"\": 1\n",
+		rhs:         "a.b: 2\n\"\": 2\n",
+		expected:    "~ .\"a.b\": 1 -> 2\n~ .\"\": 1 -> 2\n",
+	},
+}
+
+func testDiffScenario(t *testing.T, s diffScenario) {
+	lhs := test.ParseData(s.lhs)
+	rhs := test.ParseData(s.rhs)
+	differences, err := DiffNodes(&lhs, &rhs, s.prefs)
+	if err != nil {
+		t.Error(fmt.Errorf("%w: %v", err, s.description))
+		return
+	}
+	formatted, err := FormatDifferences(differences)
+	if err != nil {
+		t.Error(fmt.Errorf("%w: %v", err, s.description))
+		return
+	}
+	test.AssertResultWithContext(t, s.expected, formatted, s.description)
+}
+
+func TestDiffScenarios(t *testing.T) {
+	InitExpressionParser()
+	for _, s := range diffScenarios {
+		testDiffScenario(t, s)
+	}
+}
+
+func TestDifferencesToNode(t *testing.T) {
+	InitExpressionParser()
+	lhs := test.ParseData("a: 1\nb: [x]\n")
+	rhs := test.ParseData("a: 2\nb: []\nc: true\n")
+	differences, err := DiffNodes(&lhs, &rhs, DiffPreferences{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var output bytes.Buffer
+	writer := bufio.NewWriter(&output)
+	printer := NewPrinter(NewJONEncoder(0), NewSinglePrinterWriter(writer))
+	if err := printer.PrintResults((&CandidateNode{Node: DifferencesToNode(differences)}).AsList()); err != nil {
+		t.Fatal(err)
+	}
+	writer.Flush()
+
+	test.AssertResult(t, `[{"type":"changed","document":0,"path":["a"],"from":1,"to":2},`+
+		`{"type":"removed","document":0,"path":["b",0],"from":"x"},`+
+		`{"type":"added","document":0,"path":["c"],"to":true}]`+"\n", output.String())
+}
@@ -63,8 +63,19 @@ func TestParserTwoArgsForTwoArgOp(t *testing.T) {
 }
 
 func TestParserNoArgsForOneArgOp(t *testing.T) {
-	_, err := getExpressionParser().ParseExpression("explode")
-	test.AssertResultComplex(t, "'explode' expects 1 arg but received none", err.Error())
+	_, err := getExpressionParser().ParseExpression("select")
+	test.AssertResultComplex(t, "'select' expects 1 arg but received none", err.Error())
+}
+
+func TestParserNoArgsForExplodeIsStringExplode(t *testing.T) {
+	// explode(exp) dereferences aliases, explode on its own is jq's string explode
+	node, err := getExpressionParser().ParseExpression("explode")
+	test.AssertResultComplex(t, nil, err)
+	test.AssertResult(t, "EXPLODE_STRING", node.Operation.OperationType.Type)
+
+	node, err = getExpressionParser().ParseExpression("explode(.)")
+	test.AssertResultComplex(t, nil, err)
+	test.AssertResult(t, "EXPLODE", node.Operation.OperationType.Type)
 }
 
 func TestParserOneArgForOneArgOp(t *testing.T) {
@@ -76,3 +87,8 @@ func TestParserExtraArgs(t *testing.T) {
 	_, err := getExpressionParser().ParseExpression("sortKeys(.) explode(.)")
 	test.AssertResultComplex(t, "Bad expression, please check expression syntax", err.Error())
 }
+
+func TestParserPrefixReduceWithoutBlock(t *testing.T) {
+	_, err := getExpressionParser().ParseExpression("reduce .[] as $x (0")
+	test.AssertResultComplex(t, "reduce expects the form 'reduce <exp> as $<name> (<init>; <update>)'", err.Error())
+}
@@ -109,6 +109,12 @@ func (p *expressionPostFixerImpl) ConvertToPostfix(infixTokens []*token) ([]*Ope
 			// now we should have ( as the last element on the opStack, get rid of it
 			opStack = opStack[0 : len(opStack)-1]
 
+			// like traverse array, slices apply as soon as their brackets close
+			// so that .[1:3][0] works
+			if len(opStack) > 0 && opStack[len(opStack)-1].Operation != nil && opStack[len(opStack)-1].Operation.OperationType == sliceOpType {
+				opStack, result = popOpToResult(opStack, result)
+			}
+
 		default:
 			var currentPrecedence = currentToken.Operation.OperationType.Precedence
 			// pop off higher precedent operators onto the result
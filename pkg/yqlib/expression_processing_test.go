@@ -290,6 +290,61 @@ var pathTests = []struct {
 		append(make([]interface{}, 0), "foo*", "PIPE", "(", "SELF", "ASSIGN_STYLE", "flow (string)", ")"),
 		append(make([]interface{}, 0), "foo*", "SELF", "flow (string)", "ASSIGN_STYLE", "PIPE"),
 	},
+	{
+		`try .a.b catch "x" | .c`,
+		append(make([]interface{}, 0), "TRY", "a", "SHORT_PIPE", "b", "CATCH", "x (string)", "PIPE", "c"),
+		append(make([]interface{}, 0), "a", "b", "SHORT_PIPE", "TRY", "x (string)", "CATCH", "c", "PIPE"),
+	},
+	{
+		`.a | (.b + 1)?`,
+		append(make([]interface{}, 0), "a", "PIPE", "(", "b", "ADD", "1 (int64)", ")", "OPTIONAL"),
+		append(make([]interface{}, 0), "a", "b", "1 (int64)", "ADD", "OPTIONAL", "PIPE"),
+	},
+	{
+		`reduce .a as $x (0; . + $x) | .b`,
+		append(make([]interface{}, 0), "(", "a", "ASSIGN_VARIABLE", "GET_VARIABLE", "REDUCE", "(", "0 (int64)", "BLOCK", "SELF", "ADD", "GET_VARIABLE", ")", ")", "PIPE", "b"),
+		append(make([]interface{}, 0), "a", "GET_VARIABLE", "ASSIGN_VARIABLE", "0 (int64)", "SELF", "GET_VARIABLE", "ADD", "BLOCK", "REDUCE", "b", "PIPE"),
+	},
+	{
+		`. as [$a] ?// $a | $a`,
+		append(make([]interface{}, 0), "SELF", "ASSIGN_VARIABLE", "[", "GET_VARIABLE", "]", "ALTERNATIVE_PATTERN", "GET_VARIABLE", "PIPE", "GET_VARIABLE"),
+		append(make([]interface{}, 0), "SELF", "GET_VARIABLE", "COLLECT", "GET_VARIABLE", "ALTERNATIVE_PATTERN", "ASSIGN_VARIABLE", "GET_VARIABLE", "PIPE"),
+	},
+	{
+		`first | first(.a)`,
+		append(make([]interface{}, 0), "FIRST_ELEMENT", "PIPE", "FIRST", "(", "a", ")"),
+		append(make([]interface{}, 0), "FIRST_ELEMENT", "a", "FIRST", "PIPE"),
+	},
+	{
+		`"a \(.b) c"`,
+		append(make([]interface{}, 0), "(", "a  (string)", "ADD", "(", "(", "b", ")", "PIPE", "TO_STRING", ")", "ADD", " c (string)", ")"),
+		append(make([]interface{}, 0), "a  (string)", "b", "TO_STRING", "PIPE", " c (string)", "ADD", "ADD"),
+	},
+	{
+		`@sh "echo \(.a // "none")"`,
+		append(make([]interface{}, 0), "(", "echo  (string)", "ADD", "(", "(", "a", "ALTERNATIVE", "none (string)", ")", "PIPE", "ENCODE_SHELL", ")", ")"),
+		append(make([]interface{}, 0), "echo  (string)", "a", "none (string)", "ALTERNATIVE", "ENCODE_SHELL", "PIPE", "ADD"),
+	},
+	{
+		`.a[1:][0]`,
+		append(make([]interface{}, 0), "a", "SLICE", "(", "1 (int64)", "BLOCK", "<nil> (<nil>)", ")", "TRAVERSE_ARRAY", "[", "0 (int64)", "]"),
+		append(make([]interface{}, 0), "a", "1 (int64)", "<nil> (<nil>)", "BLOCK", "SLICE", "0 (int64)", "COLLECT", "TRAVERSE_ARRAY"),
+	},
+	{
+		`{a: 1}`,
+		append(make([]interface{}, 0), "{", "a (string)", "CREATE_MAP", "1 (int64)", "}"),
+		append(make([]interface{}, 0), "a (string)", "1 (int64)", "CREATE_MAP", "COLLECT_OBJECT", "SHORT_PIPE"),
+	},
+	{
+		`{length: 1, keys: .a}`, // bare keys that are also operator names are literal keys, like jq
+		append(make([]interface{}, 0), "{", "length (string)", "CREATE_MAP", "1 (int64)", "UNION", "keys (string)", "CREATE_MAP", "a", "}"),
+		append(make([]interface{}, 0), "length (string)", "1 (int64)", "CREATE_MAP", "keys (string)", "a", "CREATE_MAP", "UNION", "COLLECT_OBJECT", "SHORT_PIPE"),
+	},
+	{
+		`{(length): 1}`,
+		append(make([]interface{}, 0), "{", "(", "LENGTH", ")", "CREATE_MAP", "1 (int64)", "}"),
+		append(make([]interface{}, 0), "LENGTH", "1 (int64)", "CREATE_MAP", "COLLECT_OBJECT", "SHORT_PIPE"),
+	},
 }
 
 var tokeniser = newExpressionTokeniser()
@@ -33,6 +33,9 @@ type token struct {
 	AssignOperation      *Operation      // e.g. tag (GetTag) op becomes AssignTag if '=' follows it
 	CheckForPostTraverse bool            // e.g. [1]cat should really be [1].cat
 	Match                *machines.Match // match that created this token
+	CallOperation        *Operation      // e.g. first becomes first(f) if '(' follows it
+	IsMapKey             bool            // e.g. {a: 1}, the bare word 'a' is followed by an implicit create map ':'
+	IsPrefixReduction    bool            // e.g. reduce .[] as $x (0; . + $x) needs rewriting to infix
 
 }
 
@@ -103,7 +106,7 @@ func assignOpToken(updateAssign bool) lex.Action {
 func multiplyWithPrefs(op *operationType) lex.Action {
 	return func(s *lex.Scanner, m *machines.Match) (interface{}, error) {
 		prefs := multiplyPreferences{}
-		options := string(m.Bytes)
+		options := strings.TrimSpace(string(m.Bytes))
 		if strings.Contains(options, "+") {
 			prefs.AppendArrays = true
 		}
@@ -116,6 +119,9 @@ func multiplyWithPrefs(op *operationType) lex.Action {
 		if strings.Contains(options, "d") {
 			prefs.DeepMergeArrays = true
 		}
+		if strings.Contains(options, "p") {
+			prefs.MergePatch = true
+		}
 		prefs.TraversePrefs.DontFollowAlias = true
 		op := &Operation{OperationType: op, Value: multiplyOpType.Type, StringValue: options, Preferences: prefs}
 		return &token{TokenType: operationToken, Operation: op}, nil
@@ -252,6 +258,36 @@ func stringValue(wrapped bool) lex.Action {
 	}
 }
 
+func opCallableToken(op *operationType, callOp *operationType) lex.Action {
+	return opCallableTokenWithPrefs(op, callOp, nil)
+}
+
+func opCallableTokenWithPrefs(op *operationType, callOp *operationType, preferences interface{}) lex.Action {
+	return func(s *lex.Scanner, m *machines.Match) (interface{}, error) {
+		log.Debug("opCallableToken %v", string(m.Bytes))
+		value := string(m.Bytes)
+		op := &Operation{OperationType: op, Value: op.Type, StringValue: value, Preferences: preferences}
+		callOperation := &Operation{OperationType: callOp, Value: callOp.Type, StringValue: value}
+		return &token{TokenType: operationToken, Operation: op, CallOperation: callOperation}, nil
+	}
+}
+
+func mapKeyValue() lex.Action {
+	return func(s *lex.Scanner, m *machines.Match) (interface{}, error) {
+		value := strings.TrimSpace(string(m.Bytes))
+		value = strings.TrimSpace(value[:len(value)-1])
+		return &token{TokenType: operationToken, Operation: createValueOperation(value, value), IsMapKey: true}, nil
+	}
+}
+
+func prefixReductionToken(op *operationType) lex.Action {
+	return func(s *lex.Scanner, m *machines.Match) (interface{}, error) {
+		log.Debug("prefixReductionToken %v", string(m.Bytes))
+		op := &Operation{OperationType: op, Value: op.Type, StringValue: string(m.Bytes)}
+		return &token{TokenType: operationToken, Operation: op, IsPrefixReduction: true}, nil
+	}
+}
+
 func getVariableOpToken() lex.Action {
 	return func(s *lex.Scanner, m *machines.Match) (interface{}, error) {
 		value := string(m.Bytes)
@@ -322,7 +358,7 @@ func initLexer() (*lex.Lexer, error) {
 	lexer.Add([]byte(`map_values`), opToken(mapValuesOpType))
 
 	lexer.Add([]byte(`flatten\([0-9]+\)`), flattenWithDepth())
-	lexer.Add([]byte(`flatten`), opTokenWithPrefs(flattenOpType, nil, flattenPreferences{depth: -1}))
+	lexer.Add([]byte(`flatten`), opCallableTokenWithPrefs(flattenOpType, flattenWithDepthOpType, flattenPreferences{depth: -1}))
 
 	lexer.Add([]byte(`toyaml\([0-9]+\)`), encodeWithIndent(YamlOutputFormat))
 	lexer.Add([]byte(`to_yaml\([0-9]+\)`), encodeWithIndent(YamlOutputFormat))
@@ -354,6 +390,8 @@ func initLexer() (*lex.Lexer, error) {
 	lexer.Add([]byte(`to_tsv`), opTokenWithPrefs(encodeOpType, nil, encoderPreferences{format: TSVOutputFormat}))
 	lexer.Add([]byte(`@tsv`), opTokenWithPrefs(encodeOpType, nil, encoderPreferences{format: TSVOutputFormat}))
 
+	lexer.Add([]byte(`@sh`), opToken(encodeShellOpType))
+
 	lexer.Add([]byte(`toxml`), opTokenWithPrefs(encodeOpType, nil, encoderPreferences{format: XMLOutputFormat}))
 	lexer.Add([]byte(`to_xml`), opTokenWithPrefs(encodeOpType, nil, encoderPreferences{format: XMLOutputFormat, indent: 2}))
 	lexer.Add([]byte(`@xml`), opTokenWithPrefs(encodeOpType, nil, encoderPreferences{format: XMLOutputFormat, indent: 0}))
@@ -384,13 +422,39 @@ func initLexer() (*lex.Lexer, error) {
 	lexer.Add([]byte(`unique`), opToken(uniqueOpType))
 	lexer.Add([]byte(`unique_by`), opToken(uniqueByOpType))
 	lexer.Add([]byte(`group_by`), opToken(groupByOpType))
-	lexer.Add([]byte(`explode`), opToken(explodeOpType))
+	lexer.Add([]byte(`inside`), opToken(insideOpType))
+	lexer.Add([]byte(`intersection`), opToken(intersectionOpType))
+	lexer.Add([]byte(`difference`), opToken(differenceOpType))
+	lexer.Add([]byte(`transpose`), opToken(transposeOpType))
+	lexer.Add([]byte(`zip`), opToken(zipOpType))
+	lexer.Add([]byte(`combinations`), opCallableToken(combinationsOpType, combinationsWithOpType))
+	lexer.Add([]byte(`chunk`), opToken(chunkOpType))
+	lexer.Add([]byte(`_nwise`), opToken(nwiseOpType))
+	lexer.Add([]byte(`reverse`), opToken(reverseOpType))
+	lexer.Add([]byte(`explode`), opCallableToken(explodeStringOpType, explodeOpType))
 	lexer.Add([]byte(`or`), opToken(orOpType))
 	lexer.Add([]byte(`and`), opToken(andOpType))
 	lexer.Add([]byte(`not`), opToken(notOpType))
 	lexer.Add([]byte(`ireduce`), opToken(reduceOpType))
+	lexer.Add([]byte(`reduce`), prefixReductionToken(reduceOpType))
+	lexer.Add([]byte(`foreach`), prefixReductionToken(foreachOpType))
+	lexer.Add([]byte(`try`), opToken(tryOpType))
+	lexer.Add([]byte(`catch`), opToken(catchOpType))
+	lexer.Add([]byte(`\?`), opToken(optionalOpType))
+	lexer.Add([]byte(`error`), opToken(errorOpType))
+	lexer.Add([]byte(`limit`), opToken(limitOpType))
+	lexer.Add([]byte(`first`), opCallableToken(firstElementOpType, firstOpType))
+	lexer.Add([]byte(`last`), opCallableToken(lastElementOpType, lastOpType))
+	lexer.Add([]byte(`nth`), opToken(nthOpType))
+	lexer.Add([]byte(`range`), opToken(rangeOpType))
+	lexer.Add([]byte(`until`), opToken(untilOpType))
+	lexer.Add([]byte(`while`), opToken(whileOpType))
+	lexer.Add([]byte(`repeat`), opToken(repeatOpType))
+	lexer.Add([]byte(`walk`), opToken(walkOpType))
+	lexer.Add([]byte(`recurse`), opCallableToken(recurseOpType, recurseWithOpType))
 	lexer.Add([]byte(`;`), opToken(blockOpType))
 	lexer.Add([]byte(`\/\/`), opToken(alternativeOpType))
+	lexer.Add([]byte(`\?\/\/`), opToken(alternativePatternOpType))
 
 	lexer.Add([]byte(`documentIndex`), opToken(getDocumentIndexOpType))
 	lexer.Add([]byte(`document_index`), opToken(getDocumentIndexOpType))
@@ -402,6 +466,7 @@ func initLexer() (*lex.Lexer, error) {
 
 	lexer.Add([]byte(`join`), opToken(joinStringOpType))
 	lexer.Add([]byte(`sub`), opToken(subStringOpType))
+	lexer.Add([]byte(`gsub`), opToken(globalSubStringOpType))
 	lexer.Add([]byte(`match`), opToken(matchOpType))
 	lexer.Add([]byte(`capture`), opToken(captureOpType))
 	lexer.Add([]byte(`test`), opToken(testOpType))
@@ -409,6 +474,23 @@ func initLexer() (*lex.Lexer, error) {
 	lexer.Add([]byte(`sort`), opToken(sortOpType))
 	lexer.Add([]byte(`sort_by`), opToken(sortByOpType))
 
+	lexer.Add([]byte(`min`), opCallableToken(minOpType, minOfOpType))
+	lexer.Add([]byte(`max`), opCallableToken(maxOpType, maxOfOpType))
+	lexer.Add([]byte(`min_by`), opToken(minByOpType))
+	lexer.Add([]byte(`max_by`), opToken(maxByOpType))
+	lexer.Add([]byte(`add`), opToken(addArrayOpType))
+	lexer.Add([]byte(`sum`), opToken(sumOpType))
+	lexer.Add([]byte(`avg`), opToken(avgOpType))
+
+	lexer.Add([]byte(`floor`), opToken(floorOpType))
+	lexer.Add([]byte(`ceil`), opToken(ceilOpType))
+	lexer.Add([]byte(`round`), opToken(roundOpType))
+	lexer.Add([]byte(`abs`), opToken(absOpType))
+	lexer.Add([]byte(`sqrt`), opToken(sqrtOpType))
+	lexer.Add([]byte(`log`), opToken(logOpType))
+	lexer.Add([]byte(`exp`), opToken(expOpType))
+	lexer.Add([]byte(`pow`), opToken(powOpType))
+
 	lexer.Add([]byte(`any`), opToken(anyOpType))
 	lexer.Add([]byte(`any_c`), opToken(anyConditionOpType))
 	lexer.Add([]byte(`all`), opToken(allOpType))
@@ -416,6 +498,34 @@ func initLexer() (*lex.Lexer, error) {
 	lexer.Add([]byte(`contains`), opToken(containsOpType))
 
 	lexer.Add([]byte(`split`), opToken(splitStringOpType))
+	lexer.Add([]byte(`ascii_downcase`), opToken(asciiDowncaseOpType))
+	lexer.Add([]byte(`ascii_upcase`), opToken(asciiUpcaseOpType))
+	lexer.Add([]byte(`ltrimstr`), opToken(ltrimstrOpType))
+	lexer.Add([]byte(`rtrimstr`), opToken(rtrimstrOpType))
+	lexer.Add([]byte(`trim`), opToken(trimOpType))
+	lexer.Add([]byte(`startswith`), opToken(startsWithOpType))
+	lexer.Add([]byte(`endswith`), opToken(endsWithOpType))
+	lexer.Add([]byte(`index`), opToken(indexOpType))
+	lexer.Add([]byte(`rindex`), opToken(rindexOpType))
+	lexer.Add([]byte(`indices`), opToken(indicesOpType))
+	lexer.Add([]byte(`implode`), opToken(implodeOpType))
+	lexer.Add([]byte(`ascii`), opToken(asciiOpType))
+	lexer.Add([]byte(`tostring`), opToken(toStringOpType))
+	lexer.Add([]byte(`to_string`), opToken(toStringOpType))
+	lexer.Add([]byte(`tonumber`), opToken(toNumberOpType))
+	lexer.Add([]byte(`to_number`), opToken(toNumberOpType))
+	lexer.Add([]byte(`to_bool`), opToken(toBoolOpType))
+
+	lexer.Add([]byte(`type`), opToken(typeOpType))
+	lexer.Add([]byte(`kind`), opToken(kindOpType))
+	lexer.Add([]byte(`arrays`), opToken(arraysOpType))
+	lexer.Add([]byte(`objects`), opToken(objectsOpType))
+	lexer.Add([]byte(`iterables`), opToken(iterablesOpType))
+	lexer.Add([]byte(`scalars`), opToken(scalarsOpType))
+	lexer.Add([]byte(`strings`), opToken(stringsOpType))
+	lexer.Add([]byte(`numbers`), opToken(numbersOpType))
+	lexer.Add([]byte(`booleans`), opToken(booleansOpType))
+	lexer.Add([]byte(`nulls`), opToken(nullsOpType))
 
 	lexer.Add([]byte(`parent`), opToken(getParentOpType))
 	lexer.Add([]byte(`key`), opToken(getKeyOpType))
@@ -433,12 +543,40 @@ func initLexer() (*lex.Lexer, error) {
 
 	lexer.Add([]byte(`fi`), opToken(getFileIndexOpType))
 	lexer.Add([]byte(`path`), opToken(getPathOpType))
+	lexer.Add([]byte(`paths`), opCallableToken(pathsOpType, pathsFilterOpType))
+	lexer.Add([]byte(`leaf_paths`), opToken(leafPathsOpType))
+	lexer.Add([]byte(`getpath`), opToken(getValueAtPathOpType))
+	lexer.Add([]byte(`setpath`), opToken(setValueAtPathOpType))
+	lexer.Add([]byte(`delpaths`), opToken(deletePathsOpType))
+	lexer.Add([]byte(`tostream`), opToken(toStreamOpType))
+	lexer.Add([]byte(`fromstream`), opToken(fromStreamOpType))
+	lexer.Add([]byte(`pick`), opToken(pickOpType))
+	lexer.Add([]byte(`merge_patch`), opToken(mergePatchAllOpType))
+	lexer.Add([]byte(`strategic_merge`), opToken(strategicMergeOpType))
+	lexer.Add([]byte(`json_patch`), opToken(jsonPatchOpType))
+	lexer.Add([]byte(`json_diff`), opToken(jsonDiffOpType))
+	lexer.Add([]byte(`to_pointer`), opToken(toPointerOpType))
+	lexer.Add([]byte(`pointer`), opToken(pointerOpType))
+	lexer.Add([]byte(`jsonpath`), opToken(jsonPathOpType))
+	lexer.Add([]byte(`validate`), opToken(validateOpType))
+	lexer.Add([]byte(`infer_schema`), opToken(inferSchemaOpType))
+	lexer.Add([]byte(`line`), opToken(lineOpType))
+	lexer.Add([]byte(`column`), opToken(columnOpType))
+	lexer.Add([]byte(`key_line`), opToken(keyLineOpType))
 	lexer.Add([]byte(`to_entries`), opToken(toEntriesOpType))
 	lexer.Add([]byte(`from_entries`), opToken(fromEntriesOpType))
 	lexer.Add([]byte(`with_entries`), opToken(withEntriesOpType))
 
 	lexer.Add([]byte(`with`), opToken(withOpType))
 
+	lexer.Add([]byte(`now`), opToken(nowOpType))
+	lexer.Add([]byte(`from_unix`), opToken(fromUnixOpType))
+	lexer.Add([]byte(`to_unix`), opToken(toUnixOpType))
+	lexer.Add([]byte(`strftime`), opToken(strftimeOpType))
+	lexer.Add([]byte(`strptime`), opToken(strptimeOpType))
+	lexer.Add([]byte(`format_datetime`), opToken(formatDateTimeOpType))
+	lexer.Add([]byte(`tz`), opToken(tzOpType))
+
 	lexer.Add([]byte(`lineComment`), opTokenWithPrefs(getCommentOpType, assignCommentOpType, commentOpPreferences{LineComment: true}))
 	lexer.Add([]byte(`line_comment`), opTokenWithPrefs(getCommentOpType, assignCommentOpType, commentOpPreferences{LineComment: true}))
 
@@ -455,6 +593,10 @@ func initLexer() (*lex.Lexer, error) {
 
 	lexer.Add([]byte(`\s*==\s*`), opToken(equalsOpType))
 	lexer.Add([]byte(`\s*!=\s*`), opToken(notEqualsOpType))
+	lexer.Add([]byte(`\s*>=\s*`), opTokenWithPrefs(compareOpType, nil, compareTypePref{OrEqual: true, Greater: true}))
+	lexer.Add([]byte(`\s*>\s*`), opTokenWithPrefs(compareOpType, nil, compareTypePref{OrEqual: false, Greater: true}))
+	lexer.Add([]byte(`\s*<=\s*`), opTokenWithPrefs(compareOpType, nil, compareTypePref{OrEqual: true, Greater: false}))
+	lexer.Add([]byte(`\s*<\s*`), opTokenWithPrefs(compareOpType, nil, compareTypePref{OrEqual: false, Greater: false}))
 	lexer.Add([]byte(`\s*=\s*`), assignOpToken(false))
 
 	lexer.Add([]byte(`del`), opToken(deleteChildOpType))
@@ -464,7 +606,7 @@ func initLexer() (*lex.Lexer, error) {
 	lexer.Add([]byte("( |\t|\n|\r)+"), skip)
 
 	lexer.Add([]byte(`\."[^ "]+"\??`), pathToken(true))
-	lexer.Add([]byte(`\.[^ ;\}\{\:\[\],\|\.\[\(\)=\n]+\??`), pathToken(false))
+	lexer.Add([]byte(`\.[^ ;\}\{\:\[\],\|\.\[\(\)=<>\n]+\??`), pathToken(false))
 	lexer.Add([]byte(`\.`), selfToken())
 
 	lexer.Add([]byte(`\|`), opToken(pipeOpType))
@@ -481,6 +623,7 @@ func initLexer() (*lex.Lexer, error) {
 	lexer.Add([]byte(`~`), nullValue())
 
 	lexer.Add([]byte(`"([^"\\]*(\\.[^"\\]*)*)"`), stringValue(true))
+	lexer.Add([]byte(`[a-zA-Z_][a-zA-Z_0-9]*\s*:\s*`), mapKeyValue())
 	lexer.Add([]byte(`strenv\([^\)]+\)`), envOp(true))
 	lexer.Add([]byte(`env\([^\)]+\)`), envOp(false))
 
@@ -490,15 +633,16 @@ func initLexer() (*lex.Lexer, error) {
 	lexer.Add([]byte(`\]\??`), literalToken(closeCollect, true))
 	lexer.Add([]byte(`\{`), literalToken(openCollectObject, false))
 	lexer.Add([]byte(`\}`), literalToken(closeCollectObject, true))
-	lexer.Add([]byte(`\*=[\+|\?dn]*`), multiplyWithPrefs(multiplyAssignOpType))
-	lexer.Add([]byte(`\*[\+|\?dn]*`), multiplyWithPrefs(multiplyOpType))
+	// the p flag needs a space after it, so it isn't read from an operator like `*path`
+	lexer.Add([]byte(`\*=[\+|\?dn]*(p[\+|\?dn]*\s)?`), multiplyWithPrefs(multiplyAssignOpType))
+	lexer.Add([]byte(`\*[\+|\?dn]*(p[\+|\?dn]*\s)?`), multiplyWithPrefs(multiplyOpType))
 
 	lexer.Add([]byte(`\+`), opToken(addOpType))
 	lexer.Add([]byte(`\+=`), opToken(addAssignOpType))
 
 	lexer.Add([]byte(`\-`), opToken(subtractOpType))
 	lexer.Add([]byte(`\-=`), opToken(subtractAssignOpType))
-	lexer.Add([]byte(`\$[a-zA-Z_-0-9]+`), getVariableOpToken())
+	lexer.Add([]byte(`\$[a-zA-Z_\-0-9]+`), getVariableOpToken())
 	lexer.Add([]byte(`as`), opTokenWithPrefs(assignVariableOpType, nil, assignVarPreferences{}))
 	lexer.Add([]byte(`ref`), opTokenWithPrefs(assignVariableOpType, nil, assignVarPreferences{IsReference: true}))
 
@@ -526,6 +670,34 @@ func newExpressionTokeniser() expressionTokeniser {
 }
 
 func (p *expressionTokeniserImpl) Tokenise(expression string) ([]*token, error) {
+	tokens, err := p.lexInterpolated(expression)
+	if err != nil {
+		return nil, err
+	}
+
+	tokens, err = rewritePrefixReductions(tokens)
+	if err != nil {
+		return nil, err
+	}
+
+	tokens = rewriteSlices(tokens)
+
+	var postProcessedTokens = make([]*token, 0)
+
+	skipNextToken := false
+
+	for index := range tokens {
+		if skipNextToken {
+			skipNextToken = false
+		} else {
+			postProcessedTokens, skipNextToken = p.handleToken(tokens, index, postProcessedTokens)
+		}
+	}
+
+	return postProcessedTokens, nil
+}
+
+func (p *expressionTokeniserImpl) lex(expression string) ([]*token, error) {
 	scanner, err := p.lexer.Scanner([]byte(expression))
 
 	if err != nil {
@@ -543,19 +715,279 @@ func (p *expressionTokeniserImpl) Tokenise(expression string) ([]*token, error)
 			return nil, fmt.Errorf("parsing expression: %w", err)
 		}
 	}
-	var postProcessedTokens = make([]*token, 0)
+	return tokens, nil
+}
 
-	skipNextToken := false
+// lexInterpolated lexes the expression, expanding any string literals with jq style
+// interpolation (e.g. "hello \(.name)") into the equivalent string concatenation. The lexer
+// itself can't match these as the interpolated expressions may contain strings of their own.
+func (p *expressionTokeniserImpl) lexInterpolated(expression string) ([]*token, error) {
+	var tokens []*token
+	segmentStart := 0
 
-	for index := range tokens {
-		if skipNextToken {
-			skipNextToken = false
-		} else {
-			postProcessedTokens, skipNextToken = p.handleToken(tokens, index, postProcessedTokens)
+	for index := 0; index < len(expression); index++ {
+		if expression[index] != '"' {
+			continue
+		}
+		end, literals, expressions, err := scanStringLiteral(expression, index)
+		if err != nil {
+			return nil, err
+		}
+		if len(expressions) > 0 {
+			segmentTokens, err := p.lex(expression[segmentStart:index])
+			if err != nil {
+				return nil, err
+			}
+			tokens, err = p.interpolate(append(tokens, segmentTokens...), literals, expressions)
+			if err != nil {
+				return nil, err
+			}
+			segmentStart = end
 		}
+		index = end - 1
 	}
 
-	return postProcessedTokens, nil
+	remainingTokens, err := p.lex(expression[segmentStart:])
+	if err != nil {
+		return nil, err
+	}
+	return append(tokens, remainingTokens...), nil
+}
+
+// interpolate appends the tokens for ("literal" + (exp | tostring) + ...). If the string was
+// prefixed with a format (e.g. @sh "echo \(.a)"), the format is applied to each interpolated
+// value instead.
+func (p *expressionTokeniserImpl) interpolate(tokens []*token, literals []string, expressions []string) ([]*token, error) {
+	formatOperation := &Operation{OperationType: toStringOpType, Value: toStringOpType.Type, StringValue: "tostring"}
+	if len(tokens) > 0 {
+		previous := tokens[len(tokens)-1]
+		if previous.TokenType == operationToken && strings.HasPrefix(previous.Operation.StringValue, "@") {
+			formatOperation = previous.Operation
+			tokens = tokens[:len(tokens)-1]
+		}
+	}
+
+	addToken := &token{TokenType: operationToken, Operation: &Operation{OperationType: addOpType, Value: addOpType.Type, StringValue: "+"}}
+	pipeToken := &token{TokenType: operationToken, Operation: &Operation{OperationType: pipeOpType, Value: pipeOpType.Type, StringValue: "|"}}
+
+	tokens = append(tokens, &token{TokenType: openBracket})
+	for index, literal := range literals {
+		if literal != "" {
+			if tokens[len(tokens)-1].TokenType != openBracket {
+				tokens = append(tokens, addToken)
+			}
+			literal = strings.ReplaceAll(literal, "\\\"", "\"")
+			tokens = append(tokens, &token{TokenType: operationToken, Operation: createValueOperation(literal, literal)})
+		}
+		if index < len(expressions) {
+			if tokens[len(tokens)-1].TokenType != openBracket {
+				tokens = append(tokens, addToken)
+			}
+			expressionTokens, err := p.lexInterpolated(expressions[index])
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, &token{TokenType: openBracket}, &token{TokenType: openBracket})
+			tokens = append(tokens, expressionTokens...)
+			tokens = append(tokens, &token{TokenType: closeBracket}, pipeToken,
+				&token{TokenType: operationToken, Operation: formatOperation}, &token{TokenType: closeBracket})
+		}
+	}
+	return append(tokens, &token{TokenType: closeBracket}), nil
+}
+
+// scanStringLiteral scans the string literal starting at the quote at 'start', returning the index
+// after its closing quote along with its literal parts and the interpolated expressions between them.
+func scanStringLiteral(expression string, start int) (int, []string, []string, error) {
+	var literals []string
+	var expressions []string
+	literalStart := start + 1
+
+	index := start + 1
+	for index < len(expression) {
+		switch {
+		case expression[index] == '"':
+			return index + 1, append(literals, expression[literalStart:index]), expressions, nil
+		case expression[index] == '\\' && index+1 < len(expression) && expression[index+1] == '(':
+			literals = append(literals, expression[literalStart:index])
+			end, err := scanInterpolatedExpression(expression, index+2)
+			if err != nil {
+				return 0, nil, nil, err
+			}
+			expressions = append(expressions, expression[index+2:end])
+			index = end + 1
+			literalStart = index
+		case expression[index] == '\\':
+			index = index + 2
+		default:
+			index++
+		}
+	}
+	if len(expressions) > 0 {
+		return 0, nil, nil, fmt.Errorf("unterminated string: %v", expression[start:])
+	}
+	// not our problem - let the lexer report it.
+	return len(expression), nil, nil, nil
+}
+
+// scanInterpolatedExpression returns the index of the bracket that closes the interpolation starting at 'start'
+func scanInterpolatedExpression(expression string, start int) (int, error) {
+	depth := 0
+	for index := start; index < len(expression); index++ {
+		switch expression[index] {
+		case '"':
+			end, _, _, err := scanStringLiteral(expression, index)
+			if err != nil {
+				return 0, err
+			}
+			index = end - 1
+		case '(':
+			depth++
+		case ')':
+			if depth == 0 {
+				return index, nil
+			}
+			depth--
+		}
+	}
+	return 0, fmt.Errorf("unterminated string interpolation: %v", expression[start:])
+}
+
+type prefixReduction struct {
+	operation    *token
+	depth        int
+	foundAs      bool
+	blockStarted bool
+}
+
+// reduce and foreach use jq's prefix syntax e.g. `reduce .[] as $x (0; . + $x)`,
+// that gets rewritten to the infix form `(.[] as $x ireduce (0; . + $x))`
+func rewritePrefixReductions(tokens []*token) ([]*token, error) {
+	var rewritten = make([]*token, 0, len(tokens))
+	var pending = make([]*prefixReduction, 0)
+	depth := 0
+
+	for _, currentToken := range tokens {
+		if currentToken.IsPrefixReduction {
+			rewritten = append(rewritten, &token{TokenType: openBracket})
+			depth++
+			pending = append(pending, &prefixReduction{operation: currentToken, depth: depth})
+			continue
+		}
+		var current *prefixReduction
+		if len(pending) > 0 {
+			current = pending[len(pending)-1]
+		}
+
+		switch currentToken.TokenType {
+		case openBracket:
+			if current != nil && current.foundAs && !current.blockStarted && depth == current.depth {
+				log.Debug("  adding %v before its block", current.operation.Operation.OperationType.Type)
+				rewritten = append(rewritten, current.operation)
+				current.blockStarted = true
+			}
+			depth++
+		case openCollect, openCollectObject, traverseArrayCollect:
+			depth++
+		case closeBracket, closeCollect, closeCollectObject:
+			depth--
+		case operationToken:
+			if current != nil && !current.foundAs && depth == current.depth &&
+				currentToken.Operation.OperationType == assignVariableOpType {
+				current.foundAs = true
+			}
+		}
+		rewritten = append(rewritten, currentToken)
+
+		if current != nil && current.blockStarted && depth == current.depth {
+			rewritten = append(rewritten, &token{TokenType: closeBracket, CheckForPostTraverse: true})
+			depth--
+			pending = pending[:len(pending)-1]
+		}
+	}
+	if len(pending) > 0 {
+		operation := pending[len(pending)-1].operation.Operation
+		return nil, fmt.Errorf("%v expects the form '%v <exp> as $<name> (<init>; <update>)'", operation.StringValue, operation.StringValue)
+	}
+	return rewritten, nil
+}
+
+func isOpeningToken(currentToken *token) bool {
+	return currentToken.TokenType == openBracket || currentToken.TokenType == openCollect ||
+		currentToken.TokenType == openCollectObject || currentToken.TokenType == traverseArrayCollect
+}
+
+func isClosingToken(currentToken *token) bool {
+	return currentToken.TokenType == closeBracket || currentToken.TokenType == closeCollect ||
+		currentToken.TokenType == closeCollectObject
+}
+
+// isSliceAt checks if the traversal opened at the given index has a ':' directly within it e.g. `.[1:3]`
+func isSliceAt(tokens []*token, index int) bool {
+	depth := 0
+	for _, currentToken := range tokens[index+1:] {
+		if isOpeningToken(currentToken) {
+			depth++
+		} else if isClosingToken(currentToken) {
+			if depth == 0 {
+				return false
+			}
+			depth--
+		} else if depth == 0 && currentToken.TokenType == operationToken &&
+			currentToken.Operation.OperationType == createMapOpType {
+			return true
+		}
+	}
+	return false
+}
+
+// slices like `.[1:3]` and `.a[:2]` get rewritten to `. SLICE (1; 3)` and `.a SLICE (null; 2)`
+func rewriteSlices(tokens []*token) []*token {
+	var rewritten = make([]*token, 0, len(tokens))
+	var isSlice = make([]bool, 0)
+	nullToken := func() *token {
+		return &token{TokenType: operationToken, Operation: createValueOperation(nil, "null")}
+	}
+
+	for index, currentToken := range tokens {
+		inSlice := len(isSlice) > 0 && isSlice[len(isSlice)-1]
+
+		switch {
+		case currentToken.TokenType == traverseArrayCollect && isSliceAt(tokens, index):
+			log.Debug("  rewriting .[ as a slice")
+			rewritten = append(rewritten, &token{TokenType: operationToken, Operation: &Operation{OperationType: selfReferenceOpType}})
+			rewritten = append(rewritten, &token{TokenType: operationToken, Operation: &Operation{OperationType: sliceOpType, StringValue: "SLICE"}})
+			rewritten = append(rewritten, &token{TokenType: openBracket})
+			isSlice = append(isSlice, true)
+		case currentToken.TokenType == openCollect && index > 0 && tokens[index-1].CheckForPostTraverse && isSliceAt(tokens, index):
+			log.Debug("  rewriting [ as a slice")
+			rewritten = append(rewritten, &token{TokenType: operationToken, Operation: &Operation{OperationType: sliceOpType, StringValue: "SLICE"}})
+			rewritten = append(rewritten, &token{TokenType: openBracket})
+			isSlice = append(isSlice, true)
+		case isOpeningToken(currentToken):
+			rewritten = append(rewritten, currentToken)
+			isSlice = append(isSlice, false)
+		case isClosingToken(currentToken) && inSlice:
+			rewritten = append(rewritten, &token{TokenType: closeBracket, CheckForPostTraverse: true})
+			isSlice = isSlice[:len(isSlice)-1]
+		case isClosingToken(currentToken):
+			rewritten = append(rewritten, currentToken)
+			if len(isSlice) > 0 {
+				isSlice = isSlice[:len(isSlice)-1]
+			}
+		case inSlice && currentToken.TokenType == operationToken && currentToken.Operation.OperationType == createMapOpType:
+			if rewritten[len(rewritten)-1].TokenType == openBracket {
+				rewritten = append(rewritten, nullToken())
+			}
+			rewritten = append(rewritten, &token{TokenType: operationToken, Operation: &Operation{OperationType: blockOpType, StringValue: ";"}})
+			if index < len(tokens)-1 && isClosingToken(tokens[index+1]) {
+				rewritten = append(rewritten, nullToken())
+			}
+		default:
+			rewritten = append(rewritten, currentToken)
+		}
+	}
+	return rewritten
 }
 
 func (p *expressionTokeniserImpl) handleToken(tokens []*token, index int, postProcessedTokens []*token) (tokensAccum []*token, skipNextToken bool) {
@@ -590,9 +1022,21 @@ func (p *expressionTokeniserImpl) handleToken(tokens []*token, index int, postPr
 		skipNextToken = true
 	}
 
+	if index != len(tokens)-1 && currentToken.CallOperation != nil &&
+		tokens[index+1].TokenType == openBracket {
+		log.Debug("  its a function call")
+		currentToken.Operation = currentToken.CallOperation
+	}
+
 	log.Debug("  adding token to the fixed list")
 	postProcessedTokens = append(postProcessedTokens, currentToken)
 
+	if currentToken.IsMapKey {
+		log.Debug("  adding create map after bare key")
+		op := &Operation{OperationType: createMapOpType, Value: createMapOpType.Type, StringValue: ":"}
+		postProcessedTokens = append(postProcessedTokens, &token{TokenType: operationToken, Operation: op})
+	}
+
 	if index != len(tokens)-1 &&
 		((currentToken.TokenType == openCollect && tokens[index+1].TokenType == closeCollect) ||
 			(currentToken.TokenType == openCollectObject && tokens[index+1].TokenType == closeCollectObject)) {
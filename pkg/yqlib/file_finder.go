@@ -0,0 +1,220 @@
+package yqlib
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// matchesFilePattern checks the glob pattern against the file's name, or if the pattern has a '/',
+// against the end of its path e.g. "templates/*.yaml" matches "chart/templates/service.yaml".
+func matchesFilePattern(pattern string, filename string) bool {
+	filename = filepath.ToSlash(filepath.Clean(filename))
+	if !strings.Contains(pattern, "/") {
+		matches, _ := path.Match(pattern, path.Base(filename))
+		return matches
+	}
+	parts := strings.Split(filename, "/")
+	for index := range parts {
+		if matches, _ := path.Match(pattern, strings.Join(parts[index:], "/")); matches {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesAnyFilePattern(patterns []string, filename string) bool {
+	for _, pattern := range patterns {
+		if matchesFilePattern(pattern, filename) {
+			return true
+		}
+	}
+	return false
+}
+
+func validateFilePatterns(patterns []string) error {
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("bad file pattern '%v': %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+func hasGlobMeta(pattern string) bool {
+	return strings.ContainsAny(pattern, "*?[")
+}
+
+func joinGlobPath(dir string, name string) string {
+	if dir == "" {
+		return name
+	}
+	return path.Join(dir, name)
+}
+
+// globSegments adds the paths under dir that match the remaining segments of the pattern.
+// A "**" segment matches any number of directories, and the files in them when it is last. Like a shell, hidden files are only matched
+// when the segment starts with a '.'.
+func globSegments(dir string, segments []string, matches map[string]bool, results *[]string) error {
+	if len(segments) == 0 {
+		if !matches[dir] {
+			matches[dir] = true
+			*results = append(*results, dir)
+		}
+		return nil
+	}
+	segment := segments[0]
+	if !hasGlobMeta(segment) {
+		next := joinGlobPath(dir, segment)
+		if _, err := os.Lstat(filepath.FromSlash(next)); err != nil {
+			return nil
+		}
+		return globSegments(next, segments[1:], matches, results)
+	}
+
+	readDir := dir
+	if readDir == "" {
+		readDir = "."
+	}
+	entries, err := os.ReadDir(filepath.FromSlash(readDir))
+	if err != nil {
+		// like a shell, things that aren't directories just don't match
+		return nil
+	}
+	if segment == "**" {
+		if err := globSegments(dir, segments[1:], matches, results); err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			if strings.HasPrefix(entry.Name(), ".") {
+				continue
+			}
+			if entry.IsDir() {
+				if err := globSegments(joinGlobPath(dir, entry.Name()), segments, matches, results); err != nil {
+					return err
+				}
+			} else if len(segments) == 1 {
+				// a trailing "**" matches all the files too
+				if err := globSegments(joinGlobPath(dir, entry.Name()), nil, matches, results); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), ".") && !strings.HasPrefix(segment, ".") {
+			continue
+		}
+		if matched, _ := path.Match(segment, entry.Name()); matched {
+			if err := globSegments(joinGlobPath(dir, entry.Name()), segments[1:], matches, results); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ExpandGlob returns the paths matching the pattern in lexical order, where "**" matches any number of
+// directories, e.g. "charts/**/*.yaml".
+func ExpandGlob(pattern string) ([]string, error) {
+	if err := validateFilePatterns([]string{pattern}); err != nil {
+		return nil, err
+	}
+	pattern = filepath.ToSlash(pattern)
+	dir := ""
+	if strings.HasPrefix(pattern, "/") {
+		dir = "/"
+		pattern = strings.TrimLeft(pattern, "/")
+	}
+	results := []string{}
+	if err := globSegments(dir, strings.Split(pattern, "/"), map[string]bool{}, &results); err != nil {
+		return nil, err
+	}
+	for index, result := range results {
+		results[index] = filepath.FromSlash(result)
+	}
+	return results, nil
+}
+
+func walkDirectory(root string, includes []string, excludes []string, filenames []string) ([]string, error) {
+	err := filepath.WalkDir(root, func(filename string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if filename != root && (strings.HasPrefix(entry.Name(), ".") || matchesAnyFilePattern(excludes, filename)) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if matchesAnyFilePattern(excludes, filename) {
+			return nil
+		}
+		_, knownFormat := InputFormatFromFilename(filename)
+		if (len(includes) == 0 && knownFormat) || matchesAnyFilePattern(includes, filename) {
+			filenames = append(filenames, filename)
+		}
+		return nil
+	})
+	return filenames, err
+}
+
+// FindFiles returns the files given, the files matching the globs given and, when recursive, the files
+// found by walking the directories given. Files found in directories are only returned if their format
+// is known from their extension, or if they match one of the includes. Files matching globs are
+// returned if they match one of the includes, or if there are none. Anything matching one of the
+// excludes is skipped, as are hidden directories like .git. "-" is kept as is, for STDIN.
+func FindFiles(paths []string, recursive bool, includes []string, excludes []string) ([]string, error) {
+	if err := validateFilePatterns(includes); err != nil {
+		return nil, err
+	}
+	if err := validateFilePatterns(excludes); err != nil {
+		return nil, err
+	}
+	filenames := []string{}
+	for _, root := range paths {
+		if root == "-" {
+			filenames = append(filenames, root)
+			continue
+		}
+		info, err := os.Stat(root)
+		if err != nil && hasGlobMeta(root) {
+			matches, err := ExpandGlob(root)
+			if err != nil {
+				return nil, err
+			}
+			if len(matches) == 0 {
+				return nil, fmt.Errorf("no files match '%v'", root)
+			}
+			for _, match := range matches {
+				if matchInfo, err := os.Stat(match); err == nil && matchInfo.IsDir() {
+					if recursive {
+						if filenames, err = walkDirectory(match, includes, excludes, filenames); err != nil {
+							return nil, err
+						}
+					}
+				} else if !matchesAnyFilePattern(excludes, match) && (len(includes) == 0 || matchesAnyFilePattern(includes, match)) {
+					filenames = append(filenames, match)
+				}
+			}
+			continue
+		} else if err != nil || !info.IsDir() {
+			// files that can't be read are kept, for the reader to report the error
+			if !matchesAnyFilePattern(excludes, root) {
+				filenames = append(filenames, root)
+			}
+			continue
+		}
+		if !recursive {
+			return nil, fmt.Errorf("'%v' is a directory, use the recursive flag to read the files in it", root)
+		}
+		if filenames, err = walkDirectory(root, includes, excludes, filenames); err != nil {
+			return nil, err
+		}
+	}
+	return filenames, nil
+}
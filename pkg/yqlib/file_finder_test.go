@@ -0,0 +1,173 @@
+package yqlib
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mikefarah/yq/v4/test"
+)
+
+func createTestDirectory(filenames ...string) string {
+	dir, err := os.MkdirTemp("", "yqfind")
+	if err != nil {
+		panic(err)
+	}
+	for _, filename := range filenames {
+		fullPath := filepath.Join(dir, filepath.FromSlash(filename))
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0700); err != nil {
+			panic(err)
+		}
+		if err := os.WriteFile(fullPath, []byte("a: cat\n"), 0600); err != nil {
+			panic(err)
+		}
+	}
+	return dir
+}
+
+func relativeFilenames(dir string, filenames []string) []string {
+	relative := []string{}
+	for _, filename := range filenames {
+		name, err := filepath.Rel(dir, filename)
+		if err != nil {
+			panic(err)
+		}
+		relative = append(relative, filepath.ToSlash(name))
+	}
+	return relative
+}
+
+var findFilesScenarios = []struct {
+	description string
+	includes    []string
+	excludes    []string
+	expected    []string
+}{
+	{
+		description: "known formats",
+		expected:    []string{"a.yaml", "b.json", "charts/c.yml", "charts/templates/d.yaml", "e.properties"},
+	},
+	{
+		description: "include",
+		includes:    []string{"*.yml", "*.txt"},
+		expected:    []string{"charts/c.yml", "notes.txt"},
+	},
+	{
+		description: "include with a directory",
+		includes:    []string{"templates/*"},
+		expected:    []string{"charts/templates/d.yaml", "charts/templates/e.tpl"},
+	},
+	{
+		description: "exclude files and directories",
+		excludes:    []string{"*.json", "templates"},
+		expected:    []string{"a.yaml", "charts/c.yml", "e.properties"},
+	},
+}
+
+func TestFindFiles(t *testing.T) {
+	dir := createTestDirectory("a.yaml", "b.json", "charts/c.yml", "charts/templates/d.yaml", "charts/templates/e.tpl",
+		"e.properties", "notes.txt", ".git/f.yaml")
+	defer os.RemoveAll(dir)
+
+	for _, s := range findFilesScenarios {
+		filenames, err := FindFiles([]string{dir}, true, s.includes, s.excludes)
+		if err != nil {
+			t.Error(s.description, err)
+			continue
+		}
+		test.AssertResultComplexWithContext(t, s.expected, relativeFilenames(dir, filenames), s.description)
+	}
+}
+
+func TestFindFilesKeepsFilesGiven(t *testing.T) {
+	dir := createTestDirectory("notes.txt", "b.json")
+	defer os.RemoveAll(dir)
+
+	filenames, err := FindFiles([]string{filepath.Join(dir, "notes.txt"), filepath.Join(dir, "b.json")}, false, []string{}, []string{"*.json"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	test.AssertResultComplex(t, []string{"notes.txt"}, relativeFilenames(dir, filenames))
+}
+
+func TestFindFilesBadPattern(t *testing.T) {
+	_, err := FindFiles([]string{"."}, true, []string{"[a"}, []string{})
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	test.AssertResult(t, "bad file pattern '[a': syntax error in pattern", err.Error())
+}
+
+var expandGlobScenarios = []struct {
+	pattern  string
+	expected []string
+}{
+	{
+		pattern:  "*.yaml",
+		expected: []string{"a.yaml"},
+	},
+	{
+		pattern:  "**/*.yaml",
+		expected: []string{"a.yaml", "charts/templates/d.yaml"},
+	},
+	{
+		pattern:  "charts/**",
+		expected: []string{"charts", "charts/c.yml", "charts/templates", "charts/templates/d.yaml", "charts/templates/e.tpl"},
+	},
+	{
+		pattern:  "charts/*/*.tpl",
+		expected: []string{"charts/templates/e.tpl"},
+	},
+	{
+		pattern:  ".git/*",
+		expected: []string{".git/f.yaml"},
+	},
+	{
+		pattern:  "nothing/**/*.yaml",
+		expected: []string{},
+	},
+}
+
+func TestExpandGlob(t *testing.T) {
+	dir := createTestDirectory("a.yaml", "b.json", "charts/c.yml", "charts/templates/d.yaml", "charts/templates/e.tpl", ".git/f.yaml")
+	defer os.RemoveAll(dir)
+
+	for _, s := range expandGlobScenarios {
+		filenames, err := ExpandGlob(filepath.Join(dir, s.pattern))
+		if err != nil {
+			t.Error(s.pattern, err)
+			continue
+		}
+		test.AssertResultComplexWithContext(t, s.expected, relativeFilenames(dir, filenames), s.pattern)
+	}
+}
+
+func TestFindFilesWithGlob(t *testing.T) {
+	dir := createTestDirectory("a.yaml", "charts/c.yml", "charts/templates/d.yaml", "charts/templates/e.tpl")
+	defer os.RemoveAll(dir)
+
+	// directories matching the glob are only read when recursive
+	filenames, err := FindFiles([]string{filepath.Join(dir, "*")}, false, []string{}, []string{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	test.AssertResultComplex(t, []string{"a.yaml"}, relativeFilenames(dir, filenames))
+
+	filenames, err = FindFiles([]string{filepath.Join(dir, "*")}, true, []string{}, []string{"templates"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	test.AssertResultComplex(t, []string{"a.yaml", "charts/c.yml"}, relativeFilenames(dir, filenames))
+
+	_, err = FindFiles([]string{filepath.Join(dir, "*.json")}, false, []string{}, []string{})
+	test.AssertResult(t, fmt.Sprintf("no files match '%v'", filepath.Join(dir, "*.json")), err.Error())
+}
+
+func TestFindFilesDirectoryNeedsRecursive(t *testing.T) {
+	dir := createTestDirectory("a.yaml")
+	defer os.RemoveAll(dir)
+
+	_, err := FindFiles([]string{dir}, false, []string{}, []string{})
+	test.AssertResult(t, fmt.Sprintf("'%v' is a directory, use the recursive flag to read the files in it", dir), err.Error())
+}
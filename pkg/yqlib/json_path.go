@@ -0,0 +1,349 @@
+package yqlib
+
+import (
+	"container/list"
+	"fmt"
+	"strconv"
+	"strings"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+type jsonPathSelectorType int
+
+const (
+	jsonPathNames jsonPathSelectorType = iota
+	jsonPathIndices
+	jsonPathWildcard
+	jsonPathSlice
+	jsonPathFilter
+)
+
+// jsonPathSegment is one step of a JSONPath, e.g. `.name`, `..name`, `[0,1]`, `[*]`, `[1:3]` or `[?(@.a == 1)]`.
+type jsonPathSegment struct {
+	recursive    bool
+	selectorType jsonPathSelectorType
+	names        []string
+	indices      []int64
+	slice        [3]*int64 // start, end and step
+	filter       *ExpressionNode
+}
+
+// parseJSONPath parses the common subset of JSONPath used by tools like kubectl,
+// e.g. `$.spec.containers[*].image` or `$..containers[?(@.name == 'app')]`.
+func parseJSONPath(path string) ([]*jsonPathSegment, error) {
+	path = strings.TrimSpace(path)
+	if !strings.HasPrefix(path, "$") {
+		return nil, fmt.Errorf("'%v' is not a valid json path, it must start with '$'", path)
+	}
+	segments := []*jsonPathSegment{}
+	pos := 1
+	for pos < len(path) {
+		recursive := false
+		if strings.HasPrefix(path[pos:], "..") {
+			recursive = true
+			pos = pos + 2
+		} else if path[pos] == '.' {
+			pos = pos + 1
+		} else if path[pos] != '[' {
+			return nil, fmt.Errorf("'%v' is not a valid json path, unexpected '%c' at position %v", path, path[pos], pos)
+		}
+
+		var segment *jsonPathSegment
+		var err error
+		if pos < len(path) && path[pos] == '[' {
+			end := findJSONPathBracketEnd(path, pos)
+			if end == -1 {
+				return nil, fmt.Errorf("'%v' is not a valid json path, '[' at position %v is not closed", path, pos)
+			}
+			segment, err = parseJSONPathBracket(strings.TrimSpace(path[pos+1 : end]))
+			if err != nil {
+				return nil, fmt.Errorf("'%v' is not a valid json path, %w", path, err)
+			}
+			pos = end + 1
+		} else {
+			end := pos
+			for end < len(path) && path[end] != '.' && path[end] != '[' {
+				end = end + 1
+			}
+			name := path[pos:end]
+			if name == "" {
+				return nil, fmt.Errorf("'%v' is not a valid json path, expected a name at position %v", path, pos)
+			}
+			segment = &jsonPathSegment{selectorType: jsonPathNames, names: []string{name}}
+			if name == "*" {
+				segment = &jsonPathSegment{selectorType: jsonPathWildcard}
+			}
+			pos = end
+		}
+		segment.recursive = recursive
+		segments = append(segments, segment)
+	}
+	return segments, nil
+}
+
+// findJSONPathBracketEnd finds the ']' matching the '[' at start, skipping quoted strings and filter parentheses.
+func findJSONPathBracketEnd(path string, start int) int {
+	depth := 0
+	var quote byte
+	for pos := start + 1; pos < len(path); pos++ {
+		char := path[pos]
+		switch {
+		case quote != 0:
+			if char == '\\' {
+				pos++
+			} else if char == quote {
+				quote = 0
+			}
+		case char == '\'' || char == '"':
+			quote = char
+		case char == '(' || char == '[':
+			depth++
+		case char == ')' || (char == ']' && depth > 0):
+			depth--
+		case char == ']':
+			return pos
+		}
+	}
+	return -1
+}
+
+// splitJSONPathList splits the bracket content by sep, ignoring separators in quoted strings.
+func splitJSONPathList(content string, sep byte) []string {
+	parts := []string{}
+	var quote byte
+	start := 0
+	for pos := 0; pos < len(content); pos++ {
+		char := content[pos]
+		if quote != 0 {
+			if char == '\\' {
+				pos++
+			} else if char == quote {
+				quote = 0
+			}
+		} else if char == '\'' || char == '"' {
+			quote = char
+		} else if char == sep {
+			parts = append(parts, strings.TrimSpace(content[start:pos]))
+			start = pos + 1
+		}
+	}
+	return append(parts, strings.TrimSpace(content[start:]))
+}
+
+func unquoteJSONPathString(value string) (string, bool) {
+	if len(value) < 2 || (value[0] != '\'' && value[0] != '"') || value[len(value)-1] != value[0] {
+		return "", false
+	}
+	quote := string(value[0])
+	return strings.NewReplacer("\\\\", "\\", "\\"+quote, quote).Replace(value[1 : len(value)-1]), true
+}
+
+func parseJSONPathBracket(content string) (*jsonPathSegment, error) {
+	if content == "*" {
+		return &jsonPathSegment{selectorType: jsonPathWildcard}, nil
+	}
+	if strings.HasPrefix(content, "?") {
+		return parseJSONPathFilter(strings.TrimSpace(content[1:]))
+	}
+
+	if parts := splitJSONPathList(content, ':'); len(parts) > 1 {
+		if len(parts) > 3 {
+			return nil, fmt.Errorf("'[%v]' is not a valid slice", content)
+		}
+		segment := &jsonPathSegment{selectorType: jsonPathSlice}
+		for index, part := range parts {
+			if part == "" {
+				continue
+			}
+			number, err := strconv.ParseInt(part, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("'[%v]' is not a valid slice", content)
+			}
+			segment.slice[index] = &number
+		}
+		if segment.slice[2] != nil && *segment.slice[2] <= 0 {
+			return nil, fmt.Errorf("'[%v]' is not a valid slice, the step must be positive", content)
+		}
+		return segment, nil
+	}
+
+	segment := &jsonPathSegment{}
+	for _, part := range splitJSONPathList(content, ',') {
+		if name, isString := unquoteJSONPathString(part); isString {
+			segment.selectorType = jsonPathNames
+			segment.names = append(segment.names, name)
+		} else if number, err := strconv.ParseInt(part, 10, 64); err == nil {
+			segment.selectorType = jsonPathIndices
+			segment.indices = append(segment.indices, number)
+		} else {
+			return nil, fmt.Errorf("'[%v]' is not a valid selector, expected quoted names or indices", content)
+		}
+	}
+	if len(segment.names) > 0 && len(segment.indices) > 0 {
+		return nil, fmt.Errorf("'[%v]' cannot mix names and indices", content)
+	}
+	return segment, nil
+}
+
+// parseJSONPathFilter converts a filter like `(@.price < 10 && @.name != 'x')` to a yq expression.
+func parseJSONPathFilter(filter string) (*jsonPathSegment, error) {
+	if strings.HasPrefix(filter, "(") && strings.HasSuffix(filter, ")") {
+		filter = filter[1 : len(filter)-1]
+	}
+	var expression strings.Builder
+	for pos := 0; pos < len(filter); pos++ {
+		char := filter[pos]
+		switch {
+		case char == '\'':
+			end := pos + 1
+			for end < len(filter) && filter[end] != '\'' {
+				if filter[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(filter) {
+				return nil, fmt.Errorf("'%v' has an unterminated string", filter)
+			}
+			value, _ := unquoteJSONPathString(filter[pos : end+1])
+			expression.WriteString(strconv.Quote(value))
+			pos = end
+		case char == '"':
+			end := pos + 1
+			for end < len(filter) && filter[end] != '"' {
+				if filter[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(filter) {
+				return nil, fmt.Errorf("'%v' has an unterminated string", filter)
+			}
+			expression.WriteString(filter[pos : end+1])
+			pos = end
+		case char == '@':
+			// @.a is the same as .a in yq, a lone @ is the current node
+			if pos+1 >= len(filter) || filter[pos+1] != '.' {
+				expression.WriteString(".")
+			}
+		case strings.HasPrefix(filter[pos:], "&&"):
+			expression.WriteString(" and ")
+			pos++
+		case strings.HasPrefix(filter[pos:], "||"):
+			expression.WriteString(" or ")
+			pos++
+		default:
+			expression.WriteByte(char)
+		}
+	}
+	node, err := ExpressionParser.ParseExpression(expression.String())
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse filter '%v': %w", filter, err)
+	}
+	return &jsonPathSegment{selectorType: jsonPathFilter, filter: node}, nil
+}
+
+func getDescendants(candidate *CandidateNode, results []*CandidateNode) []*CandidateNode {
+	results = append(results, candidate)
+	for _, child := range getChildCandidates(candidate) {
+		results = getDescendants(child, results)
+	}
+	return results
+}
+
+func (s *jsonPathSegment) apply(d *dataTreeNavigator, context Context, candidate *CandidateNode, autoCreate bool, results *list.List) error {
+	node := followAlias(unwrapDoc(candidate.Node))
+	switch s.selectorType {
+	case jsonPathNames:
+		if node.Kind != yaml.MappingNode && !(node.Tag == "!!null" && autoCreate) {
+			return nil
+		}
+		for _, name := range s.names {
+			if child := getChildForAssignment(candidate, name, 0, false, autoCreate); child != nil {
+				results.PushBack(child)
+			}
+		}
+	case jsonPathIndices:
+		if node.Kind != yaml.SequenceNode && !(node.Tag == "!!null" && autoCreate) {
+			return nil
+		}
+		for _, index := range s.indices {
+			if child := getChildForAssignment(candidate, "", index, true, autoCreate); child != nil {
+				results.PushBack(child)
+			}
+		}
+	case jsonPathWildcard:
+		for _, child := range getChildCandidates(candidate) {
+			results.PushBack(child)
+		}
+	case jsonPathSlice:
+		if node.Kind != yaml.SequenceNode {
+			return nil
+		}
+		length := int64(len(node.Content))
+		start, end, step := int64(0), length, int64(1)
+		if s.slice[0] != nil {
+			start = relativeSliceIndex(*s.slice[0], length)
+		}
+		if s.slice[1] != nil {
+			end = relativeSliceIndex(*s.slice[1], length)
+		}
+		if s.slice[2] != nil {
+			step = *s.slice[2]
+		}
+		children := getChildCandidates(candidate)
+		for index := start; index < end; index = index + step {
+			results.PushBack(children[index])
+		}
+	case jsonPathFilter:
+		for _, child := range getChildCandidates(candidate) {
+			matches, err := conditionIsTrue(d, context, child, s.filter)
+			if err != nil {
+				return err
+			}
+			if matches {
+				results.PushBack(child)
+			}
+		}
+	}
+	return nil
+}
+
+// relativeSliceIndex turns a negative slice index into one from the start, and clamps it to the array.
+func relativeSliceIndex(index int64, length int64) int64 {
+	if index < 0 {
+		index = length + index
+	}
+	if index < 0 {
+		return 0
+	}
+	if index > length {
+		return length
+	}
+	return index
+}
+
+// evaluateJSONPath finds the nodes matching the path. Like traversing, missing names and indices
+// are created unless autoCreate is false, so that the path can be assigned to.
+func evaluateJSONPath(d *dataTreeNavigator, context Context, candidate *CandidateNode, segments []*jsonPathSegment, autoCreate bool) (*list.List, error) {
+	current := list.New()
+	current.PushBack(candidate)
+	for _, segment := range segments {
+		next := list.New()
+		for el := current.Front(); el != nil; el = el.Next() {
+			targets := []*CandidateNode{el.Value.(*CandidateNode)}
+			if segment.recursive {
+				targets = getDescendants(targets[0], nil)
+			}
+			for _, target := range targets {
+				// only create the exact paths given, not ones under every descendant
+				if err := segment.apply(d, context, target, autoCreate && !segment.recursive, next); err != nil {
+					return nil, err
+				}
+			}
+		}
+		current = next
+	}
+	return current, nil
+}
@@ -0,0 +1,148 @@
+package yqlib
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+var jsonPointerIndexRegex = regexp.MustCompile(`^(0|[1-9][0-9]*)$`)
+
+var jsonPointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+var jsonPointerUnescaper = strings.NewReplacer("~1", "/", "~0", "~")
+
+// parseJSONPointer splits an RFC 6901 pointer (e.g. /a/b~1c) into its unescaped reference tokens.
+func parseJSONPointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return []string{}, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("'%v' is not a valid json pointer, it must start with '/'", pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = jsonPointerUnescaper.Replace(token)
+	}
+	return tokens, nil
+}
+
+// toJSONPointer formats a path (e.g. [a, 0]) as a json pointer (e.g. /a/0).
+func toJSONPointer(path []interface{}) string {
+	var builder strings.Builder
+	for _, element := range path {
+		builder.WriteString("/" + jsonPointerEscaper.Replace(fmt.Sprintf("%v", element)))
+	}
+	return builder.String()
+}
+
+// parseJSONPointerIndex returns the array index the token refers to, if it is one.
+func parseJSONPointerIndex(token string) (int, bool) {
+	if !jsonPointerIndexRegex.MatchString(token) {
+		return 0, false
+	}
+	index, err := strconv.Atoi(token)
+	return index, err == nil
+}
+
+// getValueAtJSONPointer finds the node the tokens point to, without creating it. Returns nil if there is
+// no such node. Whether a token is an array index depends on the node it is applied to, so the tokens
+// are given to getValueAtPath one at a time.
+func getValueAtJSONPointer(candidate *CandidateNode, tokens []string) (*CandidateNode, error) {
+	current := candidate
+	for _, token := range tokens {
+		var pathElement interface{} = token
+		switch followAlias(unwrapDoc(current.Node)).Kind {
+		case yaml.MappingNode:
+		case yaml.SequenceNode:
+			index, isIndex := parseJSONPointerIndex(token)
+			if !isIndex {
+				return nil, nil
+			}
+			pathElement = int64(index)
+		default:
+			return nil, nil
+		}
+		next, err := getValueAtPath(current, []interface{}{pathElement})
+		if err != nil || next == nil {
+			return nil, err
+		}
+		current = next
+	}
+	return current, nil
+}
+
+// createNullNode creates the placeholder value for a path that doesn't exist yet, like traverse does.
+func createNullNode() *yaml.Node {
+	return &yaml.Node{Tag: "!!null", Kind: yaml.ScalarNode, Value: "null"}
+}
+
+// getChildForAssignment finds the child of the candidate with the given key (for maps) or index
+// (for arrays). Unless autoCreate is false, missing children are added as nulls and null nodes
+// are turned into a map or an array, so that they can be used on the LHS of an assignment.
+func getChildForAssignment(candidate *CandidateNode, key string, index int64, isIndex bool, autoCreate bool) *CandidateNode {
+	if candidate.Node.Kind == yaml.DocumentNode {
+		candidate = candidate.CreateChildInMap(nil, candidate.Node.Content[0])
+	}
+	if candidate.Node.Kind == yaml.AliasNode {
+		candidate = candidate.CreateReplacement(candidate.Node.Alias)
+	}
+	node := candidate.Node
+
+	if node.Tag == "!!null" && autoCreate {
+		node.Tag = ""
+		node.Value = ""
+		node.Kind = yaml.MappingNode
+		if isIndex {
+			node.Kind = yaml.SequenceNode
+		}
+	}
+
+	switch node.Kind {
+	case yaml.MappingNode:
+		if index := jsonPatchChildIndex(node, key); index != -1 {
+			return candidate.CreateChildInMap(node.Content[index-1], node.Content[index])
+		}
+		if !autoCreate {
+			return nil
+		}
+		keyNode := &yaml.Node{Kind: yaml.ScalarNode, Value: key}
+		valueNode := createNullNode()
+		node.Content = append(node.Content, keyNode, valueNode)
+		return candidate.CreateChildInMap(keyNode, valueNode)
+	case yaml.SequenceNode:
+		if !isIndex {
+			return nil
+		}
+		if index < 0 {
+			index = int64(len(node.Content)) + index
+		}
+		if index < 0 || (index >= int64(len(node.Content)) && !autoCreate) {
+			return nil
+		}
+		for int64(len(node.Content)) <= index {
+			node.Content = append(node.Content, createNullNode())
+		}
+		return candidate.CreateChildInArray(int(index), node.Content[index])
+	}
+	return nil
+}
+
+// traverseJSONPointer is like getValueAtJSONPointer, but creates missing nodes unless autoCreate is false.
+// The "-" token (after the last item of an array) is an error, as there is no item there to update.
+func traverseJSONPointer(candidate *CandidateNode, tokens []string, autoCreate bool) (*CandidateNode, error) {
+	current := candidate
+	for _, token := range tokens {
+		if token == "-" && followAlias(unwrapDoc(current.Node)).Kind == yaml.SequenceNode {
+			return nil, fmt.Errorf("cannot use '-' in a pointer to an array, use json_patch with an 'add' operation or '+= [value]' to append to it")
+		}
+		index, isIndex := parseJSONPointerIndex(token)
+		current = getChildForAssignment(current, token, int64(index), isIndex, autoCreate)
+		if current == nil {
+			return nil, nil
+		}
+	}
+	return current, nil
+}
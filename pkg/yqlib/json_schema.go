@@ -0,0 +1,763 @@
+package yqlib
+
+import (
+	"bufio"
+	"fmt"
+	"math"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+// maxSchemaRefDepth stops recursive $refs (e.g. a tree schema validating a value that refers to itself) from looping forever.
+const maxSchemaRefDepth = 100
+
+type jsonSchemaDraft int
+
+const (
+	jsonSchemaDraft2020 jsonSchemaDraft = iota
+	jsonSchemaDraft7
+)
+
+// SchemaViolation is a value that doesn't match the JSON Schema it is validated against.
+type SchemaViolation struct {
+	Filename string
+	Document uint
+	Path     []interface{}
+	Line     int
+	Column   int
+	Message  string
+}
+
+// String formats the violation like a compiler error, e.g. "file.yml:3:5: .a.b: expected integer, got string".
+func (v *SchemaViolation) String() string {
+	location := fmt.Sprintf("%v:%v", v.Line, v.Column)
+	if v.Filename != "" {
+		location = v.Filename + ":" + location
+	}
+	return fmt.Sprintf("%v: %v: %v", location, PathToString(v.Path), v.Message)
+}
+
+// jsonSchemaResource is a schema document, either the one given or one loaded by a $ref.
+type jsonSchemaResource struct {
+	root     *yaml.Node
+	filename string
+	draft    jsonSchemaDraft
+}
+
+// JSONSchema validates documents against a draft 2020-12 or draft-07 JSON Schema.
+type JSONSchema struct {
+	root *jsonSchemaResource
+	// files loaded by $refs, by their path
+	resources map[string]*jsonSchemaResource
+}
+
+func newJSONSchemaResource(root *yaml.Node, filename string) *jsonSchemaResource {
+	root = followAlias(unwrapDoc(root))
+	draft := jsonSchemaDraft2020
+	if schemaURI := getSchemaKeyword(root, "$schema"); schemaURI != nil {
+		for _, oldDraft := range []string{"draft-04", "draft-06", "draft-07"} {
+			if strings.Contains(schemaURI.Value, oldDraft) {
+				draft = jsonSchemaDraft7
+			}
+		}
+	}
+	return &jsonSchemaResource{root: root, filename: filename, draft: draft}
+}
+
+// NewJSONSchema creates a validator for the given schema. Relative $refs are loaded from files
+// relative to the schema's filename, or the working directory if it has none.
+func NewJSONSchema(schema *yaml.Node, filename string) (*JSONSchema, error) {
+	resource := newJSONSchemaResource(schema, filename)
+	if err := checkSchema(resource.root); err != nil {
+		return nil, err
+	}
+	return &JSONSchema{root: resource, resources: map[string]*jsonSchemaResource{}}, nil
+}
+
+// LoadJSONSchema loads the schema file (yaml or json), the same way as the load operator.
+// The filename "-" reads the schema from STDIN.
+func LoadJSONSchema(filename string) (*JSONSchema, error) {
+	var schema *CandidateNode
+	var err error
+	if filename == "-" {
+		schema, err = readSchemaFromStdin()
+	} else {
+		schema, err = loadYaml(filename, NewYamlDecoder())
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Failed to load %v: %w", filename, err)
+	}
+	return NewJSONSchema(schema.Node, filename)
+}
+
+func readSchemaFromStdin() (*CandidateNode, error) {
+	documents, err := readDocuments(bufio.NewReader(os.Stdin), "-", 0, NewYamlDecoder())
+	if err != nil {
+		return nil, err
+	}
+	if documents.Len() != 1 {
+		return nil, fmt.Errorf("expected one schema document, got %v", documents.Len())
+	}
+	return documents.Front().Value.(*CandidateNode), nil
+}
+
+func checkSchema(schema *yaml.Node) error {
+	if schema.Kind != yaml.MappingNode && schema.Tag != "!!bool" {
+		return fmt.Errorf("a json schema must be an object or a boolean, got %v", schema.Tag)
+	}
+	return nil
+}
+
+func getSchemaKeyword(schema *yaml.Node, keyword string) *yaml.Node {
+	if schema.Kind != yaml.MappingNode {
+		return nil
+	}
+	if index := jsonPatchChildIndex(schema, keyword); index != -1 {
+		return followAlias(schema.Content[index])
+	}
+	return nil
+}
+
+// ValidateFiles validates every document of each file against the schema.
+func (s *JSONSchema) ValidateFiles(filenames []string, decoder Decoder) ([]*SchemaViolation, error) {
+	violations := []*SchemaViolation{}
+	for fileIndex, filename := range filenames {
+		documents, err := readDiffDocuments(filename, fileIndex, decoder)
+		if err != nil {
+			return nil, err
+		}
+		for el := documents.Front(); el != nil; el = el.Next() {
+			found, err := s.Validate(el.Value.(*CandidateNode))
+			if err != nil {
+				return nil, err
+			}
+			violations = append(violations, found...)
+		}
+	}
+	return violations, nil
+}
+
+// Validate returns the violations of the candidate and its children, with paths from the candidate's path.
+func (s *JSONSchema) Validate(candidate *CandidateNode) ([]*SchemaViolation, error) {
+	path := append([]interface{}{}, candidate.Path...)
+	violations, err := s.validate(s.root, s.root.root, path, candidate.Node, 0)
+	if err != nil {
+		return nil, err
+	}
+	for _, violation := range violations {
+		violation.Filename = candidate.Filename
+		violation.Document = candidate.Document
+	}
+	return violations, nil
+}
+
+func newSchemaViolation(path []interface{}, node *yaml.Node, format string, args ...interface{}) *SchemaViolation {
+	return &SchemaViolation{Path: path, Line: node.Line, Column: node.Column, Message: fmt.Sprintf(format, args...)}
+}
+
+func (s *JSONSchema) resolveRef(resource *jsonSchemaResource, ref string) (*jsonSchemaResource, *yaml.Node, error) {
+	location, fragment := ref, ""
+	if index := strings.Index(ref, "#"); index != -1 {
+		location, fragment = ref[:index], ref[index+1:]
+	}
+
+	if location != "" {
+		if strings.Contains(location, "://") {
+			return nil, nil, fmt.Errorf("cannot resolve $ref '%v', only local files are supported", ref)
+		}
+		filename := location
+		if !filepath.IsAbs(filename) && resource.filename != "" && resource.filename != "-" {
+			filename = filepath.Join(filepath.Dir(resource.filename), filename)
+		}
+		loaded, isLoaded := s.resources[filename]
+		if !isLoaded {
+			schema, err := loadYaml(filename, NewYamlDecoder())
+			if err != nil {
+				return nil, nil, fmt.Errorf("cannot resolve $ref '%v': %w", ref, err)
+			}
+			loaded = newJSONSchemaResource(schema.Node, filename)
+			s.resources[filename] = loaded
+		}
+		resource = loaded
+	}
+
+	fragment, err := url.PathUnescape(fragment)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot resolve $ref '%v': %w", ref, err)
+	}
+	var found *yaml.Node
+	if fragment == "" {
+		found = resource.root
+	} else if strings.HasPrefix(fragment, "/") {
+		tokens, err := parseJSONPointer(fragment)
+		if err != nil {
+			return nil, nil, err
+		}
+		candidate, err := getValueAtJSONPointer(&CandidateNode{Node: resource.root}, tokens)
+		if err != nil {
+			return nil, nil, err
+		}
+		if candidate != nil {
+			found = followAlias(candidate.Node)
+		}
+	} else {
+		found = findSchemaAnchor(resource.root, fragment)
+	}
+	if found == nil {
+		return nil, nil, fmt.Errorf("cannot resolve $ref '%v'", ref)
+	}
+	return resource, found, checkSchema(found)
+}
+
+// findSchemaAnchor finds the subschema with the given $anchor (or draft-07 style `$id: "#anchor"`).
+func findSchemaAnchor(schema *yaml.Node, anchor string) *yaml.Node {
+	if anchorNode := getSchemaKeyword(schema, "$anchor"); anchorNode != nil && anchorNode.Value == anchor {
+		return schema
+	}
+	if idNode := getSchemaKeyword(schema, "$id"); idNode != nil && idNode.Value == "#"+anchor {
+		return schema
+	}
+	for _, child := range schema.Content {
+		if found := findSchemaAnchor(followAlias(child), anchor); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+func jsonSchemaType(node *yaml.Node) string {
+	switch node.Kind {
+	case yaml.MappingNode:
+		return "object"
+	case yaml.SequenceNode:
+		return "array"
+	}
+	switch node.Tag {
+	case "!!null":
+		return "null"
+	case "!!bool":
+		return "boolean"
+	case "!!int":
+		return "integer"
+	case "!!float":
+		return "number"
+	}
+	return "string"
+}
+
+func jsonSchemaNumber(node *yaml.Node) (float64, bool) {
+	switch node.Tag {
+	case "!!int":
+		_, number, err := parseInt(node.Value)
+		return float64(number), err == nil
+	case "!!float":
+		number, err := strconv.ParseFloat(node.Value, 64)
+		return number, err == nil
+	}
+	return 0, false
+}
+
+func jsonSchemaTypeMatches(node *yaml.Node, typeName string) bool {
+	actual := jsonSchemaType(node)
+	switch {
+	case actual == typeName:
+		return true
+	case typeName == "number":
+		return actual == "integer"
+	case typeName == "integer" && actual == "number":
+		number, isNumber := jsonSchemaNumber(node)
+		return isNumber && number == math.Trunc(number)
+	}
+	return false
+}
+
+// jsonValueEqual compares values the way JSON Schema does: numbers by value, and maps ignoring key order.
+func jsonValueEqual(lhs *yaml.Node, rhs *yaml.Node) bool {
+	lhs = followAlias(unwrapDoc(lhs))
+	rhs = followAlias(unwrapDoc(rhs))
+	lhsNumber, lhsIsNumber := jsonSchemaNumber(lhs)
+	rhsNumber, rhsIsNumber := jsonSchemaNumber(rhs)
+	if lhsIsNumber && rhsIsNumber {
+		return lhsNumber == rhsNumber
+	}
+	lhsType := jsonSchemaType(lhs)
+	if lhsType != jsonSchemaType(rhs) || len(lhs.Content) != len(rhs.Content) {
+		return false
+	}
+	switch lhsType {
+	case "object":
+		for index := 0; index < len(lhs.Content); index = index + 2 {
+			rhsIndex := jsonPatchChildIndex(rhs, lhs.Content[index].Value)
+			if rhsIndex == -1 || !jsonValueEqual(lhs.Content[index+1], rhs.Content[rhsIndex]) {
+				return false
+			}
+		}
+		return true
+	case "array":
+		for index := range lhs.Content {
+			if !jsonValueEqual(lhs.Content[index], rhs.Content[index]) {
+				return false
+			}
+		}
+		return true
+	case "null":
+		return true
+	case "boolean":
+		return strings.EqualFold(lhs.Value, rhs.Value)
+	}
+	return lhs.Value == rhs.Value
+}
+
+func formatSchemaValue(node *yaml.Node) string {
+	formatted, err := formatFlowNode(node)
+	if err != nil {
+		return node.Value
+	}
+	return formatted
+}
+
+// isValid checks the node against a subschema, for keywords like anyOf and not that only need to know if it matched.
+func (s *JSONSchema) isValid(resource *jsonSchemaResource, schema *yaml.Node, path []interface{}, node *yaml.Node, depth int) (bool, error) {
+	violations, err := s.validate(resource, schema, path, node, depth)
+	return len(violations) == 0, err
+}
+
+func (s *JSONSchema) validate(resource *jsonSchemaResource, schema *yaml.Node, path []interface{}, node *yaml.Node, depth int) ([]*SchemaViolation, error) {
+	node = followAlias(unwrapDoc(node))
+	schema = followAlias(schema)
+	if err := checkSchema(schema); err != nil {
+		return nil, err
+	}
+	if schema.Tag == "!!bool" {
+		if schema.Value == "false" {
+			return []*SchemaViolation{newSchemaViolation(path, node, "is not allowed")}, nil
+		}
+		return nil, nil
+	}
+
+	violations := []*SchemaViolation{}
+	if ref := getSchemaKeyword(schema, "$ref"); ref != nil {
+		if depth >= maxSchemaRefDepth {
+			return nil, fmt.Errorf("$ref '%v' is nested too deeply, is it recursive?", ref.Value)
+		}
+		refResource, refSchema, err := s.resolveRef(resource, ref.Value)
+		if err != nil {
+			return nil, err
+		}
+		found, err := s.validate(refResource, refSchema, path, node, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		violations = append(violations, found...)
+		if resource.draft == jsonSchemaDraft7 {
+			// before 2019-09, keywords next to a $ref are ignored
+			return violations, nil
+		}
+	}
+
+	checks := []func(*jsonSchemaResource, *yaml.Node, []interface{}, *yaml.Node, int) ([]*SchemaViolation, error){
+		s.validateGeneric, s.validateNumber, s.validateString, s.validateArray, s.validateObject, s.validateCombinators,
+	}
+	for _, check := range checks {
+		found, err := check(resource, schema, path, node, depth)
+		if err != nil {
+			return nil, err
+		}
+		violations = append(violations, found...)
+	}
+	return violations, nil
+}
+
+func (s *JSONSchema) validateGeneric(resource *jsonSchemaResource, schema *yaml.Node, path []interface{}, node *yaml.Node, depth int) ([]*SchemaViolation, error) {
+	violations := []*SchemaViolation{}
+	if typeNode := getSchemaKeyword(schema, "type"); typeNode != nil {
+		typeNames := []string{typeNode.Value}
+		if typeNode.Kind == yaml.SequenceNode {
+			typeNames = []string{}
+			for _, typeName := range typeNode.Content {
+				typeNames = append(typeNames, typeName.Value)
+			}
+		}
+		matches := false
+		for _, typeName := range typeNames {
+			matches = matches || jsonSchemaTypeMatches(node, typeName)
+		}
+		if !matches {
+			violations = append(violations, newSchemaViolation(path, node, "expected %v, got %v", strings.Join(typeNames, " or "), jsonSchemaType(node)))
+		}
+	}
+	if enum := getSchemaKeyword(schema, "enum"); enum != nil {
+		matches := false
+		for _, value := range enum.Content {
+			matches = matches || jsonValueEqual(node, value)
+		}
+		if !matches {
+			violations = append(violations, newSchemaViolation(path, node, "must be one of %v", formatSchemaValue(enum)))
+		}
+	}
+	if constant := getSchemaKeyword(schema, "const"); constant != nil && !jsonValueEqual(node, constant) {
+		violations = append(violations, newSchemaViolation(path, node, "must be %v", formatSchemaValue(constant)))
+	}
+	return violations, nil
+}
+
+func (s *JSONSchema) validateNumber(resource *jsonSchemaResource, schema *yaml.Node, path []interface{}, node *yaml.Node, depth int) ([]*SchemaViolation, error) {
+	value, isNumber := jsonSchemaNumber(node)
+	if !isNumber {
+		return nil, nil
+	}
+	violations := []*SchemaViolation{}
+	limits := []struct {
+		keyword string
+		invalid func(value float64, limit float64) bool
+		message string
+	}{
+		{"minimum", func(value float64, limit float64) bool { return value < limit }, "must be >= %v"},
+		{"maximum", func(value float64, limit float64) bool { return value > limit }, "must be <= %v"},
+		{"exclusiveMinimum", func(value float64, limit float64) bool { return value <= limit }, "must be > %v"},
+		{"exclusiveMaximum", func(value float64, limit float64) bool { return value >= limit }, "must be < %v"},
+		{"multipleOf", func(value float64, limit float64) bool {
+			quotient := value / limit
+			return math.Abs(quotient-math.Round(quotient)) > 1e-9
+		}, "must be a multiple of %v"},
+	}
+	for _, limit := range limits {
+		limitNode := getSchemaKeyword(schema, limit.keyword)
+		if limitNode == nil {
+			continue
+		}
+		// draft-04 style boolean exclusiveMinimum/exclusiveMaximum are not numbers, and are skipped
+		if limitValue, isLimitNumber := jsonSchemaNumber(limitNode); isLimitNumber && limit.invalid(value, limitValue) {
+			violations = append(violations, newSchemaViolation(path, node, limit.message, limitNode.Value))
+		}
+	}
+	return violations, nil
+}
+
+func (s *JSONSchema) validateString(resource *jsonSchemaResource, schema *yaml.Node, path []interface{}, node *yaml.Node, depth int) ([]*SchemaViolation, error) {
+	if jsonSchemaType(node) != "string" {
+		return nil, nil
+	}
+	violations := []*SchemaViolation{}
+	length := utf8.RuneCountInString(node.Value)
+	if minLength := getSchemaKeyword(schema, "minLength"); minLength != nil {
+		if limit, _ := jsonSchemaNumber(minLength); float64(length) < limit {
+			violations = append(violations, newSchemaViolation(path, node, "must be at least %v characters long", minLength.Value))
+		}
+	}
+	if maxLength := getSchemaKeyword(schema, "maxLength"); maxLength != nil {
+		if limit, _ := jsonSchemaNumber(maxLength); float64(length) > limit {
+			violations = append(violations, newSchemaViolation(path, node, "must be at most %v characters long", maxLength.Value))
+		}
+	}
+	if pattern := getSchemaKeyword(schema, "pattern"); pattern != nil {
+		regex, err := regexp.Compile(pattern.Value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern '%v' in schema: %w", pattern.Value, err)
+		}
+		if !regex.MatchString(node.Value) {
+			violations = append(violations, newSchemaViolation(path, node, "must match the pattern '%v'", pattern.Value))
+		}
+	}
+	return violations, nil
+}
+
+func (s *JSONSchema) validateArray(resource *jsonSchemaResource, schema *yaml.Node, path []interface{}, node *yaml.Node, depth int) ([]*SchemaViolation, error) {
+	if node.Kind != yaml.SequenceNode {
+		return nil, nil
+	}
+	violations := []*SchemaViolation{}
+	validateItem := func(itemSchema *yaml.Node, index int) error {
+		found, err := s.validate(resource, itemSchema, childPath(path, index), node.Content[index], depth)
+		violations = append(violations, found...)
+		return err
+	}
+
+	// draft 2020-12 has prefixItems and items, draft-07 has an array of items and additionalItems.
+	// An array of items can only be draft-07, even if the schema doesn't say which draft it is.
+	prefixItems := getSchemaKeyword(schema, "prefixItems")
+	items := getSchemaKeyword(schema, "items")
+	additionalItems := items
+	if items != nil && items.Kind == yaml.SequenceNode {
+		prefixItems = items
+		additionalItems = getSchemaKeyword(schema, "additionalItems")
+	} else if resource.draft == jsonSchemaDraft7 {
+		prefixItems = nil
+	}
+	prefixLength := 0
+	if prefixItems != nil {
+		for index := 0; index < len(prefixItems.Content) && index < len(node.Content); index++ {
+			if err := validateItem(prefixItems.Content[index], index); err != nil {
+				return nil, err
+			}
+		}
+		prefixLength = len(prefixItems.Content)
+	}
+	if additionalItems != nil {
+		for index := prefixLength; index < len(node.Content); index++ {
+			if err := validateItem(additionalItems, index); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if contains := getSchemaKeyword(schema, "contains"); contains != nil {
+		matches := 0
+		for index, item := range node.Content {
+			valid, err := s.isValid(resource, contains, childPath(path, index), item, depth)
+			if err != nil {
+				return nil, err
+			}
+			if valid {
+				matches++
+			}
+		}
+		minContains, maxContains := float64(1), math.Inf(1)
+		if minNode := getSchemaKeyword(schema, "minContains"); minNode != nil {
+			minContains, _ = jsonSchemaNumber(minNode)
+		}
+		if maxNode := getSchemaKeyword(schema, "maxContains"); maxNode != nil {
+			maxContains, _ = jsonSchemaNumber(maxNode)
+		}
+		if float64(matches) < minContains {
+			violations = append(violations, newSchemaViolation(path, node, "must contain at least %v matching item(s), found %v", minContains, matches))
+		} else if float64(matches) > maxContains {
+			violations = append(violations, newSchemaViolation(path, node, "must contain at most %v matching item(s), found %v", maxContains, matches))
+		}
+	}
+
+	if minItems := getSchemaKeyword(schema, "minItems"); minItems != nil {
+		if limit, _ := jsonSchemaNumber(minItems); float64(len(node.Content)) < limit {
+			violations = append(violations, newSchemaViolation(path, node, "must have at least %v items", minItems.Value))
+		}
+	}
+	if maxItems := getSchemaKeyword(schema, "maxItems"); maxItems != nil {
+		if limit, _ := jsonSchemaNumber(maxItems); float64(len(node.Content)) > limit {
+			violations = append(violations, newSchemaViolation(path, node, "must have at most %v items", maxItems.Value))
+		}
+	}
+	if uniqueItems := getSchemaKeyword(schema, "uniqueItems"); uniqueItems != nil && uniqueItems.Value == "true" {
+		for index := 1; index < len(node.Content); index++ {
+			for previous := 0; previous < index; previous++ {
+				if jsonValueEqual(node.Content[previous], node.Content[index]) {
+					violations = append(violations, newSchemaViolation(childPath(path, index), node.Content[index], "must be unique, it is the same as item %v", previous))
+					break
+				}
+			}
+		}
+	}
+	return violations, nil
+}
+
+func (s *JSONSchema) validateObject(resource *jsonSchemaResource, schema *yaml.Node, path []interface{}, node *yaml.Node, depth int) ([]*SchemaViolation, error) {
+	if node.Kind != yaml.MappingNode {
+		return nil, nil
+	}
+	violations := []*SchemaViolation{}
+	validateValue := func(subschema *yaml.Node, path []interface{}, value *yaml.Node) error {
+		found, err := s.validate(resource, subschema, path, value, depth)
+		violations = append(violations, found...)
+		return err
+	}
+
+	properties := getSchemaKeyword(schema, "properties")
+	patternProperties := getSchemaKeyword(schema, "patternProperties")
+	additionalProperties := getSchemaKeyword(schema, "additionalProperties")
+	propertyNames := getSchemaKeyword(schema, "propertyNames")
+	for index := 0; index < len(node.Content); index = index + 2 {
+		key := node.Content[index]
+		value := node.Content[index+1]
+		propertyPath := childPath(path, key.Value)
+		evaluated := false
+
+		if properties != nil {
+			if propertyIndex := jsonPatchChildIndex(properties, key.Value); propertyIndex != -1 {
+				evaluated = true
+				if err := validateValue(properties.Content[propertyIndex], propertyPath, value); err != nil {
+					return nil, err
+				}
+			}
+		}
+		if patternProperties != nil {
+			for patternIndex := 0; patternIndex < len(patternProperties.Content); patternIndex = patternIndex + 2 {
+				regex, err := regexp.Compile(patternProperties.Content[patternIndex].Value)
+				if err != nil {
+					return nil, fmt.Errorf("invalid pattern '%v' in schema: %w", patternProperties.Content[patternIndex].Value, err)
+				}
+				if regex.MatchString(key.Value) {
+					evaluated = true
+					if err := validateValue(patternProperties.Content[patternIndex+1], propertyPath, value); err != nil {
+						return nil, err
+					}
+				}
+			}
+		}
+		if additionalProperties != nil && !evaluated {
+			if additionalProperties.Tag == "!!bool" && additionalProperties.Value == "false" {
+				violations = append(violations, newSchemaViolation(propertyPath, key, "property '%v' is not allowed", key.Value))
+			} else if err := validateValue(additionalProperties, propertyPath, value); err != nil {
+				return nil, err
+			}
+		}
+		if propertyNames != nil {
+			found, err := s.validate(resource, propertyNames, propertyPath, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key.Value, Line: key.Line, Column: key.Column}, depth)
+			if err != nil {
+				return nil, err
+			}
+			for _, violation := range found {
+				violation.Message = fmt.Sprintf("property name '%v' %v", key.Value, violation.Message)
+			}
+			violations = append(violations, found...)
+		}
+	}
+
+	requireProperties := func(required *yaml.Node, reason string) {
+		for _, name := range required.Content {
+			if jsonPatchChildIndex(node, name.Value) == -1 {
+				violations = append(violations, newSchemaViolation(path, node, "missing required property '%v'%v", name.Value, reason))
+			}
+		}
+	}
+	if required := getSchemaKeyword(schema, "required"); required != nil && required.Kind == yaml.SequenceNode {
+		requireProperties(required, "")
+	}
+
+	// draft-07 dependencies are split into dependentRequired and dependentSchemas in 2019-09
+	for _, keyword := range []string{"dependencies", "dependentRequired", "dependentSchemas"} {
+		dependencies := getSchemaKeyword(schema, keyword)
+		if dependencies == nil {
+			continue
+		}
+		for index := 0; index < len(dependencies.Content); index = index + 2 {
+			name := dependencies.Content[index].Value
+			if jsonPatchChildIndex(node, name) == -1 {
+				continue
+			}
+			dependency := followAlias(dependencies.Content[index+1])
+			if dependency.Kind == yaml.SequenceNode {
+				requireProperties(dependency, fmt.Sprintf(", as '%v' is present", name))
+			} else if err := validateValue(dependency, path, node); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if minProperties := getSchemaKeyword(schema, "minProperties"); minProperties != nil {
+		if limit, _ := jsonSchemaNumber(minProperties); float64(len(node.Content)/2) < limit {
+			violations = append(violations, newSchemaViolation(path, node, "must have at least %v properties", minProperties.Value))
+		}
+	}
+	if maxProperties := getSchemaKeyword(schema, "maxProperties"); maxProperties != nil {
+		if limit, _ := jsonSchemaNumber(maxProperties); float64(len(node.Content)/2) > limit {
+			violations = append(violations, newSchemaViolation(path, node, "must have at most %v properties", maxProperties.Value))
+		}
+	}
+	return violations, nil
+}
+
+func (s *JSONSchema) validateCombinators(resource *jsonSchemaResource, schema *yaml.Node, path []interface{}, node *yaml.Node, depth int) ([]*SchemaViolation, error) {
+	violations := []*SchemaViolation{}
+	if allOf := getSchemaKeyword(schema, "allOf"); allOf != nil {
+		for _, subschema := range allOf.Content {
+			found, err := s.validate(resource, subschema, path, node, depth)
+			if err != nil {
+				return nil, err
+			}
+			violations = append(violations, found...)
+		}
+	}
+
+	countValid := func(subschemas *yaml.Node) (int, error) {
+		count := 0
+		for _, subschema := range subschemas.Content {
+			valid, err := s.isValid(resource, subschema, path, node, depth)
+			if err != nil {
+				return 0, err
+			}
+			if valid {
+				count++
+			}
+		}
+		return count, nil
+	}
+	if anyOf := getSchemaKeyword(schema, "anyOf"); anyOf != nil {
+		count, err := countValid(anyOf)
+		if err != nil {
+			return nil, err
+		}
+		if count == 0 {
+			violations = append(violations, newSchemaViolation(path, node, "must match at least one of the anyOf schemas"))
+		}
+	}
+	if oneOf := getSchemaKeyword(schema, "oneOf"); oneOf != nil {
+		count, err := countValid(oneOf)
+		if err != nil {
+			return nil, err
+		}
+		if count != 1 {
+			violations = append(violations, newSchemaViolation(path, node, "must match exactly one of the oneOf schemas, but matched %v", count))
+		}
+	}
+	if not := getSchemaKeyword(schema, "not"); not != nil {
+		valid, err := s.isValid(resource, not, path, node, depth)
+		if err != nil {
+			return nil, err
+		}
+		if valid {
+			violations = append(violations, newSchemaViolation(path, node, "must not match the schema in not"))
+		}
+	}
+
+	if condition := getSchemaKeyword(schema, "if"); condition != nil {
+		valid, err := s.isValid(resource, condition, path, node, depth)
+		if err != nil {
+			return nil, err
+		}
+		branch := getSchemaKeyword(schema, "else")
+		if valid {
+			branch = getSchemaKeyword(schema, "then")
+		}
+		if branch != nil {
+			found, err := s.validate(resource, branch, path, node, depth)
+			if err != nil {
+				return nil, err
+			}
+			violations = append(violations, found...)
+		}
+	}
+	return violations, nil
+}
+
+// SchemaViolationsToNode creates an array with a map for each violation, for printing as yaml or json.
+func SchemaViolationsToNode(violations []*SchemaViolation) *yaml.Node {
+	return schemaViolationsToNode(violations, true)
+}
+
+func schemaViolationsToNode(violations []*SchemaViolation, includeSource bool) *yaml.Node {
+	seq := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+	for _, violation := range violations {
+		path := PathToString(violation.Path)
+		entry := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		if includeSource {
+			entry.Content = append(entry.Content,
+				createScalarNode("file", "file"), createScalarNode(violation.Filename, violation.Filename),
+				createScalarNode("document", "document"), createIntNode(int(violation.Document)),
+			)
+		}
+		entry.Content = append(entry.Content,
+			createScalarNode("path", "path"), createScalarNode(path, path),
+			createScalarNode("line", "line"), createIntNode(violation.Line),
+			createScalarNode("column", "column"), createIntNode(violation.Column),
+			createScalarNode("message", "message"), createScalarNode(violation.Message, violation.Message),
+		)
+		seq.Content = append(seq.Content, entry)
+	}
+	return seq
+}
@@ -6,6 +6,7 @@ import (
 	"bytes"
 	"container/list"
 	"fmt"
+	"math"
 	"strconv"
 	"strings"
 
@@ -45,6 +46,12 @@ type operationType struct {
 var orOpType = &operationType{Type: "OR", NumArgs: 2, Precedence: 20, Handler: orOperator}
 var andOpType = &operationType{Type: "AND", NumArgs: 2, Precedence: 20, Handler: andOperator}
 var reduceOpType = &operationType{Type: "REDUCE", NumArgs: 2, Precedence: 35, Handler: reduceOperator}
+var foreachOpType = &operationType{Type: "FOREACH", NumArgs: 2, Precedence: 35, Handler: foreachOperator}
+
+// try binds tighter than arithmetic, but looser than '.a.b' style traversals so the whole path is tried.
+var tryOpType = &operationType{Type: "TRY", NumArgs: 1, Precedence: 44, Handler: tryOperator}
+var catchOpType = &operationType{Type: "CATCH", NumArgs: 2, Precedence: 43, Handler: catchOperator}
+var optionalOpType = &operationType{Type: "OPTIONAL", NumArgs: 1, Precedence: 49, Handler: tryOperator}
 
 var blockOpType = &operationType{Type: "BLOCK", Precedence: 10, NumArgs: 2, Handler: emptyOperator}
 
@@ -58,6 +65,70 @@ var subtractAssignOpType = &operationType{Type: "SUBTRACT_ASSIGN", NumArgs: 2, P
 
 var assignAttributesOpType = &operationType{Type: "ASSIGN_ATTRIBUTES", NumArgs: 2, Precedence: 40, Handler: assignAttributesOperator}
 var assignStyleOpType = &operationType{Type: "ASSIGN_STYLE", NumArgs: 2, Precedence: 40, Handler: assignStyleOperator}
+
+// binds tighter than 'as', so that `. as [$a] ?// $a` groups the patterns together
+var alternativePatternOpType = &operationType{Type: "ALTERNATIVE_PATTERN", NumArgs: 2, Precedence: 42, Handler: alternativePatternOperator}
+
+var limitOpType = &operationType{Type: "LIMIT", NumArgs: 1, Precedence: 50, Handler: limitOperator}
+var firstOpType = &operationType{Type: "FIRST", NumArgs: 1, Precedence: 50, Handler: firstOperator}
+var lastOpType = &operationType{Type: "LAST", NumArgs: 1, Precedence: 50, Handler: lastOperator}
+var nthOpType = &operationType{Type: "NTH", NumArgs: 1, Precedence: 50, Handler: nthOperator}
+var firstElementOpType = &operationType{Type: "FIRST_ELEMENT", NumArgs: 0, Precedence: 50, Handler: firstElementOperator}
+var lastElementOpType = &operationType{Type: "LAST_ELEMENT", NumArgs: 0, Precedence: 50, Handler: lastElementOperator}
+var rangeOpType = &operationType{Type: "RANGE", NumArgs: 1, Precedence: 50, Handler: rangeOperator}
+var untilOpType = &operationType{Type: "UNTIL", NumArgs: 1, Precedence: 50, Handler: untilOperator}
+var whileOpType = &operationType{Type: "WHILE", NumArgs: 1, Precedence: 50, Handler: whileOperator}
+var repeatOpType = &operationType{Type: "REPEAT", NumArgs: 1, Precedence: 50, Handler: repeatOperator}
+var walkOpType = &operationType{Type: "WALK", NumArgs: 1, Precedence: 50, Handler: walkOperator}
+var recurseOpType = &operationType{Type: "RECURSE", NumArgs: 0, Precedence: 50, Handler: recurseOperator}
+var recurseWithOpType = &operationType{Type: "RECURSE_WITH", NumArgs: 1, Precedence: 50, Handler: recurseWithOperator}
+
+var minOpType = &operationType{Type: "MIN", NumArgs: 0, Precedence: 50, Handler: minOperator}
+var maxOpType = &operationType{Type: "MAX", NumArgs: 0, Precedence: 50, Handler: maxOperator}
+var minOfOpType = &operationType{Type: "MIN_OF", NumArgs: 1, Precedence: 50, Handler: minOfOperator}
+var maxOfOpType = &operationType{Type: "MAX_OF", NumArgs: 1, Precedence: 50, Handler: maxOfOperator}
+var minByOpType = &operationType{Type: "MIN_BY", NumArgs: 1, Precedence: 50, Handler: minByOperator}
+var maxByOpType = &operationType{Type: "MAX_BY", NumArgs: 1, Precedence: 50, Handler: maxByOperator}
+var addArrayOpType = &operationType{Type: "ADD_ARRAY", NumArgs: 0, Precedence: 50, Handler: addArrayOperator}
+var floorOpType = &operationType{Type: "FLOOR", NumArgs: 0, Precedence: 50, Handler: mathOperator(floorFunction)}
+var ceilOpType = &operationType{Type: "CEIL", NumArgs: 0, Precedence: 50, Handler: mathOperator(ceilFunction)}
+var roundOpType = &operationType{Type: "ROUND", NumArgs: 0, Precedence: 50, Handler: mathOperator(roundFunction)}
+var absOpType = &operationType{Type: "ABS", NumArgs: 0, Precedence: 50, Handler: mathOperator(absFunction)}
+var sqrtOpType = &operationType{Type: "SQRT", NumArgs: 0, Precedence: 50, Handler: mathOperator(sqrtFunction)}
+var logOpType = &operationType{Type: "LOG", NumArgs: 0, Precedence: 50, Handler: mathOperator(logFunction)}
+var expOpType = &operationType{Type: "EXP", NumArgs: 0, Precedence: 50, Handler: mathOperator(expFunction)}
+var powOpType = &operationType{Type: "POW", NumArgs: 1, Precedence: 50, Handler: powOperator}
+var sumOpType = &operationType{Type: "SUM", NumArgs: 0, Precedence: 50, Handler: sumOperator}
+var avgOpType = &operationType{Type: "AVG", NumArgs: 0, Precedence: 50, Handler: avgOperator}
+
+var asciiDowncaseOpType = &operationType{Type: "ASCII_DOWNCASE", NumArgs: 0, Precedence: 50, Handler: asciiDowncaseOperator}
+var asciiUpcaseOpType = &operationType{Type: "ASCII_UPCASE", NumArgs: 0, Precedence: 50, Handler: asciiUpcaseOperator}
+var ltrimstrOpType = &operationType{Type: "LTRIMSTR", NumArgs: 1, Precedence: 50, Handler: ltrimstrOperator}
+var rtrimstrOpType = &operationType{Type: "RTRIMSTR", NumArgs: 1, Precedence: 50, Handler: rtrimstrOperator}
+var trimOpType = &operationType{Type: "TRIM", NumArgs: 0, Precedence: 50, Handler: trimOperator}
+var startsWithOpType = &operationType{Type: "STARTS_WITH", NumArgs: 1, Precedence: 50, Handler: startsWithOperator}
+var endsWithOpType = &operationType{Type: "ENDS_WITH", NumArgs: 1, Precedence: 50, Handler: endsWithOperator}
+var indexOpType = &operationType{Type: "INDEX", NumArgs: 1, Precedence: 50, Handler: indexOperator}
+var rindexOpType = &operationType{Type: "RINDEX", NumArgs: 1, Precedence: 50, Handler: rindexOperator}
+var indicesOpType = &operationType{Type: "INDICES", NumArgs: 1, Precedence: 50, Handler: indicesOperator}
+var explodeStringOpType = &operationType{Type: "EXPLODE_STRING", NumArgs: 0, Precedence: 50, Handler: explodeStringOperator}
+var implodeOpType = &operationType{Type: "IMPLODE", NumArgs: 0, Precedence: 50, Handler: implodeOperator}
+var asciiOpType = &operationType{Type: "ASCII", NumArgs: 0, Precedence: 50, Handler: asciiOperator}
+var typeOpType = &operationType{Type: "TYPE", NumArgs: 0, Precedence: 50, Handler: typeOperator}
+var kindOpType = &operationType{Type: "KIND", NumArgs: 0, Precedence: 50, Handler: kindOperator}
+var arraysOpType = &operationType{Type: "ARRAYS", NumArgs: 0, Precedence: 50, Handler: arraysOperator}
+var objectsOpType = &operationType{Type: "OBJECTS", NumArgs: 0, Precedence: 50, Handler: objectsOperator}
+var iterablesOpType = &operationType{Type: "ITERABLES", NumArgs: 0, Precedence: 50, Handler: iterablesOperator}
+var scalarsOpType = &operationType{Type: "SCALARS", NumArgs: 0, Precedence: 50, Handler: scalarsOperator}
+var stringsOpType = &operationType{Type: "STRINGS", NumArgs: 0, Precedence: 50, Handler: stringsOperator}
+var numbersOpType = &operationType{Type: "NUMBERS", NumArgs: 0, Precedence: 50, Handler: numbersOperator}
+var booleansOpType = &operationType{Type: "BOOLEANS", NumArgs: 0, Precedence: 50, Handler: booleansOperator}
+var nullsOpType = &operationType{Type: "NULLS", NumArgs: 0, Precedence: 50, Handler: nullsOperator}
+var toBoolOpType = &operationType{Type: "TO_BOOL", NumArgs: 0, Precedence: 50, Handler: toBoolOperator}
+
+var toStringOpType = &operationType{Type: "TO_STRING", NumArgs: 0, Precedence: 50, Handler: toStringOperator}
+var toNumberOpType = &operationType{Type: "TO_NUMBER", NumArgs: 0, Precedence: 50, Handler: toNumberOperator}
+
 var assignVariableOpType = &operationType{Type: "ASSIGN_VARIABLE", NumArgs: 2, Precedence: 40, Handler: assignVariableOperator}
 var assignTagOpType = &operationType{Type: "ASSIGN_TAG", NumArgs: 2, Precedence: 40, Handler: assignTagOperator}
 var assignCommentOpType = &operationType{Type: "ASSIGN_COMMENT", NumArgs: 2, Precedence: 40, Handler: assignCommentsOperator}
@@ -65,6 +136,8 @@ var assignAnchorOpType = &operationType{Type: "ASSIGN_ANCHOR", NumArgs: 2, Prece
 var assignAliasOpType = &operationType{Type: "ASSIGN_ALIAS", NumArgs: 2, Precedence: 40, Handler: assignAliasOperator}
 
 var multiplyOpType = &operationType{Type: "MULTIPLY", NumArgs: 2, Precedence: 42, Handler: multiplyOperator}
+var mergePatchAllOpType = &operationType{Type: "MERGE_PATCH_ALL", NumArgs: 0, Precedence: 50, Handler: mergePatchAllOperator}
+var strategicMergeOpType = &operationType{Type: "STRATEGIC_MERGE", NumArgs: 1, Precedence: 50, Handler: strategicMergeOperator}
 var multiplyAssignOpType = &operationType{Type: "MULTIPLY_ASSIGN", NumArgs: 2, Precedence: 42, Handler: multiplyAssignOperator}
 
 var addOpType = &operationType{Type: "ADD", NumArgs: 2, Precedence: 42, Handler: addOperator}
@@ -73,6 +146,7 @@ var alternativeOpType = &operationType{Type: "ALTERNATIVE", NumArgs: 2, Preceden
 
 var equalsOpType = &operationType{Type: "EQUALS", NumArgs: 2, Precedence: 40, Handler: equalsOperator}
 var notEqualsOpType = &operationType{Type: "EQUALS", NumArgs: 2, Precedence: 40, Handler: notEqualsOperator}
+var compareOpType = &operationType{Type: "COMPARE", NumArgs: 2, Precedence: 40, Handler: compareOperator}
 
 //createmap needs to be above union, as we use union to build the components of the objects
 var createMapOpType = &operationType{Type: "CREATE_MAP", NumArgs: 2, Precedence: 15, Handler: createMapOperator}
@@ -85,6 +159,7 @@ var mapOpType = &operationType{Type: "MAP", NumArgs: 1, Precedence: 50, Handler:
 var evalOpType = &operationType{Type: "EVAL", NumArgs: 1, Precedence: 50, Handler: evalOperator}
 var mapValuesOpType = &operationType{Type: "MAP_VALUES", NumArgs: 1, Precedence: 50, Handler: mapValuesOperator}
 var encodeOpType = &operationType{Type: "ENCODE", NumArgs: 0, Precedence: 50, Handler: encodeOperator}
+var encodeShellOpType = &operationType{Type: "ENCODE_SHELL", NumArgs: 0, Precedence: 50, Handler: encodeShellOperator}
 var decodeOpType = &operationType{Type: "DECODE", NumArgs: 0, Precedence: 50, Handler: decodeOperator}
 
 var anyOpType = &operationType{Type: "ANY", NumArgs: 0, Precedence: 50, Handler: anyOperator}
@@ -114,6 +189,15 @@ var getDocumentIndexOpType = &operationType{Type: "GET_DOCUMENT_INDEX", NumArgs:
 var getFilenameOpType = &operationType{Type: "GET_FILENAME", NumArgs: 0, Precedence: 50, Handler: getFilenameOperator}
 var getFileIndexOpType = &operationType{Type: "GET_FILE_INDEX", NumArgs: 0, Precedence: 50, Handler: getFileIndexOperator}
 var getPathOpType = &operationType{Type: "GET_PATH", NumArgs: 0, Precedence: 50, Handler: getPathOperator}
+var pathsOpType = &operationType{Type: "PATHS", NumArgs: 0, Precedence: 50, Handler: pathsOperator}
+var pathsFilterOpType = &operationType{Type: "PATHS_FILTER", NumArgs: 1, Precedence: 50, Handler: pathsFilterOperator}
+var leafPathsOpType = &operationType{Type: "LEAF_PATHS", NumArgs: 0, Precedence: 50, Handler: leafPathsOperator}
+var getValueAtPathOpType = &operationType{Type: "GET_VALUE_AT_PATH", NumArgs: 1, Precedence: 50, Handler: getValueAtPathOperator}
+var setValueAtPathOpType = &operationType{Type: "SET_VALUE_AT_PATH", NumArgs: 1, Precedence: 50, Handler: setValueAtPathOperator}
+var deletePathsOpType = &operationType{Type: "DELETE_PATHS", NumArgs: 1, Precedence: 50, Handler: deletePathsOperator}
+var toStreamOpType = &operationType{Type: "TO_STREAM", NumArgs: 0, Precedence: 50, Handler: toStreamOperator}
+var fromStreamOpType = &operationType{Type: "FROM_STREAM", NumArgs: 1, Precedence: 50, Handler: fromStreamOperator}
+var pickOpType = &operationType{Type: "PICK", NumArgs: 1, Precedence: 50, Handler: pickOperator}
 
 var explodeOpType = &operationType{Type: "EXPLODE", NumArgs: 1, Precedence: 50, Handler: explodeOperator}
 var sortByOpType = &operationType{Type: "SORT_BY", NumArgs: 1, Precedence: 50, Handler: sortByOperator}
@@ -121,11 +205,14 @@ var sortOpType = &operationType{Type: "SORT", NumArgs: 0, Precedence: 50, Handle
 var sortKeysOpType = &operationType{Type: "SORT_KEYS", NumArgs: 1, Precedence: 50, Handler: sortKeysOperator}
 var joinStringOpType = &operationType{Type: "JOIN", NumArgs: 1, Precedence: 50, Handler: joinStringOperator}
 var subStringOpType = &operationType{Type: "SUBSTR", NumArgs: 1, Precedence: 50, Handler: substituteStringOperator}
+var globalSubStringOpType = &operationType{Type: "GLOBAL_SUBSTR", NumArgs: 1, Precedence: 50, Handler: globalSubstituteStringOperator}
 var matchOpType = &operationType{Type: "MATCH", NumArgs: 1, Precedence: 50, Handler: matchOperator}
 var captureOpType = &operationType{Type: "CAPTURE", NumArgs: 1, Precedence: 50, Handler: captureOperator}
 var testOpType = &operationType{Type: "TEST", NumArgs: 1, Precedence: 50, Handler: testOperator}
 var splitStringOpType = &operationType{Type: "SPLIT", NumArgs: 1, Precedence: 50, Handler: splitStringOperator}
 
+var errorOpType = &operationType{Type: "ERROR", NumArgs: 1, Precedence: 50, Handler: errorOperator}
+
 var loadOpType = &operationType{Type: "LOAD", NumArgs: 1, Precedence: 50, Handler: loadYamlOperator}
 
 var keysOpType = &operationType{Type: "KEYS", NumArgs: 0, Precedence: 50, Handler: keysOperator}
@@ -133,6 +220,7 @@ var keysOpType = &operationType{Type: "KEYS", NumArgs: 0, Precedence: 50, Handle
 var collectObjectOpType = &operationType{Type: "COLLECT_OBJECT", NumArgs: 0, Precedence: 50, Handler: collectObjectOperator}
 var traversePathOpType = &operationType{Type: "TRAVERSE_PATH", NumArgs: 0, Precedence: 55, Handler: traversePathOperator}
 var traverseArrayOpType = &operationType{Type: "TRAVERSE_ARRAY", NumArgs: 2, Precedence: 50, Handler: traverseArrayOperator}
+var sliceOpType = &operationType{Type: "SLICE", NumArgs: 2, Precedence: 50, Handler: sliceOperator}
 
 var selfReferenceOpType = &operationType{Type: "SELF", NumArgs: 0, Precedence: 55, Handler: selfOperator}
 var valueOpType = &operationType{Type: "VALUE", NumArgs: 0, Precedence: 50, Handler: valueOperator}
@@ -149,7 +237,35 @@ var hasOpType = &operationType{Type: "HAS", NumArgs: 1, Precedence: 50, Handler:
 var uniqueOpType = &operationType{Type: "UNIQUE", NumArgs: 0, Precedence: 50, Handler: unique}
 var uniqueByOpType = &operationType{Type: "UNIQUE_BY", NumArgs: 1, Precedence: 50, Handler: uniqueBy}
 var groupByOpType = &operationType{Type: "GROUP_BY", NumArgs: 1, Precedence: 50, Handler: groupBy}
+var nowOpType = &operationType{Type: "NOW", NumArgs: 0, Precedence: 50, Handler: nowOperator}
+var fromUnixOpType = &operationType{Type: "FROM_UNIX", NumArgs: 0, Precedence: 50, Handler: fromUnixOperator}
+var toUnixOpType = &operationType{Type: "TO_UNIX", NumArgs: 0, Precedence: 50, Handler: toUnixOperator}
+var strftimeOpType = &operationType{Type: "STRFTIME", NumArgs: 1, Precedence: 50, Handler: strftimeOperator}
+var strptimeOpType = &operationType{Type: "STRPTIME", NumArgs: 1, Precedence: 50, Handler: strptimeOperator}
+var formatDateTimeOpType = &operationType{Type: "FORMAT_DATE_TIME", NumArgs: 1, Precedence: 50, Handler: formatDateTimeOperator}
+var tzOpType = &operationType{Type: "TZ", NumArgs: 1, Precedence: 50, Handler: tzOperator}
 var flattenOpType = &operationType{Type: "FLATTEN_BY", NumArgs: 0, Precedence: 50, Handler: flattenOp}
+var flattenWithDepthOpType = &operationType{Type: "FLATTEN_WITH_DEPTH", NumArgs: 1, Precedence: 50, Handler: flattenWithDepthOp}
+var insideOpType = &operationType{Type: "INSIDE", NumArgs: 1, Precedence: 50, Handler: insideOperator}
+var intersectionOpType = &operationType{Type: "INTERSECTION", NumArgs: 1, Precedence: 50, Handler: intersectionOperator}
+var differenceOpType = &operationType{Type: "DIFFERENCE", NumArgs: 1, Precedence: 50, Handler: differenceOperator}
+var transposeOpType = &operationType{Type: "TRANSPOSE", NumArgs: 0, Precedence: 50, Handler: transposeOperator}
+var zipOpType = &operationType{Type: "ZIP", NumArgs: 0, Precedence: 50, Handler: zipOperator}
+var combinationsOpType = &operationType{Type: "COMBINATIONS", NumArgs: 0, Precedence: 50, Handler: combinationsOperator}
+var combinationsWithOpType = &operationType{Type: "COMBINATIONS_WITH", NumArgs: 1, Precedence: 50, Handler: combinationsWithOperator}
+var chunkOpType = &operationType{Type: "CHUNK", NumArgs: 1, Precedence: 50, Handler: chunkOperator}
+var nwiseOpType = &operationType{Type: "NWISE", NumArgs: 1, Precedence: 50, Handler: nwiseOperator}
+var reverseOpType = &operationType{Type: "REVERSE", NumArgs: 0, Precedence: 50, Handler: reverseOperator}
+var jsonPatchOpType = &operationType{Type: "JSON_PATCH", NumArgs: 1, Precedence: 50, Handler: jsonPatchOperator}
+var jsonDiffOpType = &operationType{Type: "JSON_DIFF", NumArgs: 1, Precedence: 50, Handler: jsonDiffOperator}
+var pointerOpType = &operationType{Type: "POINTER", NumArgs: 1, Precedence: 50, Handler: pointerOperator}
+var jsonPathOpType = &operationType{Type: "JSONPATH", NumArgs: 1, Precedence: 50, Handler: jsonPathOperator}
+var toPointerOpType = &operationType{Type: "TO_POINTER", NumArgs: 0, Precedence: 50, Handler: toPointerOperator}
+var validateOpType = &operationType{Type: "VALIDATE", NumArgs: 1, Precedence: 50, Handler: validateOperator}
+var inferSchemaOpType = &operationType{Type: "INFER_SCHEMA", NumArgs: 0, Precedence: 50, Handler: inferSchemaOperator}
+var lineOpType = &operationType{Type: "LINE", NumArgs: 0, Precedence: 50, Handler: lineOperator}
+var columnOpType = &operationType{Type: "COLUMN", NumArgs: 0, Precedence: 50, Handler: columnOperator}
+var keyLineOpType = &operationType{Type: "KEY_LINE", NumArgs: 0, Precedence: 50, Handler: keyLineOperator}
 var deleteChildOpType = &operationType{Type: "DELETE", NumArgs: 1, Precedence: 40, Handler: deleteChildOperator}
 
 type Operation struct {
@@ -253,6 +369,23 @@ func parseInt(numberString string) (string, int64, error) {
 	return "%v", num, err
 }
 
+// formatFloat always includes a decimal point, so the value stays a float when parsed again.
+// Infinity and not a number use their yaml spellings.
+func formatFloat(number float64) string {
+	if math.IsInf(number, 1) {
+		return ".inf"
+	} else if math.IsInf(number, -1) {
+		return "-.inf"
+	} else if math.IsNaN(number) {
+		return ".nan"
+	}
+	formatted := strconv.FormatFloat(number, 'f', -1, 64)
+	if !strings.Contains(formatted, ".") {
+		formatted = formatted + ".0"
+	}
+	return formatted
+}
+
 func createScalarNode(value interface{}, stringValue string) *yaml.Node {
 	var node = &yaml.Node{Kind: yaml.ScalarNode}
 	node.Value = stringValue
@@ -0,0 +1,95 @@
+package yqlib
+
+import (
+	"container/list"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/jinzhu/copier"
+	yaml "gopkg.in/yaml.v3"
+)
+
+var variableNameRegex = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z_0-9]*$`)
+
+// NamedArguments are the variables given on the command line (e.g. --arg name value). They are set
+// on the context of every expression, both individually and all together in the $__named map.
+var NamedArguments = &namedArguments{values: make(map[string]*yaml.Node)}
+
+type namedArguments struct {
+	names  []string
+	values map[string]*yaml.Node
+}
+
+func (a *namedArguments) set(name string, node *yaml.Node) error {
+	if !variableNameRegex.MatchString(name) {
+		return fmt.Errorf("'%v' is not a valid variable name", name)
+	}
+	if _, exists := a.values[name]; !exists {
+		a.names = append(a.names, name)
+	}
+	a.values[name] = node
+	return nil
+}
+
+// AddString sets the variable to the given string.
+func (a *namedArguments) AddString(name string, value string) error {
+	return a.set(name, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value})
+}
+
+// AddDocument parses the content as a single yaml (or json) document and sets the variable to it.
+func (a *namedArguments) AddDocument(name string, content string) error {
+	decoder := yaml.NewDecoder(strings.NewReader(content))
+	var dataBucket yaml.Node
+	err := decoder.Decode(&dataBucket)
+	if errors.Is(err, io.EOF) {
+		return a.set(name, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"})
+	} else if err != nil {
+		return fmt.Errorf("cannot parse value of $%v: %w", name, err)
+	}
+	return a.set(name, unwrapDoc(&dataBucket))
+}
+
+// AddDocuments sets the variable to an array of every document read with the decoder.
+func (a *namedArguments) AddDocuments(name string, reader io.Reader, decoder Decoder) error {
+	documents, err := readDocuments(reader, "$"+name, 0, decoder)
+	if err != nil {
+		return err
+	}
+	seq := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+	for el := documents.Front(); el != nil; el = el.Next() {
+		seq.Content = append(seq.Content, unwrapDoc(el.Value.(*CandidateNode).Node))
+	}
+	return a.set(name, seq)
+}
+
+// Clear removes all the named arguments.
+func (a *namedArguments) Clear() {
+	a.names = nil
+	a.values = make(map[string]*yaml.Node)
+}
+
+// createContext creates the context expressions are first evaluated in, with the named arguments set.
+func (a *namedArguments) createContext(inputs *list.List) (Context, error) {
+	context := Context{MatchingNodes: inputs}
+	if len(a.names) == 0 {
+		return context, nil
+	}
+	named := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	for _, name := range a.names {
+		named.Content = append(named.Content, createScalarNode(name, name), a.values[name])
+	}
+	// expressions can update variables, so each evaluation gets its own copy
+	copied := &yaml.Node{}
+	if err := copier.CopyWithOption(copied, named, copier.Option{DeepCopy: true}); err != nil {
+		return Context{}, err
+	}
+
+	for i := 0; i < len(copied.Content); i = i + 2 {
+		context.SetVariable(copied.Content[i].Value, (&CandidateNode{Node: copied.Content[i+1]}).AsList())
+	}
+	context.SetVariable("__named", (&CandidateNode{Node: copied}).AsList())
+	return context, nil
+}
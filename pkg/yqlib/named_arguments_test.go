@@ -0,0 +1,52 @@
+package yqlib
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mikefarah/yq/v4/test"
+)
+
+func TestNamedArguments(t *testing.T) {
+	InitExpressionParser()
+	defer NamedArguments.Clear()
+
+	if err := NamedArguments.AddString("name", "cat"); err != nil {
+		t.Fatal(err)
+	}
+	if err := NamedArguments.AddDocument("config", `{"a": [1, 2]}`); err != nil {
+		t.Fatal(err)
+	}
+	if err := NamedArguments.AddDocuments("docs", strings.NewReader("a: 1\n---\nb: 2\n"), NewYamlDecoder()); err != nil {
+		t.Fatal(err)
+	}
+
+	var evaluator = NewAllAtOnceEvaluator()
+	node := test.ParseData(`x: 1`)
+	results, err := evaluator.EvaluateNodes(`[$name, $config.a[1], ($docs | length), ($__named | keys)]`, &node)
+	if err != nil {
+		t.Fatal(err)
+	}
+	test.AssertResultComplex(t, []string{
+		"D0, P[], (!!seq)::- cat\n- 2\n- 2\n- - name\n  - config\n  - docs\n",
+	}, resultsToString(t, results))
+
+	// updates to a variable are not seen by the next evaluation
+	_, err = evaluator.EvaluateNodes(`$config.a[0] = 5`, &node)
+	if err != nil {
+		t.Fatal(err)
+	}
+	results, err = evaluator.EvaluateNodes(`$config.a[0]`, &node)
+	if err != nil {
+		t.Fatal(err)
+	}
+	test.AssertResultComplex(t, []string{"D0, P[a 0], (!!int)::1\n"}, resultsToString(t, results))
+}
+
+func TestNamedArgumentsBadName(t *testing.T) {
+	defer NamedArguments.Clear()
+	err := NamedArguments.AddString("1x", "cat")
+	if err == nil || err.Error() != "'1x' is not a valid variable name" {
+		t.Errorf("expected an invalid name error, got %v", err)
+	}
+}
@@ -0,0 +1,411 @@
+package yqlib
+
+import (
+	"container/list"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+// canonicalNodeKey returns a string that is identical for any two nodes recursiveNodeEqual
+// considers equal. This lets array operators compare elements by hashing rather than
+// checking every element against every other.
+func canonicalNodeKey(node *yaml.Node) string {
+	var builder strings.Builder
+	writeCanonicalNode(&builder, node)
+	return builder.String()
+}
+
+func writeCanonicalNode(builder *strings.Builder, node *yaml.Node) {
+	node = unwrapDoc(node)
+	for node.Kind == yaml.AliasNode && node.Alias != nil {
+		node = node.Alias
+	}
+	switch node.Kind {
+	case yaml.SequenceNode:
+		builder.WriteString("[")
+		for i, child := range node.Content {
+			if i > 0 {
+				builder.WriteString(",")
+			}
+			writeCanonicalNode(builder, child)
+		}
+		builder.WriteString("]")
+	case yaml.MappingNode:
+		entries := make([]string, 0, len(node.Content)/2)
+		for i := 0; i+1 < len(node.Content); i = i + 2 {
+			entries = append(entries, canonicalNodeKey(node.Content[i])+":"+canonicalNodeKey(node.Content[i+1]))
+		}
+		sort.Strings(entries)
+		builder.WriteString("{")
+		builder.WriteString(strings.Join(entries, ","))
+		builder.WriteString("}")
+	default:
+		tag := resolveTag(node)
+		if tag == "!!null" {
+			builder.WriteString("null")
+			return
+		}
+		builder.WriteString(tag)
+		builder.WriteString(strconv.Quote(node.Value))
+	}
+}
+
+func canonicalNodeKeys(nodes []*yaml.Node) []string {
+	keys := make([]string, len(nodes))
+	for i, node := range nodes {
+		keys[i] = canonicalNodeKey(node)
+	}
+	return keys
+}
+
+func canonicalNodeKeySet(nodes []*yaml.Node) map[string]bool {
+	keys := make(map[string]bool, len(nodes))
+	for _, node := range nodes {
+		keys[canonicalNodeKey(node)] = true
+	}
+	return keys
+}
+
+// arrayIndices finds where the search appears in the array. If search is itself an array, it
+// is treated as a sub-sequence to look for, otherwise as a single element.
+func arrayIndices(array *yaml.Node, search *yaml.Node) []int {
+	keys := canonicalNodeKeys(array.Content)
+	var searchKeys []string
+	if search.Kind == yaml.SequenceNode {
+		searchKeys = canonicalNodeKeys(search.Content)
+	} else {
+		searchKeys = []string{canonicalNodeKey(search)}
+	}
+
+	indices := make([]int, 0)
+	if len(searchKeys) == 0 {
+		return indices
+	}
+	for i := 0; i+len(searchKeys) <= len(keys); i++ {
+		matches := true
+		for j, searchKey := range searchKeys {
+			if keys[i+j] != searchKey {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			indices = append(indices, i)
+		}
+	}
+	return indices
+}
+
+func getSequenceNode(candidate *CandidateNode, operatorName string) (*yaml.Node, error) {
+	node := unwrapDoc(candidate.Node)
+	if node.Kind != yaml.SequenceNode {
+		return nil, fmt.Errorf("%v can only be used on arrays, got %v", operatorName, node.Tag)
+	}
+	return node, nil
+}
+
+func getPositiveIntegerParameter(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode, operatorName string) (int, error) {
+	size, err := getIntegerParameter(d, context, expressionNode, operatorName)
+	if err != nil {
+		return 0, err
+	}
+	if size <= 0 {
+		return 0, fmt.Errorf("%v expects a positive number, got %v", operatorName, size)
+	}
+	return size, nil
+}
+
+func insideOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	log.Debugf("-- insideOperator")
+	return crossFunction(d, context.ReadOnlyClone(), expressionNode, insideWithNodes, false)
+}
+
+func insideWithNodes(d *dataTreeNavigator, context Context, lhs *CandidateNode, rhs *CandidateNode) (*CandidateNode, error) {
+	lhs.Node = unwrapDoc(lhs.Node)
+	rhs.Node = unwrapDoc(rhs.Node)
+
+	if lhs.Node.Kind != rhs.Node.Kind {
+		return nil, fmt.Errorf("%v cannot check inside %v", lhs.Node.Tag, rhs.Node.Tag)
+	}
+	result, err := contains(rhs.Node, lhs.Node)
+	if err != nil {
+		return nil, err
+	}
+	return createBooleanCandidate(lhs, result), nil
+}
+
+func intersectionOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	log.Debugf("-- intersectionOperator")
+	return crossFunction(d, context.ReadOnlyClone(), expressionNode, func(d *dataTreeNavigator, context Context, lhs *CandidateNode, rhs *CandidateNode) (*CandidateNode, error) {
+		return filterArray(lhs, rhs, "intersection", true)
+	}, false)
+}
+
+func differenceOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	log.Debugf("-- differenceOperator")
+	return crossFunction(d, context.ReadOnlyClone(), expressionNode, func(d *dataTreeNavigator, context Context, lhs *CandidateNode, rhs *CandidateNode) (*CandidateNode, error) {
+		return filterArray(lhs, rhs, "difference", false)
+	}, false)
+}
+
+// filterArray returns the distinct elements of lhs that are (or are not) in rhs, in the order
+// they first appear in lhs.
+func filterArray(lhs *CandidateNode, rhs *CandidateNode, operatorName string, keepMatches bool) (*CandidateNode, error) {
+	lhsNode, err := getSequenceNode(lhs, operatorName)
+	if err != nil {
+		return nil, err
+	}
+	rhsNode := unwrapDoc(rhs.Node)
+	if rhsNode.Kind != yaml.SequenceNode {
+		return nil, fmt.Errorf("%v expects an array parameter, got %v instead", operatorName, rhsNode.Tag)
+	}
+
+	rhsKeys := canonicalNodeKeySet(rhsNode.Content)
+	seen := make(map[string]bool, len(lhsNode.Content))
+	result := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+	for _, child := range lhsNode.Content {
+		key := canonicalNodeKey(child)
+		if seen[key] || rhsKeys[key] != keepMatches {
+			continue
+		}
+		seen[key] = true
+		result.Content = append(result.Content, child)
+	}
+	return lhs.CreateReplacement(result), nil
+}
+
+func transposeOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	log.Debugf("-- transposeOperator")
+	return zipArrays(context, "transpose", true)
+}
+
+func zipOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	log.Debugf("-- zipOperator")
+	return zipArrays(context, "zip", false)
+}
+
+// zipArrays groups the nth elements of each inner array together. When padding, the result is as
+// long as the longest inner array with gaps filled by null, otherwise it is cut to the shortest.
+func zipArrays(context Context, operatorName string, pad bool) (Context, error) {
+	results := list.New()
+
+	for el := context.MatchingNodes.Front(); el != nil; el = el.Next() {
+		candidate := el.Value.(*CandidateNode)
+		node, err := getSequenceNode(candidate, operatorName)
+		if err != nil {
+			return Context{}, err
+		}
+
+		rows := make([]*yaml.Node, len(node.Content))
+		length := 0
+		for i, child := range node.Content {
+			row := unwrapDoc(child)
+			if row.Kind != yaml.SequenceNode {
+				return Context{}, fmt.Errorf("%v can only be used on arrays of arrays, got %v", operatorName, row.Tag)
+			}
+			rows[i] = row
+			if i == 0 || (pad && len(row.Content) > length) || (!pad && len(row.Content) < length) {
+				length = len(row.Content)
+			}
+		}
+
+		result := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		for i := 0; i < length; i++ {
+			column := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+			for _, row := range rows {
+				if i < len(row.Content) {
+					column.Content = append(column.Content, row.Content[i])
+				} else {
+					column.Content = append(column.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"})
+				}
+			}
+			result.Content = append(result.Content, column)
+		}
+		results.PushBack(candidate.CreateReplacement(result))
+	}
+	return context.ChildContext(results), nil
+}
+
+func combinationsOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	log.Debugf("-- combinationsOperator")
+	results := list.New()
+
+	for el := context.MatchingNodes.Front(); el != nil; el = el.Next() {
+		candidate := el.Value.(*CandidateNode)
+		node, err := getSequenceNode(candidate, "combinations")
+		if err != nil {
+			return Context{}, err
+		}
+		sets := make([][]*yaml.Node, len(node.Content))
+		for i, child := range node.Content {
+			set := unwrapDoc(child)
+			if set.Kind != yaml.SequenceNode {
+				return Context{}, fmt.Errorf("combinations can only be used on arrays of arrays, got %v", set.Tag)
+			}
+			sets[i] = set.Content
+		}
+		addCombinations(candidate, sets, results)
+	}
+	return context.ChildContext(results), nil
+}
+
+func combinationsWithOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	log.Debugf("-- combinationsWithOperator")
+	size, err := getIntegerParameter(d, context, expressionNode.RHS, "combinations")
+	if err != nil {
+		return Context{}, err
+	}
+	if size < 0 {
+		return Context{}, fmt.Errorf("combinations expects a non-negative number, got %v", size)
+	}
+	results := list.New()
+
+	for el := context.MatchingNodes.Front(); el != nil; el = el.Next() {
+		candidate := el.Value.(*CandidateNode)
+		node, err := getSequenceNode(candidate, "combinations")
+		if err != nil {
+			return Context{}, err
+		}
+		sets := make([][]*yaml.Node, size)
+		for i := range sets {
+			sets[i] = node.Content
+		}
+		addCombinations(candidate, sets, results)
+	}
+	return context.ChildContext(results), nil
+}
+
+// addCombinations pushes each way of picking one element from every set, varying the last set fastest.
+func addCombinations(candidate *CandidateNode, sets [][]*yaml.Node, results *list.List) {
+	for _, set := range sets {
+		if len(set) == 0 {
+			return
+		}
+	}
+	picks := make([]int, len(sets))
+	for {
+		combination := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		for i, set := range sets {
+			combination.Content = append(combination.Content, set[picks[i]])
+		}
+		results.PushBack(candidate.CreateReplacement(combination))
+
+		i := len(sets) - 1
+		for ; i >= 0; i-- {
+			picks[i]++
+			if picks[i] < len(sets[i]) {
+				break
+			}
+			picks[i] = 0
+		}
+		if i < 0 {
+			return
+		}
+	}
+}
+
+func chunkOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	log.Debugf("-- chunkOperator")
+	size, err := getPositiveIntegerParameter(d, context, expressionNode.RHS, "chunk")
+	if err != nil {
+		return Context{}, err
+	}
+	results := list.New()
+
+	for el := context.MatchingNodes.Front(); el != nil; el = el.Next() {
+		candidate := el.Value.(*CandidateNode)
+		chunks, err := getChunks(candidate, size, "chunk")
+		if err != nil {
+			return Context{}, err
+		}
+		results.PushBack(candidate.CreateReplacement(&yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Content: chunks}))
+	}
+	return context.ChildContext(results), nil
+}
+
+func nwiseOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	log.Debugf("-- nwiseOperator")
+	size, err := getPositiveIntegerParameter(d, context, expressionNode.RHS, "_nwise")
+	if err != nil {
+		return Context{}, err
+	}
+	results := list.New()
+
+	for el := context.MatchingNodes.Front(); el != nil; el = el.Next() {
+		candidate := el.Value.(*CandidateNode)
+		chunks, err := getChunks(candidate, size, "_nwise")
+		if err != nil {
+			return Context{}, err
+		}
+		for _, chunk := range chunks {
+			results.PushBack(candidate.CreateReplacement(chunk))
+		}
+	}
+	return context.ChildContext(results), nil
+}
+
+// getChunks splits an array into arrays of the given size, or a string into strings of that many characters.
+// The last chunk holds whatever is left over.
+func getChunks(candidate *CandidateNode, size int, operatorName string) ([]*yaml.Node, error) {
+	node := unwrapDoc(candidate.Node)
+	chunks := make([]*yaml.Node, 0)
+
+	if isStringNode(node) {
+		runes := []rune(node.Value)
+		for i := 0; i < len(runes); i = i + size {
+			end := i + size
+			if end > len(runes) {
+				end = len(runes)
+			}
+			chunks = append(chunks, createScalarNode(string(runes[i:end]), string(runes[i:end])))
+		}
+		return chunks, nil
+	} else if node.Kind != yaml.SequenceNode {
+		return nil, fmt.Errorf("%v can only be used on arrays and strings, got %v", operatorName, node.Tag)
+	}
+
+	for i := 0; i < len(node.Content); i = i + size {
+		end := i + size
+		if end > len(node.Content) {
+			end = len(node.Content)
+		}
+		chunk := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		chunk.Content = append(chunk.Content, node.Content[i:end]...)
+		chunks = append(chunks, chunk)
+	}
+	return chunks, nil
+}
+
+func reverseOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	log.Debugf("-- reverseOperator")
+	results := list.New()
+
+	for el := context.MatchingNodes.Front(); el != nil; el = el.Next() {
+		candidate := el.Value.(*CandidateNode)
+		node := unwrapDoc(candidate.Node)
+
+		switch {
+		case node.Tag == "!!null":
+			results.PushBack(candidate.CreateReplacement(&yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}))
+		case isStringNode(node):
+			runes := []rune(node.Value)
+			for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+				runes[i], runes[j] = runes[j], runes[i]
+			}
+			results.PushBack(candidate.CreateReplacement(createScalarNode(string(runes), string(runes))))
+		case node.Kind == yaml.SequenceNode:
+			reversed := &yaml.Node{Kind: yaml.SequenceNode, Tag: node.Tag, Style: node.Style}
+			for i := len(node.Content) - 1; i >= 0; i-- {
+				reversed.Content = append(reversed.Content, node.Content[i])
+			}
+			results.PushBack(candidate.CreateReplacement(reversed))
+		default:
+			return Context{}, fmt.Errorf("reverse can only be used on arrays and strings, got %v", node.Tag)
+		}
+	}
+	return context.ChildContext(results), nil
+}
@@ -0,0 +1,193 @@
+package yqlib
+
+import (
+	"testing"
+)
+
+var arrayOperatorScenarios = []expressionScenario{
+	{
+		description:    "Find elements in an array",
+		subdescription: "`indices`, `index` and `rindex` work on arrays as well as strings. Searching with an array finds where that sequence of elements starts.",
+		document:       `[a, b, a, b, c]`,
+		expression:     `[indices("b"), indices(["a", "b"]), index(["b", "c"]), rindex("a")]`,
+		expected: []string{
+			"D0, P[], (!!seq)::- - 1\n  - 3\n- - 0\n  - 2\n- 3\n- 2\n",
+		},
+	},
+	{
+		description: "Find maps in an array",
+		document:    `[{a: 1}, {b: 2}, {a: 1}]`,
+		expression:  `indices({a: 1})`,
+		skipDoc:     true,
+		expected: []string{
+			"D0, P[], (!!seq)::- 0\n- 2\n",
+		},
+	},
+	{
+		description:    "Inside",
+		subdescription: "The reverse of `contains` - returns true if the input is completely contained in the parameter.",
+		document:       `{a: [foo, bar], b: [foo, bar, baz]}`,
+		expression:     `(.a | inside(["foobar", "baz"])), (.a | inside(["foo", "bar", "baz"]))`,
+		expected: []string{
+			"D0, P[a], (!!bool)::true\n",
+			"D0, P[a], (!!bool)::true\n",
+		},
+	},
+	{
+		description: "Inside map",
+		document:    `{a: {b: cat}}`,
+		expression:  `(.a | inside({"b": "cat", "c": "dog"})), (.a | inside({"c": "dog"}))`,
+		expected: []string{
+			"D0, P[a], (!!bool)::true\n",
+			"D0, P[a], (!!bool)::false\n",
+		},
+	},
+	{
+		description:   "Inside different types",
+		document:      `a: cat`,
+		expression:    `.a | inside(["cat"])`,
+		skipDoc:       true,
+		expectedError: "!!str cannot check inside !!seq",
+	},
+	{
+		description:    "Intersection",
+		subdescription: "Returns the distinct elements that are in both arrays, in the order they first appear in the input.",
+		document:       `{a: [3, 1, 2, 1, {b: c}], b: [{b: c}, 1, 3]}`,
+		expression:     `.b as $b | .a | intersection($b)`,
+		expected: []string{
+			"D0, P[a], (!!seq)::- 3\n- 1\n- {b: c}\n",
+		},
+	},
+	{
+		description:    "Difference",
+		subdescription: "Returns the distinct elements of the input that are not in the given array. Use `-` to keep duplicates.",
+		document:       `[a, b, a, c, d]`,
+		expression:     `difference(["c", "d"])`,
+		expected: []string{
+			"D0, P[], (!!seq)::- a\n- b\n",
+		},
+	},
+	{
+		description:   "Difference with a non array",
+		document:      `[a]`,
+		expression:    `difference("a")`,
+		skipDoc:       true,
+		expectedError: "difference expects an array parameter, got !!str instead",
+	},
+	{
+		description:    "Transpose",
+		subdescription: "Shorter arrays are padded with null.",
+		document:       `[[1, 2, 3], [a, b]]`,
+		expression:     `transpose`,
+		expected: []string{
+			"D0, P[], (!!seq)::- - 1\n  - a\n- - 2\n  - b\n- - 3\n  - null\n",
+		},
+	},
+	{
+		description:    "Zip",
+		subdescription: "Like `transpose`, but the result is cut to the length of the shortest array.",
+		document:       `{names: [cat, dog, mouse], sounds: [meow, woof]}`,
+		expression:     `[.names, .sounds] | zip`,
+		expected: []string{
+			"D0, P[], (!!seq)::- - cat\n  - meow\n- - dog\n  - woof\n",
+		},
+	},
+	{
+		description:   "Zip requires arrays of arrays",
+		document:      `[[a], b]`,
+		expression:    `zip`,
+		skipDoc:       true,
+		expectedError: "zip can only be used on arrays of arrays, got !!str",
+	},
+	{
+		description:    "Combinations",
+		subdescription: "Outputs every way of picking one element from each of the arrays.",
+		document:       `[[a, b], [1, 2]]`,
+		expression:     `combinations`,
+		expected: []string{
+			"D0, P[], (!!seq)::- a\n- 1\n",
+			"D0, P[], (!!seq)::- a\n- 2\n",
+			"D0, P[], (!!seq)::- b\n- 1\n",
+			"D0, P[], (!!seq)::- b\n- 2\n",
+		},
+	},
+	{
+		description:    "Combinations of an array with itself",
+		subdescription: "`combinations(n)` picks n elements from the same array.",
+		document:       `[0, 1]`,
+		expression:     `[combinations(2)]`,
+		expected: []string{
+			"D0, P[], (!!seq)::- - 0\n  - 0\n- - 0\n  - 1\n- - 1\n  - 0\n- - 1\n  - 1\n",
+		},
+	},
+	{
+		description: "Combinations with an empty array",
+		document:    `[[a], []]`,
+		expression:  `[combinations]`,
+		skipDoc:     true,
+		expected: []string{
+			"D0, P[], (!!seq)::[]\n",
+		},
+	},
+	{
+		description:    "Chunk",
+		subdescription: "Splits an array (or string) into pieces of the given size. The last piece holds whatever is left.",
+		document:       `{a: [1, 2, 3, 4, 5], b: abcde}`,
+		expression:     `(.a | chunk(2)), (.b | chunk(2))`,
+		expected: []string{
+			"D0, P[a], (!!seq)::- - 1\n  - 2\n- - 3\n  - 4\n- - 5\n",
+			"D0, P[b], (!!seq)::- ab\n- cd\n- e\n",
+		},
+	},
+	{
+		description:    "_nwise",
+		subdescription: "Like `chunk`, but outputs each piece separately.",
+		document:       `[1, 2, 3]`,
+		expression:     `_nwise(2)`,
+		expected: []string{
+			"D0, P[], (!!seq)::- 1\n- 2\n",
+			"D0, P[], (!!seq)::- 3\n",
+		},
+	},
+	{
+		description:   "Chunk size must be positive",
+		document:      `[1, 2, 3]`,
+		expression:    `chunk(0)`,
+		skipDoc:       true,
+		expectedError: "chunk expects a positive number, got 0",
+	},
+	{
+		description: "Reverse",
+		document:    `{a: [1, 2, 3], b: "héllo"}`,
+		expression:  `(.a | reverse), (.b | reverse)`,
+		expected: []string{
+			"D0, P[a], (!!seq)::[3, 2, 1]\n",
+			"D0, P[b], (!!str)::olléh\n",
+		},
+	},
+	{
+		description: "Reverse null",
+		document:    `{}`,
+		expression:  `.a | reverse`,
+		skipDoc:     true,
+		expected: []string{
+			"D0, P[a], (!!seq)::[]\n",
+		},
+	},
+	{
+		description: "Subtract large arrays",
+		document:    `{}`,
+		expression:  `[range(20000)] - [range(1; 20000)] | length`,
+		skipDoc:     true,
+		expected: []string{
+			"D0, P[], (!!int)::1\n",
+		},
+	},
+}
+
+func TestArrayOperatorScenarios(t *testing.T) {
+	for _, tt := range arrayOperatorScenarios {
+		testScenario(t, &tt)
+	}
+	documentOperatorScenarios(t, "array-operators", arrayOperatorScenarios)
+}
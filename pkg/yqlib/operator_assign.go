@@ -15,17 +15,35 @@ func assignUpdateFunc(prefs assignPreferences) crossFunctionCalculation {
 	}
 }
 
-func assignUpdateOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
-	lhs, err := d.GetMatchingNodes(context, expressionNode.LHS)
-	if err != nil {
-		return Context{}, err
-	}
+func assignSlice(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode, prefs assignPreferences) (Context, error) {
+	err := updateSlices(d, context, expressionNode.LHS, func(slice *CandidateNode) (*CandidateNode, error) {
+		if prefs.OnlyWriteNull {
+			// a slice is always an array, never null
+			return nil, nil
+		}
+		if expressionNode.Operation.UpdateAssign {
+			return firstResult(d, context.SingleChildContext(slice), expressionNode.RHS)
+		}
+		return firstResult(d, context.ReadOnlyClone(), expressionNode.RHS)
+	})
+	return context, err
+}
 
+func assignUpdateOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
 	prefs := assignPreferences{}
 	if expressionNode.Operation.Preferences != nil {
 		prefs = expressionNode.Operation.Preferences.(assignPreferences)
 	}
 
+	if isSliceExpression(expressionNode.LHS) {
+		return assignSlice(d, context, expressionNode, prefs)
+	}
+
+	lhs, err := d.GetMatchingNodes(context, expressionNode.LHS)
+	if err != nil {
+		return Context{}, err
+	}
+
 	if !expressionNode.Operation.UpdateAssign {
 		// this works because we already ran against LHS with an editable context.
 		_, err := crossFunction(d, context.ReadOnlyClone(), expressionNode, assignUpdateFunc(prefs), false)
@@ -131,6 +131,15 @@ var collectObjectOperatorScenarios = []expressionScenario{
 			"D0, P[], (!!map)::wrap: frog\n",
 		},
 	},
+	{
+		description:    "Bare word keys",
+		subdescription: "Keys that are plain words don't need quotes, even when they are the name of an operator. Wrap the key in brackets to evaluate it instead.",
+		document:       `[a, b]`,
+		expression:     `{length: 1, (length): 2}`,
+		expected: []string{
+			"D0, P[], (!!map)::length: 1\n2: 2\n",
+		},
+	},
 	{
 		skipDoc:    true,
 		expression: `{"wrap": "frog", "bing": "bong"}`,
@@ -0,0 +1,93 @@
+package yqlib
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+type compareTypePref struct {
+	OrEqual bool
+	Greater bool
+}
+
+func compareOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	log.Debugf("-- compareOperator")
+	prefs := expressionNode.Operation.Preferences.(compareTypePref)
+	return crossFunction(d, context.ReadOnlyClone(), expressionNode, compare(prefs), false)
+}
+
+func compare(prefs compareTypePref) func(d *dataTreeNavigator, context Context, lhs *CandidateNode, rhs *CandidateNode) (*CandidateNode, error) {
+	return func(d *dataTreeNavigator, context Context, lhs *CandidateNode, rhs *CandidateNode) (*CandidateNode, error) {
+		lhsNode := unwrapDoc(lhs.Node)
+		rhsNode := unwrapDoc(rhs.Node)
+
+		result, err := compareScalars(lhsNode, rhsNode)
+		if err != nil {
+			return nil, err
+		}
+
+		value := result < 0
+		if prefs.Greater {
+			value = result > 0
+		}
+		if prefs.OrEqual {
+			value = value || result == 0
+		}
+		return createBooleanCandidate(lhs, value), nil
+	}
+}
+
+// compareScalars returns a negative number when lhs is less than rhs, zero when they are the same and
+// a positive number otherwise. If either side is a timestamp, both are compared as timestamps.
+func compareScalars(lhs *yaml.Node, rhs *yaml.Node) (int, error) {
+	if lhs.Kind != yaml.ScalarNode || rhs.Kind != yaml.ScalarNode {
+		return 0, fmt.Errorf("%v cannot be compared to %v", lhs.Tag, rhs.Tag)
+	}
+	lhsTag := resolveTag(lhs)
+	rhsTag := resolveTag(rhs)
+
+	if lhsTag == "!!null" && rhsTag == "!!null" {
+		return 0, nil
+	} else if lhsTag == "!!null" {
+		// null is less than everything else
+		return -1, nil
+	} else if rhsTag == "!!null" {
+		return 1, nil
+	} else if lhsTag == "!!timestamp" || rhsTag == "!!timestamp" {
+		lhsTime, err := parseTimestamp(lhs)
+		if err != nil {
+			return 0, err
+		}
+		rhsTime, err := parseTimestamp(rhs)
+		if err != nil {
+			return 0, err
+		}
+		if lhsTime.Before(rhsTime) {
+			return -1, nil
+		} else if lhsTime.After(rhsTime) {
+			return 1, nil
+		}
+		return 0, nil
+	} else if (lhsTag == "!!int" || lhsTag == "!!float") && (rhsTag == "!!int" || rhsTag == "!!float") {
+		lhsNum, err := strconv.ParseFloat(lhs.Value, 64)
+		if err != nil {
+			return 0, err
+		}
+		rhsNum, err := strconv.ParseFloat(rhs.Value, 64)
+		if err != nil {
+			return 0, err
+		}
+		if lhsNum < rhsNum {
+			return -1, nil
+		} else if lhsNum > rhsNum {
+			return 1, nil
+		}
+		return 0, nil
+	} else if lhsTag == "!!str" && rhsTag == "!!str" {
+		return strings.Compare(lhs.Value, rhs.Value), nil
+	}
+	return 0, fmt.Errorf("%v cannot be compared to %v", lhsTag, rhsTag)
+}
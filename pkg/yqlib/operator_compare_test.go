@@ -0,0 +1,96 @@
+package yqlib
+
+import (
+	"testing"
+)
+
+var compareOperatorScenarios = []expressionScenario{
+	{
+		description: "Compare numbers",
+		document:    `{a: 5, b: 4.5}`,
+		expression:  `.a > .b, .a < .b, .a >= 5, .a <= 4`,
+		expected: []string{
+			"D0, P[a], (!!bool)::true\n",
+			"D0, P[a], (!!bool)::false\n",
+			"D0, P[a], (!!bool)::true\n",
+			"D0, P[a], (!!bool)::false\n",
+		},
+	},
+	{
+		description:    "Compare strings",
+		subdescription: "Strings are compared lexically.",
+		document:       `{a: apple, b: banana}`,
+		expression:     `.a < .b`,
+		expected: []string{
+			"D0, P[a], (!!bool)::true\n",
+		},
+	},
+	{
+		description:    "Compare timestamps",
+		subdescription: "If either side is a timestamp, both sides are compared as timestamps.",
+		document:       `{a: 2021-01-01T03:10:00Z, b: 2021-01-01T03:05:00+01:00}`,
+		expression:     `.a > .b, .a > "2022-01-01"`,
+		expected: []string{
+			"D0, P[a], (!!bool)::true\n",
+			"D0, P[a], (!!bool)::false\n",
+		},
+	},
+	{
+		description:    "Compare with null",
+		subdescription: "Null is less than everything else.",
+		document:       `{a: 3}`,
+		expression:     `.a > null, (.a | . < null)`,
+		skipDoc:        true,
+		expected: []string{
+			"D0, P[a], (!!bool)::true\n",
+			"D0, P[a], (!!bool)::false\n",
+		},
+	},
+	{
+		description: "Select by comparison",
+		document:    `[1, 5, 3, 8]`,
+		expression:  `.[] | select(. >= 5)`,
+		expected: []string{
+			"D0, P[1], (!!int)::5\n",
+			"D0, P[3], (!!int)::8\n",
+		},
+	},
+	{
+		description: "Compare without spaces",
+		skipDoc:     true,
+		document:    `[{n: 1}, {n: 3}]`,
+		expression:  `.[] | select(.n<3) | .n`,
+		expected: []string{
+			"D0, P[0 n], (!!int)::1\n",
+		},
+	},
+	{
+		skipDoc:    true,
+		document:   `{n: 2}`,
+		expression: `[.n>1, .n>=3, .n<=2]`,
+		expected: []string{
+			"D0, P[], (!!seq)::- true\n- false\n- true\n",
+		},
+	},
+	{
+		description:   "Compare different types",
+		document:      `{a: 5, b: cat}`,
+		expression:    `.a < .b`,
+		skipDoc:       true,
+		expectedError: "!!int cannot be compared to !!str",
+	},
+	{
+		description:   "Compare maps",
+		document:      `{a: {}, b: 1}`,
+		expression:    `.a < .b`,
+		skipDoc:       true,
+		expectedError: "!!map cannot be compared to !!int",
+	},
+}
+
+func TestCompareOperatorScenarios(t *testing.T) {
+	for _, tt := range compareOperatorScenarios {
+		testScenario(t, &tt)
+	}
+	documentOperatorScenarios(t, "compare", compareOperatorScenarios)
+}
@@ -0,0 +1,277 @@
+package yqlib
+
+import (
+	"container/list"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+// currentTime is used by the now operator, tests replace it to get a fixed time.
+var currentTime = time.Now
+
+// timestampLayouts are the formats yaml accepts for !!timestamp values.
+var timestampLayouts = []string{
+	time.RFC3339Nano,
+	"2006-1-2T15:4:5.999999999Z07:00",
+	"2006-1-2t15:4:5.999999999Z07:00",
+	"2006-1-2 15:4:5.999999999",
+	"2006-1-2",
+}
+
+func parseTimestamp(node *yaml.Node) (time.Time, error) {
+	if node.Kind == yaml.ScalarNode && node.Tag != "!!null" {
+		for _, layout := range timestampLayouts {
+			parsed, err := time.Parse(layout, node.Value)
+			if err == nil {
+				return parsed, nil
+			}
+		}
+	}
+	return time.Time{}, fmt.Errorf("cannot parse '%v' as a timestamp", node.Value)
+}
+
+func createTimestampNode(value time.Time) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!timestamp", Value: value.Format(time.RFC3339Nano)}
+}
+
+// addDuration handles timestamp arithmetic for add and subtract, the rhs must be a duration like "1h30m".
+func addDuration(target *CandidateNode, lhs *yaml.Node, rhs *yaml.Node, sign time.Duration) error {
+	timestamp, err := parseTimestamp(lhs)
+	if err != nil {
+		return err
+	}
+	duration, err := time.ParseDuration(rhs.Value)
+	if err != nil {
+		return fmt.Errorf("cannot parse '%v' as a duration", rhs.Value)
+	}
+	target.Node.Tag = lhs.Tag
+	target.Node.Value = timestamp.Add(sign * duration).Format(time.RFC3339Nano)
+	return nil
+}
+
+func subtractTimestamps(target *CandidateNode, lhs *yaml.Node, rhs *yaml.Node) error {
+	lhsTime, err := parseTimestamp(lhs)
+	if err != nil {
+		return err
+	}
+	rhsTime, err := parseTimestamp(rhs)
+	if err != nil {
+		return err
+	}
+	target.Node.Tag = "!!str"
+	target.Node.Value = lhsTime.Sub(rhsTime).String()
+	return nil
+}
+
+func getTimestamps(context Context, operatorName string, convert func(timestamp time.Time) (*yaml.Node, error)) (Context, error) {
+	results := list.New()
+
+	for el := context.MatchingNodes.Front(); el != nil; el = el.Next() {
+		candidate := el.Value.(*CandidateNode)
+		timestamp, err := parseTimestamp(unwrapDoc(candidate.Node))
+		if err != nil {
+			return Context{}, fmt.Errorf("%v: %w", operatorName, err)
+		}
+		node, err := convert(timestamp)
+		if err != nil {
+			return Context{}, err
+		}
+		results.PushBack(candidate.CreateReplacement(node))
+	}
+	return context.ChildContext(results), nil
+}
+
+func nowOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	log.Debugf("-- nowOperator")
+	return context.SingleChildContext(&CandidateNode{Node: createTimestampNode(currentTime())}), nil
+}
+
+func fromUnixOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	log.Debugf("-- fromUnixOperator")
+	results := list.New()
+
+	for el := context.MatchingNodes.Front(); el != nil; el = el.Next() {
+		candidate := el.Value.(*CandidateNode)
+		node := unwrapDoc(candidate.Node)
+		if node.Tag != "!!int" && node.Tag != "!!float" {
+			return Context{}, fmt.Errorf("from_unix expects a number, got %v", node.Tag)
+		}
+		seconds, err := strconv.ParseFloat(node.Value, 64)
+		if err != nil {
+			return Context{}, err
+		}
+		whole := int64(seconds)
+		timestamp := time.Unix(whole, int64((seconds-float64(whole))*float64(time.Second))).UTC()
+		results.PushBack(candidate.CreateReplacement(createTimestampNode(timestamp)))
+	}
+	return context.ChildContext(results), nil
+}
+
+func toUnixOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	log.Debugf("-- toUnixOperator")
+	return getTimestamps(context, "to_unix", func(timestamp time.Time) (*yaml.Node, error) {
+		if timestamp.Nanosecond() == 0 {
+			return createIntNode(int(timestamp.Unix())), nil
+		}
+		seconds := float64(timestamp.UnixNano()) / float64(time.Second)
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!float", Value: formatFloat(seconds)}, nil
+	})
+}
+
+func formatDateTimeOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	log.Debugf("-- formatDateTimeOperator")
+	layout, err := getStringParameter(d, context, expressionNode.RHS, "format_datetime")
+	if err != nil {
+		return Context{}, err
+	}
+	return getTimestamps(context, "format_datetime", func(timestamp time.Time) (*yaml.Node, error) {
+		formatted := timestamp.Format(layout)
+		return createScalarNode(formatted, formatted), nil
+	})
+}
+
+func strftimeOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	log.Debugf("-- strftimeOperator")
+	format, err := getStringParameter(d, context, expressionNode.RHS, "strftime")
+	if err != nil {
+		return Context{}, err
+	}
+	return getTimestamps(context, "strftime", func(timestamp time.Time) (*yaml.Node, error) {
+		formatted, err := strftime(format, timestamp)
+		if err != nil {
+			return nil, err
+		}
+		return createScalarNode(formatted, formatted), nil
+	})
+}
+
+func strptimeOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	log.Debugf("-- strptimeOperator")
+	format, err := getStringParameter(d, context, expressionNode.RHS, "strptime")
+	if err != nil {
+		return Context{}, err
+	}
+	layout, err := strftimeToLayout(format)
+	if err != nil {
+		return Context{}, err
+	}
+	results := list.New()
+
+	for el := context.MatchingNodes.Front(); el != nil; el = el.Next() {
+		candidate := el.Value.(*CandidateNode)
+		node := unwrapDoc(candidate.Node)
+		if !isStringNode(node) {
+			return Context{}, fmt.Errorf("strptime can only be used on strings, got %v", node.Tag)
+		}
+		timestamp, err := time.Parse(layout, node.Value)
+		if err != nil {
+			return Context{}, fmt.Errorf("cannot parse '%v' with format '%v'", node.Value, format)
+		}
+		results.PushBack(candidate.CreateReplacement(createTimestampNode(timestamp)))
+	}
+	return context.ChildContext(results), nil
+}
+
+func tzOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	log.Debugf("-- tzOperator")
+	name, err := getStringParameter(d, context, expressionNode.RHS, "tz")
+	if err != nil {
+		return Context{}, err
+	}
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		return Context{}, fmt.Errorf("unknown time zone '%v'", name)
+	}
+	return getTimestamps(context, "tz", func(timestamp time.Time) (*yaml.Node, error) {
+		return createTimestampNode(timestamp.In(location)), nil
+	})
+}
+
+// strftimeLayouts maps the strftime directives that have a go layout equivalent, these can be
+// used for both formatting and parsing.
+var strftimeLayouts = map[byte]string{
+	'a': "Mon",
+	'A': "Monday",
+	'b': "Jan",
+	'h': "Jan",
+	'B': "January",
+	'c': "Mon Jan _2 15:04:05 2006",
+	'd': "02",
+	'D': "01/02/06",
+	'e': "_2",
+	'F': "2006-01-02",
+	'H': "15",
+	'I': "03",
+	'm': "01",
+	'M': "04",
+	'p': "PM",
+	'R': "15:04",
+	'S': "05",
+	'T': "15:04:05",
+	'y': "06",
+	'Y': "2006",
+	'z': "-0700",
+	'Z': "MST",
+	'n': "\n",
+	't': "\t",
+	'%': "%",
+}
+
+// strftimeFunctions are the directives that can only be used for formatting.
+var strftimeFunctions = map[byte]func(timestamp time.Time) string{
+	'j': func(timestamp time.Time) string { return fmt.Sprintf("%03d", timestamp.YearDay()) },
+	's': func(timestamp time.Time) string { return strconv.FormatInt(timestamp.Unix(), 10) },
+	'u': func(timestamp time.Time) string {
+		if timestamp.Weekday() == time.Sunday {
+			return "7"
+		}
+		return strconv.Itoa(int(timestamp.Weekday()))
+	},
+	'w': func(timestamp time.Time) string { return strconv.Itoa(int(timestamp.Weekday())) },
+}
+
+func strftime(format string, timestamp time.Time) (string, error) {
+	var builder strings.Builder
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			builder.WriteByte(format[i])
+			continue
+		}
+		if i+1 == len(format) {
+			return "", fmt.Errorf("strftime format '%v' ends with an incomplete directive", format)
+		}
+		i++
+		if layout, ok := strftimeLayouts[format[i]]; ok {
+			builder.WriteString(timestamp.Format(layout))
+		} else if convert, ok := strftimeFunctions[format[i]]; ok {
+			builder.WriteString(convert(timestamp))
+		} else {
+			return "", fmt.Errorf("strftime does not support %%%c", format[i])
+		}
+	}
+	return builder.String(), nil
+}
+
+func strftimeToLayout(format string) (string, error) {
+	var builder strings.Builder
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			builder.WriteByte(format[i])
+			continue
+		}
+		if i+1 == len(format) {
+			return "", fmt.Errorf("strptime format '%v' ends with an incomplete directive", format)
+		}
+		i++
+		layout, ok := strftimeLayouts[format[i]]
+		if !ok {
+			return "", fmt.Errorf("strptime does not support %%%c", format[i])
+		}
+		builder.WriteString(layout)
+	}
+	return builder.String(), nil
+}
@@ -0,0 +1,156 @@
+package yqlib
+
+import (
+	"testing"
+	"time"
+	// make the time zone tests independent of the system zoneinfo
+	_ "time/tzdata"
+)
+
+var dateTimeOperatorScenarios = []expressionScenario{
+	{
+		description:    "Get the current time",
+		subdescription: "Returns the current time as a UTC timestamp (fixed to 2021-05-19T01:02:03Z for these examples).",
+		document:       `a: cat`,
+		expression:     `.updated = now`,
+		expected: []string{
+			"D0, P[], (doc)::a: cat\nupdated: 2021-05-19T01:02:03Z\n",
+		},
+	},
+	{
+		description:    "Add a duration",
+		subdescription: "Durations are go duration strings, like `1h30m`. Subtracting two timestamps gives the duration between them.",
+		document:       `{a: 2021-01-01T00:00:00Z, b: 2021-01-02T01:30:00Z}`,
+		expression:     `.a + "36h", .a - "1m", .b - .a`,
+		expected: []string{
+			"D0, P[a], (!!timestamp)::2021-01-02T12:00:00Z\n",
+			"D0, P[a], (!!timestamp)::2020-12-31T23:59:00Z\n",
+			"D0, P[b], (!!str)::25h30m0s\n",
+		},
+	},
+	{
+		description:    "Select expiring certificates",
+		subdescription: "Find anything that expires within the next 30 days.",
+		document:       `[{name: a, expires: 2021-05-30T00:00:00Z}, {name: b, expires: 2021-08-01T00:00:00Z}]`,
+		expression:     `.[] | select(.expires < now + "720h") | .name`,
+		expected: []string{
+			"D0, P[0 name], (!!str)::a\n",
+		},
+	},
+	{
+		description: "Update with a duration",
+		document:    `a: 2021-01-01T00:00:00Z`,
+		expression:  `.a += "24h"`,
+		expected: []string{
+			"D0, P[], (doc)::a: 2021-01-02T00:00:00Z\n",
+		},
+	},
+	{
+		description: "Subtract a quoted timestamp",
+		document:    `{a: 2021-01-02T00:00:00Z, b: "2021-01-01T00:00:00Z"}`,
+		expression:  `.a - .b`,
+		skipDoc:     true,
+		expected: []string{
+			"D0, P[a], (!!str)::24h0m0s\n",
+		},
+	},
+	{
+		description:   "Add an invalid duration",
+		document:      `a: 2021-01-01T00:00:00Z`,
+		expression:    `.a + "soon"`,
+		skipDoc:       true,
+		expectedError: "cannot parse 'soon' as a duration",
+	},
+	{
+		description:    "Unix time",
+		subdescription: "Convert to and from seconds since the unix epoch.",
+		document:       `{a: 1621386123, b: 2021-05-19T01:02:03.5Z}`,
+		expression:     `(.a | from_unix), (.b | to_unix)`,
+		expected: []string{
+			"D0, P[a], (!!timestamp)::2021-05-19T01:02:03Z\n",
+			"D0, P[b], (!!float)::1621386123.5\n",
+		},
+	},
+	{
+		description: "Unix time as an integer",
+		document:    `a: 2021-05-19T01:02:03Z`,
+		expression:  `.a | to_unix`,
+		skipDoc:     true,
+		expected: []string{
+			"D0, P[a], (!!int)::1621386123\n",
+		},
+	},
+	{
+		description:    "Format with strftime",
+		subdescription: "Supports the common strftime directives.",
+		document:       `a: 2021-05-19T01:02:03Z`,
+		expression:     `.a | strftime("%A, %B %d, %Y at %H:%M (day %j)")`,
+		expected: []string{
+			"D0, P[a], (!!str)::Wednesday, May 19, 2021 at 01:02 (day 139)\n",
+		},
+	},
+	{
+		description: "Parse with strptime",
+		document:    `a: 19/05/2021 01:02`,
+		expression:  `.a | strptime("%d/%m/%Y %H:%M")`,
+		expected: []string{
+			"D0, P[a], (!!timestamp)::2021-05-19T01:02:00Z\n",
+		},
+	},
+	{
+		description:   "Parse with an unsupported directive",
+		document:      `a: "139"`,
+		expression:    `.a | strptime("%j")`,
+		skipDoc:       true,
+		expectedError: "strptime does not support %j",
+	},
+	{
+		description:   "Parse with a mismatching format",
+		document:      `a: cat`,
+		expression:    `.a | strptime("%Y")`,
+		skipDoc:       true,
+		expectedError: "cannot parse 'cat' with format '%Y'",
+	},
+	{
+		description:    "Format with a go layout",
+		subdescription: "See https://pkg.go.dev/time#pkg-constants for the layout format.",
+		document:       `a: 2021-05-19T01:02:03Z`,
+		expression:     `.a | format_datetime("Monday, 02-Jan-06 3:04PM")`,
+		expected: []string{
+			"D0, P[a], (!!str)::Wednesday, 19-May-21 1:02AM\n",
+		},
+	},
+	{
+		description:    "Change time zone",
+		subdescription: "The time zone is an IANA name, like `America/New_York`.",
+		document:       `a: 2021-05-19T01:02:03Z`,
+		expression:     `.a | tz("Australia/Sydney")`,
+		expected: []string{
+			"D0, P[a], (!!timestamp)::2021-05-19T11:02:03+10:00\n",
+		},
+	},
+	{
+		description:   "Unknown time zone",
+		document:      `a: 2021-05-19T01:02:03Z`,
+		expression:    `.a | tz("Nowhere/Special")`,
+		skipDoc:       true,
+		expectedError: "unknown time zone 'Nowhere/Special'",
+	},
+	{
+		description:   "Not a timestamp",
+		document:      `a: cat`,
+		expression:    `.a | to_unix`,
+		skipDoc:       true,
+		expectedError: "to_unix: cannot parse 'cat' as a timestamp",
+	},
+}
+
+func TestDateTimeOperatorScenarios(t *testing.T) {
+	currentTime = func() time.Time { return time.Date(2021, 5, 19, 1, 2, 3, 0, time.UTC) }
+	defer func() { currentTime = time.Now }()
+
+	for _, tt := range dateTimeOperatorScenarios {
+		testScenario(t, &tt)
+	}
+	documentOperatorScenarios(t, "datetime", dateTimeOperatorScenarios)
+}
@@ -23,20 +23,27 @@ func deleteChildOperator(d *dataTreeNavigator, context Context, expressionNode *
 			return context, nil
 		}
 
-		parentNode := candidate.Parent.Node
-		childPath := candidate.Path[len(candidate.Path)-1]
-
-		if parentNode.Kind == yaml.MappingNode {
-			deleteFromMap(candidate.Parent, childPath)
-		} else if parentNode.Kind == yaml.SequenceNode {
-			deleteFromArray(candidate.Parent, childPath)
-		} else {
-			return Context{}, fmt.Errorf("Cannot delete nodes from parent of tag %v", parentNode.Tag)
+		if err := deleteFromParent(candidate); err != nil {
+			return Context{}, err
 		}
 	}
 	return context, nil
 }
 
+func deleteFromParent(candidate *CandidateNode) error {
+	parentNode := candidate.Parent.Node
+	childPath := candidate.Path[len(candidate.Path)-1]
+
+	if parentNode.Kind == yaml.MappingNode {
+		deleteFromMap(candidate.Parent, childPath)
+	} else if parentNode.Kind == yaml.SequenceNode {
+		deleteFromArray(candidate.Parent, childPath)
+	} else {
+		return fmt.Errorf("Cannot delete nodes from parent of tag %v", parentNode.Tag)
+	}
+	return nil
+}
+
 func deleteFromMap(candidate *CandidateNode, childPath interface{}) {
 	log.Debug("deleteFromMap")
 	node := unwrapDoc(candidate.Node)
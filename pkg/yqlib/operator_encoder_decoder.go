@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"bytes"
 	"container/list"
+	"fmt"
 	"regexp"
 	"strings"
 
@@ -127,3 +128,48 @@ func decodeOperator(d *dataTreeNavigator, context Context, expressionNode *Expre
 	}
 	return context.ChildContext(results), nil
 }
+
+func shellQuote(node *yaml.Node) (string, error) {
+	if node.Kind != yaml.ScalarNode {
+		return "", fmt.Errorf("%v cannot be escaped for shell", node.Tag)
+	}
+	if node.Tag == "!!null" {
+		return "null", nil
+	} else if !isStringNode(node) {
+		return node.Value, nil
+	}
+	return "'" + strings.ReplaceAll(node.Value, "'", `'\''`) + "'", nil
+}
+
+/* quotes strings (or arrays of strings) so they can be safely used as shell arguments */
+func encodeShellOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	log.Debugf("-- encodeShellOperator")
+	var results = list.New()
+
+	for el := context.MatchingNodes.Front(); el != nil; el = el.Next() {
+		candidate := el.Value.(*CandidateNode)
+		node := unwrapDoc(candidate.Node)
+
+		var stringValue string
+		if node.Kind == yaml.SequenceNode {
+			quoted := make([]string, len(node.Content))
+			for i, child := range node.Content {
+				value, err := shellQuote(child)
+				if err != nil {
+					return Context{}, err
+				}
+				quoted[i] = value
+			}
+			stringValue = strings.Join(quoted, " ")
+		} else {
+			value, err := shellQuote(node)
+			if err != nil {
+				return Context{}, err
+			}
+			stringValue = value
+		}
+
+		results.PushBack(candidate.CreateReplacement(&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: stringValue}))
+	}
+	return context.ChildContext(results), nil
+}
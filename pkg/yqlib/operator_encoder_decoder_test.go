@@ -200,6 +200,22 @@ var encoderDecoderOperatorScenarios = []expressionScenario{
 			"D0, P[], (doc)::a: \"<foo>bar</foo>\"\nb:\n    foo: bar\n",
 		},
 	},
+	{
+		description:    "Encode strings for shell",
+		subdescription: "Strings are wrapped in single quotes, arrays become space separated arguments.",
+		document:       `{a: "it's", b: [cat, "a dog", 3]}`,
+		expression:     `(.a, .b) | @sh`,
+		expected: []string{
+			"D0, P[a], (!!str)::'it'\\''s'\n",
+			"D0, P[b], (!!str)::'cat' 'a dog' 3\n",
+		},
+	},
+	{
+		skipDoc:       true,
+		document:      `[[a]]`,
+		expression:    `@sh`,
+		expectedError: "!!seq cannot be escaped for shell",
+	},
 }
 
 func TestEncoderDecoderOperatorScenarios(t *testing.T) {
@@ -50,6 +50,19 @@ func envOperator(d *dataTreeNavigator, context Context, expressionNode *Expressi
 	return context.SingleChildContext(target), nil
 }
 
+// createEnvMapNode returns a map of all the environment variables, used for $ENV.
+func createEnvMapNode() *yaml.Node {
+	node := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	for _, keyValue := range os.Environ() {
+		parts := strings.SplitN(keyValue, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		node.Content = append(node.Content, createScalarNode(parts[0], parts[0]), createScalarNode(parts[1], parts[1]))
+	}
+	return node
+}
+
 func envsubstOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
 	var results = list.New()
 
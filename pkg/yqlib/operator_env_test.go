@@ -72,6 +72,15 @@ var envOperatorScenarios = []expressionScenario{
 			"D0, P[cat], (!!str)::meow\n",
 		},
 	},
+	{
+		description:          "Read all environment variables",
+		subdescription:       "`$ENV` is a map of all the environment variables, as strings.",
+		environmentVariables: map[string]string{"myenv": "12"},
+		expression:           `.a = $ENV.myenv`,
+		expected: []string{
+			"D0, P[], ()::a: \"12\"\n",
+		},
+	},
 	{
 		description:          "Replace strings with envsubst",
 		environmentVariables: map[string]string{"myenv": "cat"},
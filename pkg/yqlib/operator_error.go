@@ -0,0 +1,47 @@
+package yqlib
+
+import (
+	"fmt"
+	"strings"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+// userError is raised by the error operator, it keeps hold of the
+// value given so that it can be passed on to a catch block.
+type userError struct {
+	Value *yaml.Node
+}
+
+func (e *userError) Error() string {
+	if e.Value.Kind == yaml.ScalarNode {
+		if e.Value.Tag == "!!null" {
+			return "null (null) not a string"
+		}
+		return e.Value.Value
+	}
+	encoded, err := encodeToString(&CandidateNode{Node: e.Value}, encoderPreferences{format: JSONOutputFormat, indent: 0})
+	if err != nil {
+		return fmt.Sprintf("%v (not a string)", e.Value.Tag)
+	}
+	return fmt.Sprintf("%v (not a string)", strings.TrimSpace(encoded))
+}
+
+func errorOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	log.Debugf("-- errorOperator")
+
+	for el := context.MatchingNodes.Front(); el != nil; el = el.Next() {
+		candidate := el.Value.(*CandidateNode)
+		rhs, err := d.GetMatchingNodes(context.SingleReadonlyChildContext(candidate), expressionNode.RHS)
+		if err != nil {
+			return Context{}, err
+		}
+		message := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}
+		if rhs.MatchingNodes.Front() != nil {
+			message = unwrapDoc(rhs.MatchingNodes.Front().Value.(*CandidateNode).Node)
+		}
+		return Context{}, &userError{Value: message}
+	}
+
+	return context, nil
+}
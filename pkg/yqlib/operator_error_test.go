@@ -0,0 +1,53 @@
+package yqlib
+
+import (
+	"testing"
+)
+
+var errorOperatorScenarios = []expressionScenario{
+	{
+		description:   "Raise an error",
+		skipDoc:       true,
+		expression:    `error("something went wrong")`,
+		expectedError: "something went wrong",
+	},
+	{
+		description:   "Raise an error using the current node",
+		skipDoc:       true,
+		document:      `name: cat`,
+		expression:    `error("invalid name: " + .name)`,
+		expectedError: "invalid name: cat",
+	},
+	{
+		skipDoc:       true,
+		expression:    `error({"a": "cat"})`,
+		expectedError: `{"a":"cat"} (not a string)`,
+	},
+	{
+		skipDoc:       true,
+		expression:    `error(null)`,
+		expectedError: `null (null) not a string`,
+	},
+	{
+		description:    "Validate input",
+		subdescription: "Errors are only raised when there is something to raise them against",
+		document:       `[{name: cat, age: 3}, {name: dog, age: 4}]`,
+		expression:     `.[] | select(.age == null) | error("missing age: " + .name)`,
+		expected:       []string{},
+	},
+	{
+		description: "Catch a raised error",
+		document:    `{name: bob}`,
+		expression:  `try error("invalid: " + .name) catch .`,
+		expected: []string{
+			"D0, P[], (!!str)::invalid: bob\n",
+		},
+	},
+}
+
+func TestErrorOperatorScenarios(t *testing.T) {
+	for _, tt := range errorOperatorScenarios {
+		testScenario(t, &tt)
+	}
+	documentOperatorScenarios(t, "error", errorOperatorScenarios)
+}
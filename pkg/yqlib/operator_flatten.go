@@ -52,3 +52,16 @@ func flattenOp(d *dataTreeNavigator, context Context, expressionNode *Expression
 	return context, nil
 
 }
+
+func flattenWithDepthOp(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	log.Debugf("-- flattenWithDepth Operator")
+	depth, err := getIntegerParameter(d, context, expressionNode.RHS, "flatten")
+	if err != nil {
+		return Context{}, err
+	}
+	if depth < 0 {
+		return Context{}, fmt.Errorf("flatten depth must not be negative, got %v", depth)
+	}
+	flattenExpression := &ExpressionNode{Operation: &Operation{OperationType: flattenOpType, Preferences: flattenPreferences{depth: depth}}}
+	return flattenOp(d, context, flattenExpression)
+}
@@ -22,6 +22,22 @@ var flattenOperatorScenarios = []expressionScenario{
 			"D0, P[], (doc)::[1, 2, [3]]\n",
 		},
 	},
+	{
+		description:    "Flatten with a depth expression",
+		subdescription: "The depth can be any expression that returns an integer.",
+		document:       `[1, [2, [3, [4]]]]`,
+		expression:     `flatten(1 + 1)`,
+		expected: []string{
+			"D0, P[], (doc)::[1, 2, 3, [4]]\n",
+		},
+	},
+	{
+		description:   "Flatten with a negative depth",
+		document:      `[1, [2]]`,
+		expression:    `flatten(-1)`,
+		skipDoc:       true,
+		expectedError: "flatten depth must not be negative, got -1",
+	},
 	{
 		description: "Flatten empty array",
 		document:    `[[]]`,
@@ -0,0 +1,49 @@
+package yqlib
+
+import (
+	"testing"
+)
+
+var foreachOperatorScenarios = []expressionScenario{
+	{
+		description: "Running total",
+		document:    `[1, 2, 3]`,
+		expression:  `[foreach .[] as $item (0; . + $item)]`,
+		expected: []string{
+			"D0, P[], (!!seq)::- 1\n- 3\n- 6\n",
+		},
+	},
+	{
+		description:    "Extract from the accumulator",
+		subdescription: "The extract expression is run against the accumulator after each update.",
+		document:       `[1, 2, 3]`,
+		expression:     `[foreach .[] as $item (0; . + $item; [$item, . * 2])]`,
+		expected: []string{
+			"D0, P[], (!!seq)::- - 1\n  - 2\n- - 2\n  - 6\n- - 3\n  - 12\n",
+		},
+	},
+	{
+		description: "Destructure elements",
+		document:    `[[a, 1], [b, 2]]`,
+		expression:  `foreach .[] as [$key, $value] ({}; .[$key] = $value; keys)`,
+		expected: []string{
+			"D0, P[], (!!seq)::- a\n",
+			"D0, P[], (!!seq)::- a\n- b\n",
+		},
+	},
+	{
+		skipDoc:    true,
+		document:   `[]`,
+		expression: `[foreach .[] as $item (0; . + $item)]`,
+		expected: []string{
+			"D0, P[], (!!seq)::[]\n",
+		},
+	},
+}
+
+func TestForeachOperatorScenarios(t *testing.T) {
+	for _, tt := range foreachOperatorScenarios {
+		testScenario(t, &tt)
+	}
+	documentOperatorScenarios(t, "foreach", foreachOperatorScenarios)
+}
@@ -0,0 +1,159 @@
+package yqlib
+
+import (
+	yaml "gopkg.in/yaml.v3"
+)
+
+// maxEnumValues is the most distinct strings a value can have for infer_schema to record them as an enum.
+const maxEnumValues = 5
+
+const inferredSchemaURI = "https://json-schema.org/draft/2020-12/schema"
+
+// schemaInference collects what was seen at one path across all the samples.
+type schemaInference struct {
+	types []string // in the order they were first seen
+
+	stringCount   int
+	stringValues  []string
+	tooManyValues bool
+
+	objectCount    int
+	propertyNames  []string // in the order they were first seen
+	properties     map[string]*schemaInference
+	propertyCounts map[string]int
+
+	items *schemaInference
+}
+
+func newSchemaInference() *schemaInference {
+	return &schemaInference{properties: map[string]*schemaInference{}, propertyCounts: map[string]int{}}
+}
+
+func containsString(values []string, value string) bool {
+	for _, existing := range values {
+		if existing == value {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *schemaInference) add(node *yaml.Node) {
+	node = followAlias(unwrapDoc(node))
+	typeName := jsonSchemaType(node)
+	if !containsString(s.types, typeName) {
+		s.types = append(s.types, typeName)
+	}
+
+	switch typeName {
+	case "object":
+		s.objectCount++
+		for index := 0; index < len(node.Content); index = index + 2 {
+			name := node.Content[index].Value
+			property, exists := s.properties[name]
+			if !exists {
+				property = newSchemaInference()
+				s.properties[name] = property
+				s.propertyNames = append(s.propertyNames, name)
+			}
+			s.propertyCounts[name]++
+			property.add(node.Content[index+1])
+		}
+	case "array":
+		if s.items == nil {
+			s.items = newSchemaInference()
+		}
+		for _, item := range node.Content {
+			s.items.add(item)
+		}
+	case "string":
+		s.stringCount++
+		if !s.tooManyValues && !containsString(s.stringValues, node.Value) {
+			s.stringValues = append(s.stringValues, node.Value)
+			if len(s.stringValues) > maxEnumValues {
+				s.tooManyValues = true
+				s.stringValues = nil
+			}
+		}
+	}
+}
+
+func createStringSequence(values []string) *yaml.Node {
+	seq := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+	for _, value := range values {
+		seq.Content = append(seq.Content, createScalarNode(value, value))
+	}
+	return seq
+}
+
+func (s *schemaInference) toNode() *yaml.Node {
+	schema := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	addKeyword := func(keyword string, value *yaml.Node) {
+		schema.Content = append(schema.Content, createScalarNode(keyword, keyword), value)
+	}
+
+	types := s.types
+	if containsString(types, "integer") && containsString(types, "number") {
+		// integers are numbers too
+		types = []string{}
+		for _, typeName := range s.types {
+			if typeName != "integer" {
+				types = append(types, typeName)
+			}
+		}
+	}
+	if len(types) == 1 {
+		addKeyword("type", createScalarNode(types[0], types[0]))
+	} else if len(types) > 1 {
+		addKeyword("type", createStringSequence(types))
+	}
+
+	// a few distinct values that repeat look like an enum, rather than names that happen to be few
+	if len(types) == 1 && types[0] == "string" && !s.tooManyValues && len(s.stringValues) < s.stringCount {
+		addKeyword("enum", createStringSequence(s.stringValues))
+	}
+
+	if len(s.propertyNames) > 0 {
+		properties := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		required := []string{}
+		for _, name := range s.propertyNames {
+			properties.Content = append(properties.Content, createScalarNode(name, name), s.properties[name].toNode())
+			if s.propertyCounts[name] == s.objectCount {
+				required = append(required, name)
+			}
+		}
+		addKeyword("properties", properties)
+		if len(required) > 0 {
+			addKeyword("required", createStringSequence(required))
+		}
+	}
+
+	if s.items != nil && len(s.items.types) > 0 {
+		addKeyword("items", s.items.toNode())
+	}
+	return schema
+}
+
+// inferSchema creates a JSON Schema that all the given nodes are valid against.
+func inferSchema(nodes []*yaml.Node) *yaml.Node {
+	inference := newSchemaInference()
+	for _, node := range nodes {
+		inference.add(node)
+	}
+	schema := inference.toNode()
+	schema.Content = append([]*yaml.Node{createScalarNode("$schema", "$schema"), createScalarNode(inferredSchemaURI, inferredSchemaURI)}, schema.Content...)
+	return schema
+}
+
+func inferSchemaOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	log.Debugf("-- inferSchemaOperator")
+	if context.MatchingNodes.Front() == nil {
+		return context, nil
+	}
+	nodes := []*yaml.Node{}
+	for el := context.MatchingNodes.Front(); el != nil; el = el.Next() {
+		nodes = append(nodes, el.Value.(*CandidateNode).Node)
+	}
+	first := context.MatchingNodes.Front().Value.(*CandidateNode)
+	return context.SingleChildContext(first.CreateReplacement(inferSchema(nodes))), nil
+}
@@ -0,0 +1,78 @@
+package yqlib
+
+import (
+	"testing"
+)
+
+var inferSchemaOperatorScenarios = []expressionScenario{
+	{
+		description:    "Infer a schema",
+		subdescription: "Map keys are required when they are in every sample.",
+		document:       `{name: app, replicas: 2, ports: [80, 443], labels: {tier: web}}`,
+		expression:     `infer_schema`,
+		expected: []string{
+			"D0, P[], (!!map)::$schema: https://json-schema.org/draft/2020-12/schema\ntype: object\nproperties:\n    name:\n        type: string\n    replicas:\n        type: integer\n    ports:\n        type: array\n        items:\n            type: integer\n    labels:\n        type: object\n        properties:\n            tier:\n                type: string\n        required:\n            - tier\nrequired:\n    - name\n    - replicas\n    - ports\n    - labels\n",
+		},
+	},
+	{
+		description:    "Infer a schema from several documents",
+		subdescription: "Use eval-all (or the infer-schema command) to combine all the documents of all the files. Strings that keep repeating a few values are recorded as an enum.",
+		document:       "kind: Service\nport: 80\n---\nkind: Service\nport: 8.5\n",
+		document2:      "kind: Deployment\nreplicas: 1\n---\nkind: Deployment\nport: null\n",
+		expression:     `infer_schema`,
+		expected: []string{
+			"D0, P[], (!!map)::$schema: https://json-schema.org/draft/2020-12/schema\ntype: object\nproperties:\n    kind:\n        type: string\n        enum:\n            - Service\n            - Deployment\n    port:\n        type:\n            - number\n            - \"null\"\n    replicas:\n        type: integer\nrequired:\n    - kind\n",
+		},
+	},
+	{
+		description: "Infer a schema for arrays of objects",
+		document:    `[{name: a, tags: []}, {name: b}]`,
+		expression:  `infer_schema | .items`,
+		expected: []string{
+			"D0, P[items], (!!map)::type: object\nproperties:\n    name:\n        type: string\n    tags:\n        type: array\nrequired:\n    - name\n",
+		},
+	},
+	{
+		description:    "Validate against the inferred schema",
+		subdescription: "All the samples are valid against the schema.",
+		document:       `{a: [1, "x", {b: true}]}`,
+		expression:     `validate(infer_schema) | length`,
+		expected: []string{
+			"D0, P[], (!!int)::0\n",
+		},
+	},
+	{
+		description: "A repeated value makes an enum",
+		skipDoc:     true,
+		document:    `[{env: prod}, {env: dev}, {env: prod}]`,
+		expression:  `infer_schema | .items.properties.env`,
+		expected: []string{
+			"D0, P[items properties env], (!!map)::type: string\nenum:\n    - prod\n    - dev\n",
+		},
+	},
+	{
+		description: "Distinct strings are not an enum",
+		skipDoc:     true,
+		document:    `[{name: a}, {name: b}, {name: c}]`,
+		expression:  `infer_schema | .items.properties.name | has("enum")`,
+		expected: []string{
+			"D0, P[items properties name], (!!bool)::false\n",
+		},
+	},
+	{
+		description: "Too many distinct strings are not an enum",
+		skipDoc:     true,
+		document:    `[a, b, c, d, e, f, a, b, c, d, e, f]`,
+		expression:  `infer_schema | .items | has("enum")`,
+		expected: []string{
+			"D0, P[items], (!!bool)::false\n",
+		},
+	},
+}
+
+func TestInferSchemaOperatorScenarios(t *testing.T) {
+	for _, tt := range inferSchemaOperatorScenarios {
+		testScenario(t, &tt)
+	}
+	documentOperatorScenarios(t, "infer-schema", inferSchemaOperatorScenarios)
+}
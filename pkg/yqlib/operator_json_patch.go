@@ -0,0 +1,377 @@
+package yqlib
+
+import (
+	"container/list"
+	"fmt"
+
+	"github.com/jinzhu/copier"
+	yaml "gopkg.in/yaml.v3"
+)
+
+func jsonPatchOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	log.Debugf("-- jsonPatchOperator")
+	results := list.New()
+
+	for el := context.MatchingNodes.Front(); el != nil; el = el.Next() {
+		candidate := el.Value.(*CandidateNode)
+		patch, err := getNodeParameter(d, context, candidate, expressionNode.RHS, "json_patch")
+		if err != nil {
+			return Context{}, err
+		}
+		if patch.Kind != yaml.SequenceNode {
+			return Context{}, fmt.Errorf("json_patch expects an array of operations, got %v", patch.Tag)
+		}
+		// patches are applied to a copy, so nothing changes if an operation fails
+		document, err := deepCopyNode(candidate.Node)
+		if err != nil {
+			return Context{}, err
+		}
+		root := unwrapDoc(document)
+		for _, operation := range patch.Content {
+			root, err = applyJSONPatchOperation(root, operation)
+			if err != nil {
+				return Context{}, err
+			}
+		}
+		if document.Kind == yaml.DocumentNode {
+			document.Content[0] = root
+		} else {
+			document = root
+		}
+		result := candidate.CreateReplacement(document)
+		// the patched document is an edit of the original, so keeps its leading comments
+		result.LeadingContent = candidate.LeadingContent
+		results.PushBack(result)
+	}
+	return context.ChildContext(results), nil
+}
+
+func deepCopyNode(node *yaml.Node) (*yaml.Node, error) {
+	copied := &yaml.Node{}
+	err := copier.CopyWithOption(copied, node, copier.Option{DeepCopy: true})
+	return copied, err
+}
+
+func getJSONPatchField(operation *yaml.Node, name string, opName string) (*yaml.Node, error) {
+	index := findKeyInMap(operation, createScalarNode(name, name))
+	if index == -1 {
+		return nil, fmt.Errorf("json_patch %v operation expects a '%v' field", opName, name)
+	}
+	return operation.Content[index+1], nil
+}
+
+func getJSONPatchPointer(operation *yaml.Node, name string, opName string) ([]string, error) {
+	pointer, err := getJSONPatchField(operation, name, opName)
+	if err != nil {
+		return nil, err
+	}
+	if !isStringNode(pointer) {
+		return nil, fmt.Errorf("json_patch expects '%v' to be a string, got %v", name, pointer.Tag)
+	}
+	return parseJSONPointer(pointer.Value)
+}
+
+// applyJSONPatchOperation applies a single RFC 6902 operation, and returns the new root of the document.
+func applyJSONPatchOperation(root *yaml.Node, operation *yaml.Node) (*yaml.Node, error) {
+	operation = unwrapDoc(operation)
+	if operation.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("json_patch expects each operation to be a map, got %v", operation.Tag)
+	}
+	opIndex := findKeyInMap(operation, createScalarNode("op", "op"))
+	if opIndex == -1 {
+		return nil, fmt.Errorf("json_patch expects each operation to have an 'op' field")
+	}
+	opName := operation.Content[opIndex+1].Value
+	path, err := getJSONPatchPointer(operation, "path", opName)
+	if err != nil {
+		return nil, err
+	}
+
+	switch opName {
+	case "add", "replace", "test":
+		value, err := getJSONPatchField(operation, "value", opName)
+		if err != nil {
+			return nil, err
+		}
+		if opName == "test" {
+			return root, jsonPatchTest(root, path, value)
+		}
+		value, err = deepCopyNode(value)
+		if err != nil {
+			return nil, err
+		}
+		if opName == "replace" {
+			return jsonPatchReplace(root, path, value)
+		}
+		return jsonPatchAdd(root, path, value)
+	case "remove":
+		_, err := jsonPatchRemove(root, path)
+		return root, err
+	case "move", "copy":
+		from, err := getJSONPatchPointer(operation, "from", opName)
+		if err != nil {
+			return nil, err
+		}
+		if opName == "copy" {
+			value, err := jsonPatchGet(root, from, opName)
+			if err != nil {
+				return nil, err
+			}
+			value, err = deepCopyNode(value)
+			if err != nil {
+				return nil, err
+			}
+			return jsonPatchAdd(root, path, value)
+		}
+		if isPathPrefix(from, path) && len(from) < len(path) {
+			return nil, fmt.Errorf("json_patch cannot move '%v' into itself", toJSONPointer(toPath(from)))
+		}
+		if len(from) == 0 {
+			return root, nil
+		}
+		value, err := jsonPatchRemove(root, from)
+		if err != nil {
+			return nil, err
+		}
+		return jsonPatchAdd(root, path, value)
+	}
+	return nil, fmt.Errorf("json_patch does not support '%v' operations", opName)
+}
+
+func toPath(tokens []string) []interface{} {
+	path := make([]interface{}, len(tokens))
+	for i, token := range tokens {
+		path[i] = token
+	}
+	return path
+}
+
+func isPathPrefix(prefix []string, tokens []string) bool {
+	if len(prefix) > len(tokens) {
+		return false
+	}
+	for i := range prefix {
+		if prefix[i] != tokens[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func jsonPatchGet(root *yaml.Node, tokens []string, opName string) (*yaml.Node, error) {
+	found, err := getValueAtJSONPointer(&CandidateNode{Node: root}, tokens)
+	if err != nil {
+		return nil, err
+	}
+	if found == nil {
+		return nil, fmt.Errorf("json_patch cannot %v '%v', it does not exist", opName, toJSONPointer(toPath(tokens)))
+	}
+	return followAlias(unwrapDoc(found.Node)), nil
+}
+
+// jsonPatchChildIndex returns the index of the child in the parent's content, or -1 if it isn't there.
+func jsonPatchChildIndex(parent *yaml.Node, token string) int {
+	switch parent.Kind {
+	case yaml.MappingNode:
+		for index := 0; index < len(parent.Content); index = index + 2 {
+			if parent.Content[index].Value == token {
+				return index + 1
+			}
+		}
+	case yaml.SequenceNode:
+		if index, isIndex := parseJSONPointerIndex(token); isIndex && index < len(parent.Content) {
+			return index
+		}
+	}
+	return -1
+}
+
+func jsonPatchAdd(root *yaml.Node, tokens []string, value *yaml.Node) (*yaml.Node, error) {
+	if len(tokens) == 0 {
+		return value, nil
+	}
+	parent, err := jsonPatchGet(root, tokens[:len(tokens)-1], "add to")
+	if err != nil {
+		return nil, err
+	}
+	token := tokens[len(tokens)-1]
+
+	switch parent.Kind {
+	case yaml.MappingNode:
+		if index := jsonPatchChildIndex(parent, token); index != -1 {
+			parent.Content[index] = value
+		} else {
+			parent.Content = append(parent.Content, createScalarNode(token, token), value)
+		}
+	case yaml.SequenceNode:
+		index, isIndex := parseJSONPointerIndex(token)
+		if token == "-" {
+			index = len(parent.Content)
+		} else if !isIndex || index > len(parent.Content) {
+			return nil, fmt.Errorf("json_patch cannot add to '%v', it is not a valid index", toJSONPointer(toPath(tokens)))
+		}
+		content := make([]*yaml.Node, 0, len(parent.Content)+1)
+		content = append(content, parent.Content[:index]...)
+		content = append(content, value)
+		parent.Content = append(content, parent.Content[index:]...)
+	default:
+		return nil, fmt.Errorf("json_patch cannot add to '%v', its parent is a %v", toJSONPointer(toPath(tokens)), parent.Tag)
+	}
+	return root, nil
+}
+
+// jsonPatchRemove removes the node at the path, and returns it.
+func jsonPatchRemove(root *yaml.Node, tokens []string) (*yaml.Node, error) {
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("json_patch cannot remove the whole document")
+	}
+	parent, err := jsonPatchGet(root, tokens[:len(tokens)-1], "remove")
+	if err != nil {
+		return nil, err
+	}
+	index := jsonPatchChildIndex(parent, tokens[len(tokens)-1])
+	if index == -1 {
+		return nil, fmt.Errorf("json_patch cannot remove '%v', it does not exist", toJSONPointer(toPath(tokens)))
+	}
+	removed := parent.Content[index]
+	if parent.Kind == yaml.MappingNode {
+		parent.Content = append(parent.Content[:index-1], parent.Content[index+1:]...)
+	} else {
+		parent.Content = append(parent.Content[:index], parent.Content[index+1:]...)
+	}
+	return removed, nil
+}
+
+// keepComments copies the comments of the original node to its replacement, unless the replacement has its own.
+func keepComments(original *yaml.Node, replacement *yaml.Node) {
+	if replacement.HeadComment == "" && replacement.LineComment == "" && replacement.FootComment == "" {
+		replacement.HeadComment = original.HeadComment
+		replacement.LineComment = original.LineComment
+		replacement.FootComment = original.FootComment
+	}
+}
+
+// jsonPatchReplace keeps the comments of the replaced node, unless the new value has its own.
+func jsonPatchReplace(root *yaml.Node, tokens []string, value *yaml.Node) (*yaml.Node, error) {
+	if len(tokens) == 0 {
+		return value, nil
+	}
+	parent, err := jsonPatchGet(root, tokens[:len(tokens)-1], "replace")
+	if err != nil {
+		return nil, err
+	}
+	index := jsonPatchChildIndex(parent, tokens[len(tokens)-1])
+	if index == -1 {
+		return nil, fmt.Errorf("json_patch cannot replace '%v', it does not exist", toJSONPointer(toPath(tokens)))
+	}
+	keepComments(parent.Content[index], value)
+	parent.Content[index] = value
+	return root, nil
+}
+
+func jsonPatchTest(root *yaml.Node, tokens []string, value *yaml.Node) error {
+	found, err := jsonPatchGet(root, tokens, "test")
+	if err != nil {
+		return err
+	}
+	if !recursiveNodeEqual(found, unwrapDoc(value)) {
+		expected, err := formatFlowNode(unwrapDoc(value))
+		if err != nil {
+			return err
+		}
+		return fmt.Errorf("json_patch test failed, '%v' is not %v", toJSONPointer(toPath(tokens)), expected)
+	}
+	return nil
+}
+
+func jsonDiffOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	log.Debugf("-- jsonDiffOperator")
+	results := list.New()
+
+	for el := context.MatchingNodes.Front(); el != nil; el = el.Next() {
+		candidate := el.Value.(*CandidateNode)
+		target, err := getNodeParameter(d, context, candidate, expressionNode.RHS, "json_diff")
+		if err != nil {
+			return Context{}, err
+		}
+		patch := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		generateJSONPatch(patch, []interface{}{}, unwrapDoc(candidate.Node), target)
+		results.PushBack(candidate.CreateReplacement(patch))
+	}
+	return context.ChildContext(results), nil
+}
+
+func addJSONPatchOperation(patch *yaml.Node, op string, path []interface{}, value *yaml.Node) {
+	pointer := toJSONPointer(path)
+	operation := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	operation.Content = append(operation.Content,
+		createScalarNode("op", "op"), createScalarNode(op, op),
+		createScalarNode("path", "path"), createScalarNode(pointer, pointer),
+	)
+	if value != nil {
+		operation.Content = append(operation.Content, createScalarNode("value", "value"), value)
+	}
+	patch.Content = append(patch.Content, operation)
+}
+
+// generateJSONPatch adds the operations that turn source into target to the patch. Like json, the order of
+// keys is ignored.
+func generateJSONPatch(patch *yaml.Node, path []interface{}, source *yaml.Node, target *yaml.Node) {
+	source = followAlias(source)
+	target = followAlias(target)
+	if source.Kind != target.Kind {
+		addJSONPatchOperation(patch, "replace", path, target)
+	} else if source.Kind == yaml.MappingNode {
+		generateJSONPatchForMaps(patch, path, source, target)
+	} else if source.Kind == yaml.SequenceNode {
+		generateJSONPatchForArrays(patch, path, source, target)
+	} else if !recursiveNodeEqual(source, target) {
+		addJSONPatchOperation(patch, "replace", path, target)
+	}
+}
+
+func generateJSONPatchForMaps(patch *yaml.Node, path []interface{}, source *yaml.Node, target *yaml.Node) {
+	for index := 0; index < len(source.Content); index = index + 2 {
+		key := source.Content[index]
+		keyPath := childPath(path, key.Value)
+		if indexInTarget := jsonPatchChildIndex(target, key.Value); indexInTarget == -1 {
+			addJSONPatchOperation(patch, "remove", keyPath, nil)
+		} else {
+			generateJSONPatch(patch, keyPath, source.Content[index+1], target.Content[indexInTarget])
+		}
+	}
+	for index := 0; index < len(target.Content); index = index + 2 {
+		key := target.Content[index]
+		if jsonPatchChildIndex(source, key.Value) == -1 {
+			addJSONPatchOperation(patch, "add", childPath(path, key.Value), target.Content[index+1])
+		}
+	}
+}
+
+// generateJSONPatchForArrays skips the items that are the same at the start and end of both arrays,
+// so inserting or removing a single item is a single operation. The rest are compared by index.
+func generateJSONPatchForArrays(patch *yaml.Node, path []interface{}, source *yaml.Node, target *yaml.Node) {
+	sourceLength := len(source.Content)
+	targetLength := len(target.Content)
+	prefix := 0
+	for prefix < sourceLength && prefix < targetLength && recursiveNodeEqual(source.Content[prefix], target.Content[prefix]) {
+		prefix++
+	}
+	suffix := 0
+	for suffix < sourceLength-prefix && suffix < targetLength-prefix &&
+		recursiveNodeEqual(source.Content[sourceLength-1-suffix], target.Content[targetLength-1-suffix]) {
+		suffix++
+	}
+
+	index := prefix
+	for ; index < sourceLength-suffix && index < targetLength-suffix; index++ {
+		generateJSONPatch(patch, childPath(path, index), source.Content[index], target.Content[index])
+	}
+	// removing from the end first keeps the indices of the earlier items
+	for removeIndex := sourceLength - suffix - 1; removeIndex >= index; removeIndex-- {
+		addJSONPatchOperation(patch, "remove", childPath(path, removeIndex), nil)
+	}
+	for ; index < targetLength-suffix; index++ {
+		addJSONPatchOperation(patch, "add", childPath(path, index), target.Content[index])
+	}
+}
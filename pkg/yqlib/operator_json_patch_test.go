@@ -0,0 +1,178 @@
+package yqlib
+
+import (
+	"testing"
+)
+
+var jsonPatchOperatorScenarios = []expressionScenario{
+	{
+		description:    "Apply a patch",
+		subdescription: "Comments and styles of the nodes the patch doesn't touch are kept.",
+		document:       "# config\na: cat # pet\nb: [1, 2]\nc: {d: 1}\n",
+		expression:     `json_patch([{"op": "add", "path": "/b/1", "value": 5}, {"op": "remove", "path": "/c/d"}, {"op": "add", "path": "/e", "value": "new"}])`,
+		expected: []string{
+			"D0, P[], (doc)::# config\na: cat # pet\nb: [1, 5, 2]\nc: {}\ne: new\n",
+		},
+	},
+	{
+		description:    "Replace a value",
+		subdescription: "The comments of the replaced value are kept.",
+		document:       "a: cat # pet\n",
+		expression:     `json_patch([{"op": "replace", "path": "/a", "value": "dog"}])`,
+		expected: []string{
+			"D0, P[], (doc)::a: dog # pet\n",
+		},
+	},
+	{
+		description: "Move and copy",
+		document:    `{a: {b: cat}, c: [1]}`,
+		expression:  `json_patch([{"op": "move", "from": "/a/b", "path": "/d"}, {"op": "copy", "from": "/c/0", "path": "/c/-"}])`,
+		expected: []string{
+			"D0, P[], (doc)::{a: {}, c: [1, 1], d: cat}\n",
+		},
+	},
+	{
+		description:    "Test a value",
+		subdescription: "The patch only applies when the test passes.",
+		document:       `{a: cat}`,
+		expression:     `json_patch([{"op": "test", "path": "/a", "value": "cat"}, {"op": "replace", "path": "/a", "value": "dog"}])`,
+		expected: []string{
+			"D0, P[], (doc)::{a: dog}\n",
+		},
+	},
+	{
+		description:    "Escaped keys",
+		subdescription: "Use `~1` for `/` and `~0` for `~` in keys.",
+		document:       `{"a/b": 1, "c~d": 2}`,
+		expression:     `json_patch([{"op": "remove", "path": "/a~1b"}, {"op": "replace", "path": "/c~0d", "value": 3}])`,
+		expected: []string{
+			"D0, P[], (doc)::{\"c~d\": 3}\n",
+		},
+	},
+	{
+		description:    "Apply a patch from a file",
+		subdescription: "Patches are usually json, but can be given in yaml too.",
+		document:       `{a: cat}`,
+		expression:     `json_patch(load("../../examples/patch.json"))`,
+		expected: []string{
+			"D0, P[], (doc)::{a: \"dog\", b: [1]}\n",
+		},
+	},
+	{
+		description: "Replace the whole document",
+		skipDoc:     true,
+		document:    `{a: cat}`,
+		expression:  `json_patch([{"op": "replace", "path": "", "value": [1]}])`,
+		expected: []string{
+			"D0, P[], (doc)::- 1\n",
+		},
+	},
+	{
+		description: "Pointer to a key that looks like an index",
+		skipDoc:     true,
+		document:    `{"0": a, b: [x]}`,
+		expression:  `json_patch([{"op": "replace", "path": "/0", "value": "b"}, {"op": "add", "path": "/b/0", "value": "y"}])`,
+		expected: []string{
+			"D0, P[], (doc)::{\"0\": b, b: [y, x]}\n",
+		},
+	},
+	{
+		description:   "Failing test",
+		skipDoc:       true,
+		document:      `{a: cat}`,
+		expression:    `json_patch([{"op": "test", "path": "/a", "value": "dog"}])`,
+		expectedError: "json_patch test failed, '/a' is not dog",
+	},
+	{
+		description:   "Missing path",
+		skipDoc:       true,
+		document:      `{a: cat}`,
+		expression:    `json_patch([{"op": "remove", "path": "/b"}])`,
+		expectedError: "json_patch cannot remove '/b', it does not exist",
+	},
+	{
+		description:   "Missing parent",
+		skipDoc:       true,
+		document:      `{a: cat}`,
+		expression:    `json_patch([{"op": "add", "path": "/b/c", "value": 1}])`,
+		expectedError: "json_patch cannot add to '/b', it does not exist",
+	},
+	{
+		description:   "Index out of range",
+		skipDoc:       true,
+		document:      `{a: [1]}`,
+		expression:    `json_patch([{"op": "add", "path": "/a/3", "value": 1}])`,
+		expectedError: "json_patch cannot add to '/a/3', it is not a valid index",
+	},
+	{
+		description:   "Move into itself",
+		skipDoc:       true,
+		document:      `{a: {b: 1}}`,
+		expression:    `json_patch([{"op": "move", "from": "/a", "path": "/a/c"}])`,
+		expectedError: "json_patch cannot move '/a' into itself",
+	},
+	{
+		description:   "Unknown operation",
+		skipDoc:       true,
+		document:      `{a: cat}`,
+		expression:    `json_patch([{"op": "frob", "path": "/a"}])`,
+		expectedError: "json_patch does not support 'frob' operations",
+	},
+	{
+		description:   "Invalid pointer",
+		skipDoc:       true,
+		document:      `{a: cat}`,
+		expression:    `json_patch([{"op": "remove", "path": "a"}])`,
+		expectedError: "'a' is not a valid json pointer, it must start with '/'",
+	},
+	{
+		description:   "Missing op",
+		skipDoc:       true,
+		document:      `{a: cat}`,
+		expression:    `json_patch([{"path": "/a"}])`,
+		expectedError: "json_patch expects each operation to have an 'op' field",
+	},
+	{
+		description:    "Generate a patch",
+		subdescription: "The order of keys is ignored, like in json. Items inserted or removed from the middle of an array are a single operation.",
+		document:       `{a: cat, b: [1, 2, 3], c: {d: 1}}`,
+		expression:     `json_diff({"c": {"d": 2}, "b": [1, 3], "a": "cat", "e": true})`,
+		expected: []string{
+			"D0, P[], (!!seq)::- op: remove\n  path: /b/1\n- op: replace\n  path: /c/d\n  value: 2\n- op: add\n  path: /e\n  value: true\n",
+		},
+	},
+	{
+		description:    "Generate a patch from another file",
+		subdescription: "Applying the generated patch gives the other document.",
+		document:       `{a: cat, b: [1, 2]}`,
+		expression:     `json_patch(json_diff(load("../../examples/data1.yaml")))`,
+		expected: []string{
+			"D0, P[], (doc)::{cat: purrs}\n",
+		},
+	},
+	{
+		description: "Generate a patch for arrays",
+		skipDoc:     true,
+		document:    `[1, 2, 3]`,
+		expression:  `json_diff([0, 1, 4, 5, 6])`,
+		expected: []string{
+			"D0, P[], (!!seq)::- op: replace\n  path: /0\n  value: 0\n- op: replace\n  path: /1\n  value: 1\n- op: replace\n  path: /2\n  value: 4\n- op: add\n  path: /3\n  value: 5\n- op: add\n  path: /4\n  value: 6\n",
+		},
+	},
+	{
+		description: "Generate a patch with escaped keys",
+		skipDoc:     true,
+		document:    `{"a/b": 1}`,
+		expression:  `json_diff({"a/b": 2, "~": 1})`,
+		expected: []string{
+			"D0, P[], (!!seq)::- op: replace\n  path: /a~1b\n  value: 2\n- op: add\n  path: /~0\n  value: 1\n",
+		},
+	},
+}
+
+func TestJSONPatchOperatorScenarios(t *testing.T) {
+	for _, tt := range jsonPatchOperatorScenarios {
+		testScenario(t, &tt)
+	}
+	documentOperatorScenarios(t, "json-patch", jsonPatchOperatorScenarios)
+}
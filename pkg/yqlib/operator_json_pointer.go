@@ -0,0 +1,86 @@
+package yqlib
+
+import (
+	"container/list"
+	"fmt"
+)
+
+// getPathStringParameters returns each of the strings the expression evaluates to, so that several paths can be given at once.
+func getPathStringParameters(d *dataTreeNavigator, context Context, candidate *CandidateNode, expressionNode *ExpressionNode, operatorName string) ([]string, error) {
+	result, err := d.GetMatchingNodes(context.SingleReadonlyChildContext(candidate), expressionNode)
+	if err != nil {
+		return nil, err
+	}
+	paths := []string{}
+	for el := result.MatchingNodes.Front(); el != nil; el = el.Next() {
+		node := unwrapDoc(el.Value.(*CandidateNode).Node)
+		if !isStringNode(node) {
+			return nil, fmt.Errorf("%v expects a string parameter, got %v instead", operatorName, node.Tag)
+		}
+		paths = append(paths, node.Value)
+	}
+	return paths, nil
+}
+
+func pointerOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	log.Debugf("-- pointerOperator")
+	results := list.New()
+
+	for el := context.MatchingNodes.Front(); el != nil; el = el.Next() {
+		candidate := el.Value.(*CandidateNode)
+		pointers, err := getPathStringParameters(d, context, candidate, expressionNode.RHS, "pointer")
+		if err != nil {
+			return Context{}, err
+		}
+		for _, pointer := range pointers {
+			tokens, err := parseJSONPointer(pointer)
+			if err != nil {
+				return Context{}, err
+			}
+			found, err := traverseJSONPointer(candidate, tokens, !context.DontAutoCreate)
+			if err != nil {
+				return Context{}, err
+			} else if found != nil {
+				results.PushBack(found)
+			}
+		}
+	}
+	return context.ChildContext(results), nil
+}
+
+func jsonPathOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	log.Debugf("-- jsonPathOperator")
+	results := list.New()
+
+	for el := context.MatchingNodes.Front(); el != nil; el = el.Next() {
+		candidate := el.Value.(*CandidateNode)
+		paths, err := getPathStringParameters(d, context, candidate, expressionNode.RHS, "jsonpath")
+		if err != nil {
+			return Context{}, err
+		}
+		for _, path := range paths {
+			segments, err := parseJSONPath(path)
+			if err != nil {
+				return Context{}, err
+			}
+			found, err := evaluateJSONPath(d, context, candidate, segments, !context.DontAutoCreate)
+			if err != nil {
+				return Context{}, err
+			}
+			results.PushBackList(found)
+		}
+	}
+	return context.ChildContext(results), nil
+}
+
+func toPointerOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	log.Debugf("-- toPointerOperator")
+	results := list.New()
+
+	for el := context.MatchingNodes.Front(); el != nil; el = el.Next() {
+		candidate := el.Value.(*CandidateNode)
+		pointer := toJSONPointer(candidate.Path)
+		results.PushBack(candidate.CreateReplacement(createScalarNode(pointer, pointer)))
+	}
+	return context.ChildContext(results), nil
+}
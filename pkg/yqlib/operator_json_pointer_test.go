@@ -0,0 +1,149 @@
+package yqlib
+
+import (
+	"testing"
+)
+
+var jsonPointerOperatorScenarios = []expressionScenario{
+	{
+		description:    "Get a value by JSON Pointer",
+		subdescription: "`~1` and `~0` escape `/` and `~` in keys, see [RFC 6901](https://datatracker.ietf.org/doc/html/rfc6901).",
+		document:       `{spec: {containers: [{name: app, image: "app:1"}], "a/b": cat}}`,
+		expression:     `pointer("/spec/containers/0/image"), pointer("/spec/a~1b")`,
+		expected: []string{
+			"D0, P[spec containers 0 image], (!!str)::app:1\n",
+			"D0, P[spec a/b], (!!str)::cat\n",
+		},
+	},
+	{
+		description: "Set a value by JSON Pointer",
+		document:    `{spec: {containers: [{name: app, image: "app:1"}]}}`,
+		expression:  `pointer("/spec/containers/0/image") = "app:2"`,
+		expected: []string{
+			"D0, P[], (doc)::{spec: {containers: [{name: app, image: \"app:2\"}]}}\n",
+		},
+	},
+	{
+		description:    "Missing paths are created when assigning",
+		subdescription: "Whether a token is a key or an index depends on the node it's applied to. Missing numeric tokens create arrays.",
+		document:       `{a: {"0": cat}}`,
+		expression:     `pointer("/a/0") = "dog" | pointer("/b/0/c") = "frog"`,
+		expected: []string{
+			"D0, P[], (doc)::{a: {\"0\": dog}, b: [{c: frog}]}\n",
+		},
+	},
+	{
+		description:    "Update a value by JSON Pointer",
+		subdescription: "Pointers are evaluated against each matching node, so can be built from other values.",
+		document:       `{target: /a/b, a: {b: 1}}`,
+		expression:     `pointer(.target) |= . + 1`,
+		expected: []string{
+			"D0, P[], (doc)::{target: /a/b, a: {b: 2}}\n",
+		},
+	},
+	{
+		description: "Get values by JSONPath",
+		document:    `{spec: {containers: [{name: app, image: "app:1"}, {name: sidecar, image: "side:1"}]}}`,
+		expression:  `jsonpath("$.spec.containers[*].image")`,
+		expected: []string{
+			"D0, P[spec containers 0 image], (!!str)::app:1\n",
+			"D0, P[spec containers 1 image], (!!str)::side:1\n",
+		},
+	},
+	{
+		description: "JSONPath recursive descent",
+		document:    `{a: {name: x, b: [{name: y}, {c: {name: z}}]}}`,
+		expression:  `jsonpath("$..name")`,
+		expected: []string{
+			"D0, P[a name], (!!str)::x\n",
+			"D0, P[a b 0 name], (!!str)::y\n",
+			"D0, P[a b 1 c name], (!!str)::z\n",
+		},
+	},
+	{
+		description:    "JSONPath filters",
+		subdescription: "Filters support yq expressions, with `@` as the current item and `&&`/`||` for `and`/`or`.",
+		document:       `{containers: [{name: app, image: "app:1"}, {name: sidecar, image: "side:1"}]}`,
+		expression:     `jsonpath("$.containers[?(@.name == 'sidecar')].image") = "side:2"`,
+		expected: []string{
+			"D0, P[], (doc)::{containers: [{name: app, image: \"app:1\"}, {name: sidecar, image: \"side:2\"}]}\n",
+		},
+	},
+	{
+		description: "JSONPath slices, unions and quoted names",
+		document:    `{a: [1, 2, 3, 4], "b.c": {x: 5, y: 6}}`,
+		expression:  `[jsonpath("$.a[1:3]", "$.a[-1:]", "$.a[0,2]", "$['b.c']['x','y']")]`,
+		expected: []string{
+			"D0, P[], (!!seq)::- 2\n- 3\n- 4\n- 1\n- 3\n- 5\n- 6\n",
+		},
+	},
+	{
+		description: "Convert the path to a JSON Pointer",
+		document:    `{a: {"b/c": [cat, dog]}}`,
+		expression:  `.a["b/c"][1] | to_pointer`,
+		expected: []string{
+			"D0, P[a b/c 1], (!!str)::/a/b~1c/1\n",
+		},
+	},
+	{
+		description: "Pointers to all the leaves",
+		document:    `{a: {b: cat, c: [dog]}}`,
+		expression:  `[.. | select(tag != "!!map" and tag != "!!seq") | to_pointer]`,
+		expected: []string{
+			"D0, P[], (!!seq)::- /a/b\n- /a/c/0\n",
+		},
+	},
+	{
+		description: "Missing values are null, like traversing",
+		skipDoc:     true,
+		document:    `{a: [1]}`,
+		expression:  `pointer("/a/5"), jsonpath("$.b.c"), jsonpath("$.a.b"), pointer("/a/b")`,
+		expected: []string{
+			"D0, P[a 5], (!!null)::null\n",
+			"D0, P[b c], (!!null)::null\n",
+		},
+	},
+	{
+		description:   "Pointers must start with /",
+		skipDoc:       true,
+		document:      `{a: 1}`,
+		expression:    `pointer("a")`,
+		expectedError: "'a' is not a valid json pointer, it must start with '/'",
+	},
+	{
+		skipDoc:       true,
+		document:      `{a: [1]}`,
+		expression:    `pointer("/a/-") = 2`,
+		expectedError: "cannot use '-' in a pointer to an array, use json_patch with an 'add' operation or '+= [value]' to append to it",
+	},
+	{
+		skipDoc:     true,
+		description: "'-' is only special for arrays",
+		document:    `{a: {"-": 1}}`,
+		expression:  `pointer("/a/-")`,
+		expected: []string{
+			"D0, P[a -], (!!int)::1\n",
+		},
+	},
+	{
+		description:   "Invalid json path",
+		skipDoc:       true,
+		document:      `{a: 1}`,
+		expression:    `jsonpath("$.a[1")`,
+		expectedError: "'$.a[1' is not a valid json path, '[' at position 3 is not closed",
+	},
+	{
+		description:   "Invalid json path parameter",
+		skipDoc:       true,
+		document:      `{a: 1}`,
+		expression:    `jsonpath(1)`,
+		expectedError: "jsonpath expects a string parameter, got !!int instead",
+	},
+}
+
+func TestJSONPointerOperatorScenarios(t *testing.T) {
+	for _, tt := range jsonPointerOperatorScenarios {
+		testScenario(t, &tt)
+	}
+	documentOperatorScenarios(t, "json-pointer", jsonPointerOperatorScenarios)
+}
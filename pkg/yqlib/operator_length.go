@@ -3,6 +3,7 @@ package yqlib
 import (
 	"container/list"
 	"fmt"
+	"unicode/utf8"
 
 	yaml "gopkg.in/yaml.v3"
 )
@@ -20,7 +21,7 @@ func lengthOperator(d *dataTreeNavigator, context Context, expressionNode *Expre
 			if targetNode.Tag == "!!null" {
 				length = 0
 			} else {
-				length = len(targetNode.Value)
+				length = utf8.RuneCountInString(targetNode.Value)
 			}
 		case yaml.MappingNode:
 			length = len(targetNode.Content) / 2
@@ -14,6 +14,15 @@ var lengthOperatorScenarios = []expressionScenario{
 			"D0, P[a], (!!int)::3\n",
 		},
 	},
+	{
+		description:    "Unicode string length",
+		subdescription: "counts unicode code points, rather than bytes",
+		document:       `{a: "héllo 🐱"}`,
+		expression:     `.a | length`,
+		expected: []string{
+			"D0, P[a], (!!int)::7\n",
+		},
+	},
 	{
 		description: "null length",
 		document:    `{a: null}`,
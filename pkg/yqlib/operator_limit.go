@@ -0,0 +1,175 @@
+package yqlib
+
+import (
+	"container/list"
+	"fmt"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+func getIntegerParameter(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode, operatorName string) (int, error) {
+	result, err := d.GetMatchingNodes(context.ReadOnlyClone(), expressionNode)
+	if err != nil {
+		return 0, err
+	}
+	if result.MatchingNodes.Front() == nil {
+		return 0, fmt.Errorf("%v expects a number, but got nothing", operatorName)
+	}
+	node := unwrapDoc(result.MatchingNodes.Front().Value.(*CandidateNode).Node)
+	if node.Kind != yaml.ScalarNode || node.Tag != "!!int" {
+		return 0, fmt.Errorf("%v expects an integer, got %v instead", operatorName, node.Tag)
+	}
+	_, number, err := parseInt(node.Value)
+	if err != nil {
+		return 0, err
+	}
+	return int(number), nil
+}
+
+// getLimitedMatchingNodes returns (at most) the first 'limit' results of the expression.
+// Where possible, evaluation stops once enough results have been found - e.g. for the branches
+// of a union, when repeating or for a range; otherwise the expression is evaluated in full and trimmed.
+func getLimitedMatchingNodes(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode, limit int) (*list.List, error) {
+	results := list.New()
+	if limit <= 0 {
+		return results, nil
+	}
+
+	switch expressionNode.Operation.OperationType {
+	case unionOpType:
+		lhs, err := getLimitedMatchingNodes(d, context, expressionNode.LHS, limit)
+		if err != nil {
+			return nil, err
+		}
+		results.PushBackList(lhs)
+		if results.Len() < limit {
+			rhs, err := getLimitedMatchingNodes(d, context, expressionNode.RHS, limit-results.Len())
+			if err != nil {
+				return nil, err
+			}
+			results.PushBackList(rhs)
+		}
+		return results, nil
+	case repeatOpType:
+		for el := context.MatchingNodes.Front(); el != nil && results.Len() < limit; el = el.Next() {
+			err := repeat(d, context, el.Value.(*CandidateNode), expressionNode.RHS, results, limit)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return results, nil
+	case rangeOpType:
+		return getRange(d, context, expressionNode, limit)
+	}
+
+	all, err := d.GetMatchingNodes(context, expressionNode)
+	if err != nil {
+		return nil, err
+	}
+	for el := all.MatchingNodes.Front(); el != nil && results.Len() < limit; el = el.Next() {
+		results.PushBack(el.Value)
+	}
+	return results, nil
+}
+
+func limitOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	log.Debugf("-- limitOperator")
+	// limit(n; exp)
+
+	if expressionNode.RHS.Operation.OperationType != blockOpType {
+		return Context{}, fmt.Errorf("limit must be given a block, got %v instead", expressionNode.RHS.Operation.OperationType.Type)
+	}
+
+	limit, err := getIntegerParameter(d, context, expressionNode.RHS.LHS, "limit")
+	if err != nil {
+		return Context{}, err
+	}
+	if limit < 0 {
+		return Context{}, fmt.Errorf("limit expects a non-negative number, got %v", limit)
+	}
+
+	results, err := getLimitedMatchingNodes(d, context, expressionNode.RHS.RHS, limit)
+	if err != nil {
+		return Context{}, err
+	}
+	return context.ChildContext(results), nil
+}
+
+func firstOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	log.Debugf("-- firstOperator")
+	results, err := getLimitedMatchingNodes(d, context, expressionNode.RHS, 1)
+	if err != nil {
+		return Context{}, err
+	}
+	return context.ChildContext(results), nil
+}
+
+func lastOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	log.Debugf("-- lastOperator")
+	all, err := d.GetMatchingNodes(context, expressionNode.RHS)
+	if err != nil {
+		return Context{}, err
+	}
+	results := list.New()
+	if all.MatchingNodes.Back() != nil {
+		results.PushBack(all.MatchingNodes.Back().Value)
+	}
+	return context.ChildContext(results), nil
+}
+
+func nthOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	log.Debugf("-- nthOperator")
+	// nth(n; exp)
+
+	if expressionNode.RHS.Operation.OperationType != blockOpType {
+		return Context{}, fmt.Errorf("nth must be given a block, got %v instead", expressionNode.RHS.Operation.OperationType.Type)
+	}
+
+	index, err := getIntegerParameter(d, context, expressionNode.RHS.LHS, "nth")
+	if err != nil {
+		return Context{}, err
+	}
+	if index < 0 {
+		return Context{}, fmt.Errorf("nth expects a non-negative index, got %v", index)
+	}
+
+	matches, err := getLimitedMatchingNodes(d, context, expressionNode.RHS.RHS, index+1)
+	if err != nil {
+		return Context{}, err
+	}
+	results := list.New()
+	if matches.Len() == index+1 {
+		results.PushBack(matches.Back().Value)
+	}
+	return context.ChildContext(results), nil
+}
+
+func getArrayElement(context Context, operatorName string, pickIndex func(length int) int) (Context, error) {
+	results := list.New()
+
+	for el := context.MatchingNodes.Front(); el != nil; el = el.Next() {
+		candidate := el.Value.(*CandidateNode)
+		node := unwrapDoc(candidate.Node)
+		if node.Kind != yaml.SequenceNode {
+			return Context{}, fmt.Errorf("%v can only be used on arrays, got %v", operatorName, node.Tag)
+		}
+		if len(node.Content) == 0 {
+			results.PushBack(candidate.CreateReplacement(&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}))
+			continue
+		}
+		index := pickIndex(len(node.Content))
+		results.PushBack(candidate.CreateChildInArray(index, node.Content[index]))
+	}
+
+	return context.ChildContext(results), nil
+}
+
+func firstElementOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	log.Debugf("-- firstElementOperator")
+	return getArrayElement(context, "first", func(length int) int { return 0 })
+}
+
+func lastElementOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	log.Debugf("-- lastElementOperator")
+	return getArrayElement(context, "last", func(length int) int { return length - 1 })
+}
@@ -0,0 +1,150 @@
+package yqlib
+
+import (
+	"testing"
+)
+
+var limitOperatorScenarios = []expressionScenario{
+	{
+		description: "Limit the number of results",
+		document:    `[a, b, c, d]`,
+		expression:  `[limit(2; .[])]`,
+		expected: []string{
+			"D0, P[], (!!seq)::- a\n- b\n",
+		},
+	},
+	{
+		description:    "Limit stops evaluating early",
+		subdescription: "Branches of a union (as well as repeat and range) are only evaluated until enough results have been found.",
+		document:       `a: cat`,
+		expression:     `limit(1; .a, error("never evaluated"))`,
+		expected: []string{
+			"D0, P[a], (!!str)::cat\n",
+		},
+	},
+	{
+		description: "Limit a huge range",
+		skipDoc:     true,
+		document:    `{}`,
+		expression:  `[limit(3; range(0; 20000000000))], first(range(5; 20000000000; 0.5))`,
+		expected: []string{
+			"D0, P[], (!!seq)::- 0\n- 1\n- 2\n",
+			"D0, P[], (!!float)::5.0\n",
+		},
+	},
+	{
+		skipDoc:    true,
+		document:   `[a, b]`,
+		expression: `[limit(0; .[])]`,
+		expected: []string{
+			"D0, P[], (!!seq)::[]\n",
+		},
+	},
+	{
+		skipDoc:    true,
+		document:   `[a, b]`,
+		expression: `[limit(5; .[])]`,
+		expected: []string{
+			"D0, P[], (!!seq)::- a\n- b\n",
+		},
+	},
+	{
+		skipDoc:       true,
+		document:      `[a, b]`,
+		expression:    `limit(-1; .[])`,
+		expectedError: "limit expects a non-negative number, got -1",
+	},
+	{
+		skipDoc:       true,
+		document:      `[a, b]`,
+		expression:    `limit("cat"; .[])`,
+		expectedError: "limit expects an integer, got !!str instead",
+	},
+	{
+		description: "First result of an expression",
+		document:    `[{name: cat, pet: false}, {name: dog, pet: true}, {name: fish, pet: true}]`,
+		expression:  `first(.[] | select(.pet)) | .name`,
+		expected: []string{
+			"D0, P[1 name], (!!str)::dog\n",
+		},
+	},
+	{
+		description:    "First matching document",
+		subdescription: "As expressions are run against all the matching nodes together, first can be used to pick a single document.",
+		document:       `a: cat`,
+		document2:      `a: dog`,
+		expression:     `first(select(.a == "dog"), .)`,
+		expected: []string{
+			"D0, P[], (doc)::a: dog\n",
+		},
+	},
+	{
+		description: "Last result of an expression",
+		document:    `[{name: cat, pet: false}, {name: dog, pet: true}, {name: fish, pet: true}]`,
+		expression:  `last(.[] | select(.pet)) | .name`,
+		expected: []string{
+			"D0, P[2 name], (!!str)::fish\n",
+		},
+	},
+	{
+		skipDoc:    true,
+		document:   `[]`,
+		expression: `[first(.[]), last(.[])]`,
+		expected: []string{
+			"D0, P[], (!!seq)::[]\n",
+		},
+	},
+	{
+		description:    "Nth result of an expression",
+		subdescription: "The index starts at 0",
+		document:       `[a, b, c]`,
+		expression:     `nth(1; .[])`,
+		expected: []string{
+			"D0, P[1], (!!str)::b\n",
+		},
+	},
+	{
+		skipDoc:    true,
+		document:   `[a, b, c]`,
+		expression: `[nth(3; .[])]`,
+		expected: []string{
+			"D0, P[], (!!seq)::[]\n",
+		},
+	},
+	{
+		skipDoc:       true,
+		document:      `[a, b, c]`,
+		expression:    `nth(-1; .[])`,
+		expectedError: "nth expects a non-negative index, got -1",
+	},
+	{
+		description: "First and last elements of an array",
+		document:    `[a, b, c]`,
+		expression:  `first, last`,
+		expected: []string{
+			"D0, P[0], (!!str)::a\n",
+			"D0, P[2], (!!str)::c\n",
+		},
+	},
+	{
+		description: "First and last elements of an empty array",
+		document:    `[]`,
+		expression:  `[first, last]`,
+		expected: []string{
+			"D0, P[], (!!seq)::- null\n- null\n",
+		},
+	},
+	{
+		skipDoc:       true,
+		document:      `a: cat`,
+		expression:    `first`,
+		expectedError: "first can only be used on arrays, got !!map",
+	},
+}
+
+func TestLimitOperatorScenarios(t *testing.T) {
+	for _, tt := range limitOperatorScenarios {
+		testScenario(t, &tt)
+	}
+	documentOperatorScenarios(t, "limit", limitOperatorScenarios)
+}
@@ -0,0 +1,31 @@
+package yqlib
+
+import (
+	"container/list"
+)
+
+func positionOperator(getPosition func(candidate *CandidateNode) int) operatorHandler {
+	return func(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+		results := list.New()
+		for el := context.MatchingNodes.Front(); el != nil; el = el.Next() {
+			candidate := el.Value.(*CandidateNode)
+			results.PushBack(candidate.CreateReplacement(createIntNode(getPosition(candidate))))
+		}
+		return context.ChildContext(results), nil
+	}
+}
+
+func lineOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	log.Debugf("-- lineOperator")
+	return positionOperator((*CandidateNode).GetLine)(d, context, expressionNode)
+}
+
+func columnOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	log.Debugf("-- columnOperator")
+	return positionOperator((*CandidateNode).GetColumn)(d, context, expressionNode)
+}
+
+func keyLineOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	log.Debugf("-- keyLineOperator")
+	return positionOperator((*CandidateNode).GetKeyLine)(d, context, expressionNode)
+}
@@ -0,0 +1,51 @@
+package yqlib
+
+import (
+	"testing"
+)
+
+var lineOperatorScenarios = []expressionScenario{
+	{
+		description:    "Get the line and column",
+		subdescription: "Lines and columns start at 1. Nodes created by the expression are at line 0.",
+		document:       "a:\n  b: cat\n  c: [dog, frog]\n",
+		expression:     `.a.c[1] | [line, column]`,
+		expected: []string{
+			"D0, P[a c 1], (!!seq)::- 3\n- 12\n",
+		},
+	},
+	{
+		description:    "Get the line of the key",
+		subdescription: "key_line is the line of the map key, which can be different to the line of the value. It is 0 for array items.",
+		document:       "a:\n  b:\n    c: cat\n",
+		expression:     `.a.b | [key_line, line]`,
+		expected: []string{
+			"D0, P[a b], (!!seq)::- 2\n- 3\n",
+		},
+	},
+	{
+		description:    "Find where values are",
+		subdescription: "Handy for linters pointing at the offending line.",
+		document:       "# config\nreplicas: 1\nports: [80, -1]\n",
+		expression:     `[.. | select(tag == "!!int") | select(. < 0) | {"path": path | join("."), "line": line}]`,
+		expected: []string{
+			"D0, P[], (!!seq)::- path: ports.1\n  line: 3\n",
+		},
+	},
+	{
+		description: "Created nodes have no position",
+		skipDoc:     true,
+		document:    "a: 1\n",
+		expression:  `{"b": 2} | .b | [line, column, key_line]`,
+		expected: []string{
+			"D0, P[b], (!!seq)::- 0\n- 0\n- 0\n",
+		},
+	},
+}
+
+func TestLineOperatorScenarios(t *testing.T) {
+	for _, tt := range lineOperatorScenarios {
+		testScenario(t, &tt)
+	}
+	documentOperatorScenarios(t, "line", lineOperatorScenarios)
+}
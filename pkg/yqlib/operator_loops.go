@@ -0,0 +1,136 @@
+package yqlib
+
+import (
+	"container/list"
+	"fmt"
+)
+
+func getUpdateResults(d *dataTreeNavigator, context Context, candidate *CandidateNode, updateExp *ExpressionNode) ([]*CandidateNode, error) {
+	updated, err := d.GetMatchingNodes(context.SingleReadonlyChildContext(candidate), updateExp)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]*CandidateNode, 0, updated.MatchingNodes.Len())
+	for el := updated.MatchingNodes.Front(); el != nil; el = el.Next() {
+		results = append(results, el.Value.(*CandidateNode))
+	}
+	return results, nil
+}
+
+func conditionIsTrue(d *dataTreeNavigator, context Context, candidate *CandidateNode, conditionExp *ExpressionNode) (bool, error) {
+	result, err := d.GetMatchingNodes(context.SingleReadonlyChildContext(candidate), conditionExp)
+	if err != nil {
+		return false, err
+	}
+	if result.MatchingNodes.Front() == nil {
+		return false, nil
+	}
+	return isTruthy(result.MatchingNodes.Front().Value.(*CandidateNode))
+}
+
+// pushInOrder adds the candidates to the stack so that the first one is popped next,
+// this gives the same depth first ordering as jq's recursive definitions.
+func pushInOrder(stack []*CandidateNode, candidates []*CandidateNode) []*CandidateNode {
+	for i := len(candidates) - 1; i >= 0; i-- {
+		stack = append(stack, candidates[i])
+	}
+	return stack
+}
+
+// repeat outputs the candidate, then the results of applying the update expression over and over again,
+// until there are no more results or the limit (if not negative) has been reached.
+func repeat(d *dataTreeNavigator, context Context, candidate *CandidateNode, updateExp *ExpressionNode, results *list.List, limit int) error {
+	stack := []*CandidateNode{candidate}
+	for len(stack) > 0 && (limit < 0 || results.Len() < limit) {
+		current := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		results.PushBack(current)
+
+		next, err := getUpdateResults(d, context, current, updateExp)
+		if err != nil {
+			return err
+		}
+		stack = pushInOrder(stack, next)
+	}
+	return nil
+}
+
+func repeatOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	log.Debugf("-- repeatOperator")
+	results := list.New()
+	for el := context.MatchingNodes.Front(); el != nil; el = el.Next() {
+		err := repeat(d, context, el.Value.(*CandidateNode), expressionNode.RHS, results, -1)
+		if err != nil {
+			return Context{}, err
+		}
+	}
+	return context.ChildContext(results), nil
+}
+
+func whileOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	log.Debugf("-- whileOperator")
+	// while(cond; update)
+	if expressionNode.RHS.Operation.OperationType != blockOpType {
+		return Context{}, fmt.Errorf("while must be given a block, got %v instead", expressionNode.RHS.Operation.OperationType.Type)
+	}
+	conditionExp := expressionNode.RHS.LHS
+	updateExp := expressionNode.RHS.RHS
+
+	results := list.New()
+	for el := context.MatchingNodes.Front(); el != nil; el = el.Next() {
+		stack := []*CandidateNode{el.Value.(*CandidateNode)}
+		for len(stack) > 0 {
+			current := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+
+			keepGoing, err := conditionIsTrue(d, context, current, conditionExp)
+			if err != nil {
+				return Context{}, err
+			} else if !keepGoing {
+				continue
+			}
+			results.PushBack(current)
+
+			next, err := getUpdateResults(d, context, current, updateExp)
+			if err != nil {
+				return Context{}, err
+			}
+			stack = pushInOrder(stack, next)
+		}
+	}
+	return context.ChildContext(results), nil
+}
+
+func untilOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	log.Debugf("-- untilOperator")
+	// until(cond; update)
+	if expressionNode.RHS.Operation.OperationType != blockOpType {
+		return Context{}, fmt.Errorf("until must be given a block, got %v instead", expressionNode.RHS.Operation.OperationType.Type)
+	}
+	conditionExp := expressionNode.RHS.LHS
+	updateExp := expressionNode.RHS.RHS
+
+	results := list.New()
+	for el := context.MatchingNodes.Front(); el != nil; el = el.Next() {
+		stack := []*CandidateNode{el.Value.(*CandidateNode)}
+		for len(stack) > 0 {
+			current := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+
+			done, err := conditionIsTrue(d, context, current, conditionExp)
+			if err != nil {
+				return Context{}, err
+			} else if done {
+				results.PushBack(current)
+				continue
+			}
+
+			next, err := getUpdateResults(d, context, current, updateExp)
+			if err != nil {
+				return Context{}, err
+			}
+			stack = pushInOrder(stack, next)
+		}
+	}
+	return context.ChildContext(results), nil
+}
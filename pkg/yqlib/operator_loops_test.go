@@ -0,0 +1,64 @@
+package yqlib
+
+import (
+	"testing"
+)
+
+var loopsOperatorScenarios = []expressionScenario{
+	{
+		description:    "Until",
+		subdescription: "Applies the update until the condition is true, and returns the final value.",
+		document:       `1`,
+		expression:     `until(. == 16; . * 2)`,
+		expected: []string{
+			"D0, P[], (!!int)::16\n",
+		},
+	},
+	{
+		description:    "While",
+		subdescription: "Returns each value while the condition is true.",
+		document:       `1`,
+		expression:     `[while(. != 16; . * 2)]`,
+		expected: []string{
+			"D0, P[], (!!seq)::- 1\n- 2\n- 4\n- 8\n",
+		},
+	},
+	{
+		description:    "Repeat",
+		subdescription: "Outputs the value, then repeatedly applies the expression. Use with `limit` as otherwise it will continue until the expression returns no results.",
+		document:       `1`,
+		expression:     `[limit(5; repeat(. * 2))]`,
+		expected: []string{
+			"D0, P[], (!!seq)::- 1\n- 2\n- 4\n- 8\n- 16\n",
+		},
+	},
+	{
+		description: "Repeat until there are no more results",
+		document:    `{name: a, child: {name: b, child: {name: c}}}`,
+		expression:  `[repeat(.child | select(. != null)) | .name]`,
+		expected: []string{
+			"D0, P[], (!!seq)::- a\n- b\n- c\n",
+		},
+	},
+	{
+		skipDoc:    true,
+		document:   `[1, 4]`,
+		expression: `[.[] | until(. == 16; . * 2)]`,
+		expected: []string{
+			"D0, P[], (!!seq)::- 16\n- 16\n",
+		},
+	},
+	{
+		skipDoc:       true,
+		document:      `1`,
+		expression:    `until(. == 16)`,
+		expectedError: "until must be given a block, got EQUALS instead",
+	},
+}
+
+func TestLoopsOperatorScenarios(t *testing.T) {
+	for _, tt := range loopsOperatorScenarios {
+		testScenario(t, &tt)
+	}
+	documentOperatorScenarios(t, "loops", loopsOperatorScenarios)
+}
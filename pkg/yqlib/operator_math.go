@@ -0,0 +1,203 @@
+package yqlib
+
+import (
+	"container/list"
+	"fmt"
+	"math"
+	"strconv"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+// mathFunction describes a function of one number. Ints are passed to forInt when it is set,
+// otherwise they are converted to floats. When roundsToInt is set, float results become ints.
+type mathFunction struct {
+	name        string
+	forInt      func(number int64) int64
+	forFloat    func(number float64) float64
+	roundsToInt bool
+}
+
+var floorFunction = mathFunction{name: "floor", forInt: func(n int64) int64 { return n }, forFloat: math.Floor, roundsToInt: true}
+var ceilFunction = mathFunction{name: "ceil", forInt: func(n int64) int64 { return n }, forFloat: math.Ceil, roundsToInt: true}
+var roundFunction = mathFunction{name: "round", forInt: func(n int64) int64 { return n }, forFloat: math.Round, roundsToInt: true}
+var absFunction = mathFunction{name: "abs", forInt: absInt, forFloat: math.Abs}
+var sqrtFunction = mathFunction{name: "sqrt", forFloat: math.Sqrt}
+var logFunction = mathFunction{name: "log", forFloat: math.Log}
+var expFunction = mathFunction{name: "exp", forFloat: math.Exp}
+
+func absInt(number int64) int64 {
+	if number < 0 {
+		return -number
+	}
+	return number
+}
+
+type parsedNumber struct {
+	isInt      bool
+	intValue   int64
+	floatValue float64
+}
+
+// parseNumber reads the value of an !!int or !!float node. Like add, custom tags are
+// resolved to their underlying type.
+func parseNumber(node *yaml.Node, operatorName string) (parsedNumber, error) {
+	switch resolveTag(node) {
+	case "!!int":
+		_, number, err := parseInt(node.Value)
+		if err != nil {
+			return parsedNumber{}, fmt.Errorf("%v cannot parse '%v' as an integer", operatorName, node.Value)
+		}
+		return parsedNumber{isInt: true, intValue: number, floatValue: float64(number)}, nil
+	case "!!float":
+		number, err := parseFloat(node.Value)
+		if err != nil {
+			return parsedNumber{}, fmt.Errorf("%v cannot parse '%v' as a float", operatorName, node.Value)
+		}
+		return parsedNumber{floatValue: number}, nil
+	}
+	return parsedNumber{}, fmt.Errorf("%v expects a number, got %v", operatorName, node.Tag)
+}
+
+// parseFloat also understands the yaml spellings of infinity and not a number.
+func parseFloat(value string) (float64, error) {
+	switch value {
+	case ".inf", ".Inf", ".INF", "+.inf", "+.Inf", "+.INF":
+		return math.Inf(1), nil
+	case "-.inf", "-.Inf", "-.INF":
+		return math.Inf(-1), nil
+	case ".nan", ".NaN", ".NAN":
+		return math.NaN(), nil
+	}
+	return strconv.ParseFloat(value, 64)
+}
+
+// createNumberNode keeps the custom tag of the original node if the result is still
+// of the same type, the same way add does.
+func createNumberNode(original *yaml.Node, result parsedNumber) *yaml.Node {
+	node := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!float", Value: formatFloat(result.floatValue)}
+	if result.isInt {
+		node.Tag = "!!int"
+		node.Value = strconv.FormatInt(result.intValue, 10)
+	}
+	if original != nil && resolveTag(original) == node.Tag {
+		node.Tag = original.Tag
+	}
+	return node
+}
+
+func (f mathFunction) apply(node *yaml.Node) (*yaml.Node, error) {
+	number, err := parseNumber(node, f.name)
+	if err != nil {
+		return nil, err
+	}
+	if number.isInt && f.forInt != nil {
+		return createNumberNode(node, parsedNumber{isInt: true, intValue: f.forInt(number.intValue)}), nil
+	}
+	result := f.forFloat(number.floatValue)
+	if err := checkFiniteResult(f.name, node.Value, result, number.floatValue); err != nil {
+		return nil, err
+	}
+	if f.roundsToInt && result >= math.MinInt64 && result < math.MaxInt64 {
+		return createNumberNode(node, parsedNumber{isInt: true, intValue: int64(result)}), nil
+	}
+	return createNumberNode(node, parsedNumber{floatValue: result}), nil
+}
+
+// checkFiniteResult errors when a function of finite numbers has no finite result, like sqrt(-1)
+// or log(0), as json can't represent those.
+func checkFiniteResult(operatorName string, description string, result float64, inputs ...float64) error {
+	for _, input := range inputs {
+		if math.IsNaN(input) || math.IsInf(input, 0) {
+			return nil
+		}
+	}
+	if math.IsNaN(result) {
+		return fmt.Errorf("%v is not defined for %v", operatorName, description)
+	} else if math.IsInf(result, 0) {
+		return fmt.Errorf("%v of %v is infinite", operatorName, description)
+	}
+	return nil
+}
+
+func mathOperator(f mathFunction) operatorHandler {
+	return func(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+		log.Debugf("-- mathOperator %v", f.name)
+		results := list.New()
+
+		for el := context.MatchingNodes.Front(); el != nil; el = el.Next() {
+			candidate := el.Value.(*CandidateNode)
+			result, err := f.apply(unwrapDoc(candidate.Node))
+			if err != nil {
+				return Context{}, err
+			}
+			results.PushBack(candidate.CreateReplacement(result))
+		}
+		return context.ChildContext(results), nil
+	}
+}
+
+// getNodeParameter returns the first result of the expression run against the candidate.
+func getNodeParameter(d *dataTreeNavigator, context Context, candidate *CandidateNode, expressionNode *ExpressionNode, operatorName string) (*yaml.Node, error) {
+	result, err := d.GetMatchingNodes(context.SingleReadonlyChildContext(candidate), expressionNode)
+	if err != nil {
+		return nil, err
+	}
+	if result.MatchingNodes.Front() == nil {
+		return nil, fmt.Errorf("%v expects a parameter, but got nothing", operatorName)
+	}
+	return unwrapDoc(result.MatchingNodes.Front().Value.(*CandidateNode).Node), nil
+}
+
+func powOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	log.Debugf("-- powOperator")
+	params := flattenBlock(expressionNode.RHS)
+	if len(params) != 2 {
+		return Context{}, fmt.Errorf("pow expects 2 parameters, got %v", len(params))
+	}
+	results := list.New()
+
+	for el := context.MatchingNodes.Front(); el != nil; el = el.Next() {
+		candidate := el.Value.(*CandidateNode)
+		baseNode, err := getNodeParameter(d, context, candidate, params[0], "pow")
+		if err != nil {
+			return Context{}, err
+		}
+		exponentNode, err := getNodeParameter(d, context, candidate, params[1], "pow")
+		if err != nil {
+			return Context{}, err
+		}
+		base, err := parseNumber(baseNode, "pow")
+		if err != nil {
+			return Context{}, err
+		}
+		exponent, err := parseNumber(exponentNode, "pow")
+		if err != nil {
+			return Context{}, err
+		}
+		result := pow(base, exponent)
+		description := fmt.Sprintf("%v and %v", baseNode.Value, exponentNode.Value)
+		if err := checkFiniteResult("pow", description, result.floatValue, base.floatValue, exponent.floatValue); err != nil {
+			return Context{}, err
+		}
+		results.PushBack(candidate.CreateReplacement(createNumberNode(baseNode, result)))
+	}
+	return context.ChildContext(results), nil
+}
+
+// pow keeps integer results as ints, as long as they fit.
+func pow(base parsedNumber, exponent parsedNumber) parsedNumber {
+	result := math.Pow(base.floatValue, exponent.floatValue)
+	if !base.isInt || !exponent.isInt || exponent.intValue < 0 || math.Abs(result) >= 1<<53 {
+		return parsedNumber{floatValue: result}
+	}
+	intResult := int64(1)
+	factor := base.intValue
+	for remaining := exponent.intValue; remaining > 0; remaining = remaining >> 1 {
+		if remaining&1 == 1 {
+			intResult = intResult * factor
+		}
+		factor = factor * factor
+	}
+	return parsedNumber{isInt: true, intValue: intResult}
+}
@@ -0,0 +1,133 @@
+package yqlib
+
+import (
+	"testing"
+)
+
+var mathOperatorScenarios = []expressionScenario{
+	{
+		description:    "Floor, ceil and round",
+		subdescription: "These always return integers.",
+		document:       `[1.5, -1.5, 2]`,
+		expression:     `[.[] | [floor, ceil, round]]`,
+		expected: []string{
+			"D0, P[], (!!seq)::- - 1\n  - 2\n  - 2\n- - -2\n  - -1\n  - -2\n- - 2\n  - 2\n  - 2\n",
+		},
+	},
+	{
+		description: "Absolute value",
+		document:    `[-3, 2.5, -0.5]`,
+		expression:  `map(abs)`,
+		expected: []string{
+			"D0, P[], (!!seq)::[3, 2.5, 0.5]\n",
+		},
+	},
+	{
+		description:    "Square root, logarithm and exponent",
+		subdescription: "These always return floats. `log` is the natural logarithm.",
+		document:       `{a: 16, b: 1}`,
+		expression:     `(.a | sqrt), (.b | log), (.b | exp | . * 1000 | round)`,
+		expected: []string{
+			"D0, P[a], (!!float)::4.0\n",
+			"D0, P[b], (!!float)::0.0\n",
+			"D0, P[b], (!!int)::2718\n",
+		},
+	},
+	{
+		description:    "Power",
+		subdescription: "Integers raised to a non-negative integer power stay integers.",
+		document:       `{a: 2, b: 10}`,
+		expression:     `pow(.a; .b), pow(.a; -1), pow(9; 0.5)`,
+		expected: []string{
+			"D0, P[], (!!int)::1024\n",
+			"D0, P[], (!!float)::0.5\n",
+			"D0, P[], (!!float)::3.0\n",
+		},
+	},
+	{
+		description: "Power that overflows an integer",
+		document:    `{}`,
+		expression:  `pow(10; 20)`,
+		skipDoc:     true,
+		expected: []string{
+			"D0, P[], (!!float)::100000000000000000000.0\n",
+		},
+	},
+	{
+		description:   "Logarithm of zero",
+		document:      `a: 0`,
+		expression:    `.a | log`,
+		skipDoc:       true,
+		expectedError: "log of 0 is infinite",
+	},
+	{
+		description:   "Square root of a negative number",
+		document:      `a: -4`,
+		expression:    `.a | sqrt`,
+		skipDoc:       true,
+		expectedError: "sqrt is not defined for -4",
+	},
+	{
+		description:   "Power with no real result",
+		document:      `{}`,
+		expression:    `pow(-8; 0.5)`,
+		skipDoc:       true,
+		expectedError: "pow is not defined for -8 and 0.5",
+	},
+	{
+		description: "Infinite input",
+		document:    `a: .inf`,
+		expression:  `.a | log`,
+		skipDoc:     true,
+		expected: []string{
+			"D0, P[a], (!!float)::.inf\n",
+		},
+	},
+	{
+		description:    "Custom tags",
+		subdescription: "Custom tags are kept, as long as the result is still the same type of number.",
+		document:       `{a: !cost 2.5, b: !cost 3}`,
+		expression:     `(.a, .b) | floor`,
+		expected: []string{
+			"D0, P[a], (!!int)::2\n",
+			"D0, P[b], (!cost)::3\n",
+		},
+	},
+	{
+		description: "Hex numbers",
+		document:    `a: 0x10`,
+		expression:  `.a | sqrt`,
+		skipDoc:     true,
+		expected: []string{
+			"D0, P[a], (!!float)::4.0\n",
+		},
+	},
+	{
+		description:   "Non numeric input",
+		document:      `a: cat`,
+		expression:    `.a | floor`,
+		skipDoc:       true,
+		expectedError: "floor expects a number, got !!str",
+	},
+	{
+		description:   "Non numeric custom tag",
+		document:      `a: !thing cat`,
+		expression:    `.a | abs`,
+		skipDoc:       true,
+		expectedError: "abs expects a number, got !thing",
+	},
+	{
+		description:   "Power with a missing parameter",
+		document:      `a: 2`,
+		expression:    `pow(.a; .b)`,
+		skipDoc:       true,
+		expectedError: "pow expects a parameter, but got nothing",
+	},
+}
+
+func TestMathOperatorScenarios(t *testing.T) {
+	for _, tt := range mathOperatorScenarios {
+		testScenario(t, &tt)
+	}
+	documentOperatorScenarios(t, "math", mathOperatorScenarios)
+}
@@ -0,0 +1,109 @@
+package yqlib
+
+import (
+	"container/list"
+	"fmt"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+func minOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	selfExpression := &ExpressionNode{Operation: &Operation{OperationType: selfReferenceOpType}}
+	return minMaxBy(d, context, selfExpression, "min", false)
+}
+
+func maxOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	selfExpression := &ExpressionNode{Operation: &Operation{OperationType: selfReferenceOpType}}
+	return minMaxBy(d, context, selfExpression, "max", true)
+}
+
+func minOfOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	return minMaxOf(d, context, expressionNode, "min", false)
+}
+
+func maxOfOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	return minMaxOf(d, context, expressionNode, "max", true)
+}
+
+func minByOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	return minMaxBy(d, context, expressionNode.RHS, "min_by", false)
+}
+
+func maxByOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	return minMaxBy(d, context, expressionNode.RHS, "max_by", true)
+}
+
+// minMaxBy uses the same ordering as sort. Like jq, if there are several equal
+// elements min returns the first of them and max returns the last.
+func minMaxBy(d *dataTreeNavigator, context Context, compareByExp *ExpressionNode, operatorName string, findMax bool) (Context, error) {
+	log.Debugf("-- %v operator", operatorName)
+
+	results := list.New()
+
+	for el := context.MatchingNodes.Front(); el != nil; el = el.Next() {
+		candidate := el.Value.(*CandidateNode)
+		candidateNode := unwrapDoc(candidate.Node)
+
+		if candidateNode.Kind != yaml.SequenceNode {
+			return Context{}, fmt.Errorf("node at path [%v] is not an array (it's a %v)", candidate.GetNicePath(), candidate.GetNiceTag())
+		}
+
+		if len(candidateNode.Content) == 0 {
+			results.PushBack(candidate.CreateReplacement(&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}))
+			continue
+		}
+
+		sortableArray, err := createSortableNodeArray(d, context, candidate, compareByExp, operatorName)
+		if err != nil {
+			return Context{}, err
+		}
+
+		found := 0
+		for i := 1; i < len(sortableArray); i++ {
+			if findMax && !sortableArray.Less(i, found) {
+				found = i
+			} else if !findMax && sortableArray.Less(i, found) {
+				found = i
+			}
+		}
+		results.PushBack(candidate.CreateChildInArray(found, candidateNode.Content[found]))
+	}
+
+	return context.ChildContext(results), nil
+}
+
+// minMaxOf picks the smaller or bigger of two values, e.g. min(.a; .b), ordering them the same way as min and max.
+func minMaxOf(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode, operatorName string, findMax bool) (Context, error) {
+	log.Debugf("-- %v of operator", operatorName)
+
+	params := flattenBlock(expressionNode.RHS)
+	if len(params) != 2 {
+		return Context{}, fmt.Errorf("%v expects 2 parameters, got %v", operatorName, len(params))
+	}
+	selfExpression := &ExpressionNode{Operation: &Operation{OperationType: selfReferenceOpType}}
+	results := list.New()
+
+	for el := context.MatchingNodes.Front(); el != nil; el = el.Next() {
+		candidate := el.Value.(*CandidateNode)
+		values := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		for _, param := range params {
+			value, err := getNodeParameter(d, context, candidate, param, operatorName)
+			if err != nil {
+				return Context{}, err
+			}
+			values.Content = append(values.Content, value)
+		}
+
+		sortableArray, err := createSortableNodeArray(d, context, &CandidateNode{Node: values}, selfExpression, operatorName)
+		if err != nil {
+			return Context{}, err
+		}
+		found := 0
+		if findMax && !sortableArray.Less(1, 0) || !findMax && sortableArray.Less(1, 0) {
+			found = 1
+		}
+		results.PushBack(candidate.CreateReplacement(values.Content[found]))
+	}
+
+	return context.ChildContext(results), nil
+}
@@ -0,0 +1,116 @@
+package yqlib
+
+import (
+	"testing"
+)
+
+var minMaxOperatorScenarios = []expressionScenario{
+	{
+		description: "Minimum and maximum",
+		document:    `[5, 3.2, 10, 1]`,
+		expression:  `min, max`,
+		expected: []string{
+			"D0, P[3], (!!int)::1\n",
+			"D0, P[2], (!!int)::10\n",
+		},
+	},
+	{
+		description:    "Mixed types",
+		subdescription: "Uses the same ordering as `sort`: nulls, then booleans, then numbers and strings.",
+		document:       `[cat, 4, null, true]`,
+		expression:     `min, max`,
+		expected: []string{
+			"D0, P[2], (!!null)::null\n",
+			"D0, P[0], (!!str)::cat\n",
+		},
+	},
+	{
+		description: "Timestamps",
+		skipDoc:     true,
+		document:    `{t: 2020-01-01T00:00:00+10:00, u: 2019-12-31T20:00:00Z}`,
+		expression:  `.t < .u, ([.t, .u] | min), ([.t, .u] | max_by(.))`,
+		expected: []string{
+			"D0, P[t], (!!bool)::true\n",
+			"D0, P[0], (!!timestamp)::2020-01-01T00:00:00+10:00\n",
+			"D0, P[1], (!!timestamp)::2019-12-31T20:00:00Z\n",
+		},
+	},
+	{
+		description: "Min and max of an empty array",
+		document:    `[]`,
+		expression:  `[min, max]`,
+		expected: []string{
+			"D0, P[], (!!seq)::- null\n- null\n",
+		},
+	},
+	{
+		description: "Minimum by an expression",
+		document:    `[{name: cat, age: 4}, {name: dog, age: 2}, {name: fish, age: 2}]`,
+		expression:  `min_by(.age) | .name`,
+		expected: []string{
+			"D0, P[1 name], (!!str)::dog\n",
+		},
+	},
+	{
+		description:    "Maximum per group",
+		subdescription: "Find the biggest memory limit in each namespace",
+		document:       `[{ns: a, mem: 512}, {ns: b, mem: 128}, {ns: a, mem: 1024}]`,
+		expression:     `group_by(.ns) | map(max_by(.mem))`,
+		expected: []string{
+			"D0, P[], (!!seq)::- {ns: a, mem: 1024}\n- {ns: b, mem: 128}\n",
+		},
+	},
+	{
+		description:    "Minimum and maximum of two values",
+		subdescription: "Useful for clamping numbers",
+		document:       `{replicas: 12, limit: 10}`,
+		expression:     `min(.replicas; .limit), max(.replicas - 20; 0)`,
+		expected: []string{
+			"D0, P[], (!!int)::10\n",
+			"D0, P[], (!!int)::0\n",
+		},
+	},
+	{
+		skipDoc:    true,
+		document:   `{a: 2, b: 2.0}`,
+		expression: `min(.a; .b), max(.a; .b)`,
+		expected: []string{
+			"D0, P[], (!!int)::2\n",
+			"D0, P[], (!!float)::2.0\n",
+		},
+	},
+	{
+		skipDoc:       true,
+		document:      `a: 1`,
+		expression:    `min(.a)`,
+		expectedError: "min expects 2 parameters, got 1",
+	},
+	{
+		skipDoc:    true,
+		document:   `[{a: 1, b: x}, {a: 1, b: y}]`,
+		expression: `min_by(.a).b, max_by(.a).b`,
+		expected: []string{
+			"D0, P[0 b], (!!str)::x\n",
+			"D0, P[1 b], (!!str)::y\n",
+		},
+	},
+	{
+		skipDoc:       true,
+		document:      `[[1], [2]]`,
+		expression:    `min`,
+		expectedError: "min only works for scalars, got !!seq",
+	},
+	{
+		skipDoc:       true,
+		document:      `a: cat`,
+		expression:    `max`,
+		expectedError: "node at path [] is not an array (it's a !!map)",
+	},
+}
+
+func TestMinMaxOperatorScenarios(t *testing.T) {
+	for _, tt := range minMaxOperatorScenarios {
+		testScenario(t, &tt)
+	}
+	documentOperatorScenarios(t, "min-max", minMaxOperatorScenarios)
+}
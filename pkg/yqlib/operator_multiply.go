@@ -13,6 +13,8 @@ import (
 type multiplyPreferences struct {
 	AppendArrays    bool
 	DeepMergeArrays bool
+	MergePatch      bool            // RFC 7396, nulls remove keys and arrays are replaced
+	MergeKey        *ExpressionNode // strategic merge, array items are matched by this key
 	TraversePrefs   traversePreferences
 	AssignPrefs     assignPreferences
 }
@@ -54,6 +56,11 @@ func getComments(lhs *CandidateNode, rhs *CandidateNode) (leadingContent string,
 
 func multiply(preferences multiplyPreferences) func(d *dataTreeNavigator, context Context, lhs *CandidateNode, rhs *CandidateNode) (*CandidateNode, error) {
 	return func(d *dataTreeNavigator, context Context, lhs *CandidateNode, rhs *CandidateNode) (*CandidateNode, error) {
+		if preferences.MergePatch {
+			return mergePatch(lhs, rhs)
+		} else if preferences.MergeKey != nil {
+			return strategicMerge(d, context, lhs, rhs, preferences.MergeKey)
+		}
 		// need to do this before unWrapping the potential document node
 		leadingContent, headComment, footComment := getComments(lhs, rhs)
 		lhs.Node = unwrapDoc(lhs.Node)
@@ -78,6 +85,86 @@ func multiply(preferences multiplyPreferences) func(d *dataTreeNavigator, contex
 	}
 }
 
+// mergePatchAllOperator merges all the matching nodes in order, e.g. all the documents given to eval-all.
+func mergePatchAllOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	log.Debugf("-- mergePatchAllOperator")
+	if context.MatchingNodes.Front() == nil {
+		return context, nil
+	}
+	result := context.MatchingNodes.Front().Value.(*CandidateNode)
+	for el := context.MatchingNodes.Front().Next(); el != nil; el = el.Next() {
+		merged, err := mergePatch(result, el.Value.(*CandidateNode))
+		if err != nil {
+			return Context{}, err
+		}
+		result = merged
+	}
+	return context.SingleChildContext(result), nil
+}
+
+// mergePatch merges the rhs into a copy of the lhs following RFC 7396 (JSON Merge Patch).
+func mergePatch(lhs *CandidateNode, rhs *CandidateNode) (*CandidateNode, error) {
+	return mergeCopies(lhs, rhs, func(target *yaml.Node, patch *yaml.Node) (*yaml.Node, error) {
+		return mergePatchNodes(target, patch), nil
+	})
+}
+
+// mergeCopies merges copies of the lhs and rhs, so neither is changed, keeping the comments like multiply does.
+func mergeCopies(lhs *CandidateNode, rhs *CandidateNode, merge func(target *yaml.Node, patch *yaml.Node) (*yaml.Node, error)) (*CandidateNode, error) {
+	leadingContent, headComment, footComment := getComments(lhs, rhs)
+	target, err := deepCopyNode(unwrapDoc(lhs.Node))
+	if err != nil {
+		return nil, err
+	}
+	patch, err := deepCopyNode(unwrapDoc(rhs.Node))
+	if err != nil {
+		return nil, err
+	}
+	merged, err := merge(target, patch)
+	if err != nil {
+		return nil, err
+	}
+	result := lhs.CreateReplacement(merged)
+	result.LeadingContent = leadingContent
+	if result.Node.Kind == yaml.MappingNode {
+		result.Node.HeadComment = headComment
+		result.Node.FootComment = footComment
+	}
+	return result, nil
+}
+
+func mergePatchNodes(target *yaml.Node, patch *yaml.Node) *yaml.Node {
+	if followAlias(patch).Kind != yaml.MappingNode {
+		if target != nil {
+			keepComments(target, patch)
+		}
+		return patch
+	}
+	patch = followAlias(patch)
+	if target == nil || followAlias(target).Kind != yaml.MappingNode {
+		target = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Style: patch.Style}
+	}
+	target = followAlias(target)
+
+	for index := 0; index < len(patch.Content); index = index + 2 {
+		key := patch.Content[index]
+		value := patch.Content[index+1]
+		indexInTarget := jsonPatchChildIndex(target, key.Value)
+
+		if value.Tag == "!!null" {
+			if indexInTarget != -1 {
+				target.Content = append(target.Content[:indexInTarget-1], target.Content[indexInTarget+1:]...)
+			}
+		} else if indexInTarget == -1 {
+			// merging into nothing still removes any nulls nested in the value
+			target.Content = append(target.Content, key, mergePatchNodes(nil, value))
+		} else {
+			target.Content[indexInTarget] = mergePatchNodes(target.Content[indexInTarget], value)
+		}
+	}
+	return target
+}
+
 func multiplyScalars(lhs *CandidateNode, rhs *CandidateNode) (*CandidateNode, error) {
 	lhsTag := lhs.Node.Tag
 	rhsTag := rhs.Node.Tag
@@ -93,7 +180,9 @@ func multiplyScalars(lhs *CandidateNode, rhs *CandidateNode) (*CandidateNode, er
 		rhsTag = guessTagFromCustomType(rhs.Node)
 	}
 
-	if lhsTag == "!!int" && rhsTag == "!!int" {
+	if lhsTag == "!!str" && rhsTag == "!!int" {
+		return repeatString(lhs, rhs)
+	} else if lhsTag == "!!int" && rhsTag == "!!int" {
 		return multiplyIntegers(lhs, rhs)
 	} else if (lhsTag == "!!int" || lhsTag == "!!float") && (rhsTag == "!!int" || rhsTag == "!!float") {
 		return multiplyFloats(lhs, rhs, lhsIsCustom)
@@ -101,6 +190,23 @@ func multiplyScalars(lhs *CandidateNode, rhs *CandidateNode) (*CandidateNode, er
 	return nil, fmt.Errorf("Cannot multiply %v with %v", lhs.Node.Tag, rhs.Node.Tag)
 }
 
+func repeatString(lhs *CandidateNode, rhs *CandidateNode) (*CandidateNode, error) {
+	_, count, err := parseInt(rhs.Node.Value)
+	if err != nil {
+		return nil, err
+	}
+	if count <= 0 {
+		// same as jq
+		return lhs.CreateReplacement(&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}), nil
+	}
+	target := lhs.CreateReplacement(&yaml.Node{})
+	target.Node.Kind = yaml.ScalarNode
+	target.Node.Style = lhs.Node.Style
+	target.Node.Tag = lhs.Node.Tag
+	target.Node.Value = strings.Repeat(lhs.Node.Value, int(count))
+	return target, nil
+}
+
 func multiplyFloats(lhs *CandidateNode, rhs *CandidateNode, lhsIsCustom bool) (*CandidateNode, error) {
 	target := lhs.CreateReplacement(&yaml.Node{})
 	target.Node.Kind = yaml.ScalarNode
@@ -164,6 +164,16 @@ var multiplyOperatorScenarios = []expressionScenario{
 			"D0, P[], (doc)::a: 12\nb: 4\n",
 		},
 	},
+	{
+		description:    "Repeat a string",
+		subdescription: "Like jq, repeating a string zero times returns null.",
+		document:       "a: ab",
+		expression:     `.a * 3, .a * 0`,
+		expected: []string{
+			"D0, P[a], (!!str)::ababab\n",
+			"D0, P[a], (!!null)::null\n",
+		},
+	},
 	{
 		skipDoc:    true,
 		document:   doc1,
@@ -417,6 +427,63 @@ var multiplyOperatorScenarios = []expressionScenario{
 			"D0, P[a], (!!seq)::[{name: fred, age: 34}, {name: bob, age: 32}]\n",
 		},
 	},
+	{
+		description:    "Merge, using JSON merge patch",
+		subdescription: "Following RFC 7396, a null removes the key and arrays are replaced.",
+		document:       `{a: {b: 1, c: 2, d: [1, 2]}, patch: {b: null, d: [3], e: {f: 1, g: null}}}`,
+		expression:     `.a *p .patch`,
+		expected: []string{
+			"D0, P[a], (!!map)::{c: 2, d: [3], e: {f: 1}}\n",
+		},
+	},
+	{
+		description:    "Merge, using JSON merge patch on other types",
+		subdescription: "Anything other than an object replaces the LHS, and an object replaces anything that isn't an object.",
+		document:       `{a: [1], b: 2, c: {d: 1}}`,
+		expression:     `(.a *p .c), (.c *p 3)`,
+		expected: []string{
+			"D0, P[a], (!!map)::{d: 1}\n",
+			"D0, P[c], (!!int)::3\n",
+		},
+	},
+	{
+		description: "Merge patch keeps comments",
+		skipDoc:     true,
+		document:    "a: cat # pet\nb: 1\n",
+		expression:  `. *p {"a": "dog", "b": null}`,
+		expected: []string{
+			"D0, P[], (!!map)::a: dog # pet\n",
+		},
+	},
+	{
+		description: "Multiply by an operator starting with p",
+		skipDoc:     true,
+		document:    `{}`,
+		expression:  `[1]*path, 2*pow(2;3)`,
+		expected: []string{
+			"D0, P[], (!!seq)::[]\n",
+			"D0, P[], (!!int)::16\n",
+		},
+	},
+	{
+		description: "Merge patch with assignment",
+		skipDoc:     true,
+		document:    `{a: {b: 1, c: 2}}`,
+		expression:  `.a *=p {"b": null}`,
+		expected: []string{
+			"D0, P[], (doc)::{a: {c: 2}}\n",
+		},
+	},
+	{
+		description:    "Merge all documents using JSON merge patch",
+		subdescription: "`merge_patch` merges all the matching documents in order, e.g. `yq ea 'merge_patch' base.yml override.yml` to apply Helm style value overrides.",
+		document:       `{a: {b: 1, c: 2}, d: [1]}`,
+		document2:      `{a: {b: null}, d: [2]}`,
+		expression:     `merge_patch`,
+		expected: []string{
+			"D0, P[], (!!map)::{a: {c: 2}, d: [2]}\n",
+		},
+	},
 	{
 		description:          "Merge arrays of objects together, matching on a key",
 		subdescription:       mergeArraysObjectKeysText,
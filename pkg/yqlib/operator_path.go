@@ -3,7 +3,10 @@ package yqlib
 import (
 	"container/list"
 	"fmt"
+	"sort"
+	"strings"
 
+	"github.com/jinzhu/copier"
 	yaml "gopkg.in/yaml.v3"
 )
 
@@ -37,3 +40,431 @@ func getPathOperator(d *dataTreeNavigator, context Context, expressionNode *Expr
 
 	return context.ChildContext(results), nil
 }
+
+func createPathArrayNode(path []interface{}) *yaml.Node {
+	node := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+	node.Content = make([]*yaml.Node, len(path))
+	for pathIndex, pathElement := range path {
+		node.Content[pathIndex] = createPathNodeFor(pathElement)
+	}
+	return node
+}
+
+// getPathArray converts a path array node (e.g. ["a", 0]) into the path elements,
+// with integers as int64 as they are when parsing properties keys.
+func getPathArray(node *yaml.Node, operatorName string) ([]interface{}, error) {
+	node = unwrapDoc(node)
+	if node.Kind != yaml.SequenceNode {
+		return nil, fmt.Errorf("%v expects a path array, got %v", operatorName, node.Tag)
+	}
+	path := make([]interface{}, len(node.Content))
+	for pathIndex, pathNode := range node.Content {
+		if pathNode.Kind == yaml.ScalarNode && pathNode.Tag == "!!int" {
+			_, number, err := parseInt(pathNode.Value)
+			if err != nil {
+				return nil, err
+			}
+			path[pathIndex] = number
+		} else if isStringNode(pathNode) {
+			path[pathIndex] = pathNode.Value
+		} else {
+			return nil, fmt.Errorf("%v expects path elements to be strings or integers, got %v", operatorName, pathNode.Tag)
+		}
+	}
+	return path, nil
+}
+
+// getValueAtPath finds the node at the given path, without creating it. Returns nil if there is no such node.
+func getValueAtPath(candidate *CandidateNode, path []interface{}) (*CandidateNode, error) {
+	current := candidate
+	for _, pathElement := range path {
+		if current.Node.Kind == yaml.DocumentNode {
+			current = current.CreateChildInMap(nil, current.Node.Content[0])
+		}
+		if current.Node.Kind == yaml.AliasNode {
+			current = current.CreateReplacement(current.Node.Alias)
+		}
+		node := current.Node
+
+		switch node.Kind {
+		case yaml.MappingNode:
+			key, isString := pathElement.(string)
+			if !isString {
+				return nil, fmt.Errorf("cannot index map with %v", pathElement)
+			}
+			var found *CandidateNode
+			for index := 0; index < len(node.Content); index = index + 2 {
+				if node.Content[index].Value == key {
+					found = current.CreateChildInMap(node.Content[index], node.Content[index+1])
+				}
+			}
+			if found == nil {
+				return nil, nil
+			}
+			current = found
+		case yaml.SequenceNode:
+			index, isInt := pathElement.(int64)
+			if !isInt {
+				return nil, fmt.Errorf("cannot index array with '%v'", pathElement)
+			}
+			if index < 0 {
+				index = int64(len(node.Content)) + index
+			}
+			if index < 0 || index >= int64(len(node.Content)) {
+				return nil, nil
+			}
+			current = current.CreateChildInArray(int(index), node.Content[index])
+		default:
+			if node.Tag == "!!null" {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("cannot index %v with '%v'", node.Tag, pathElement)
+		}
+	}
+	return current, nil
+}
+
+// createEmptyRoot creates a null node to set paths on, see fixRootTag.
+func createEmptyRoot(candidate *CandidateNode) *CandidateNode {
+	return candidate.CreateReplacement(&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null"})
+}
+
+// fixRootTag sets the tag of a root created by createEmptyRoot, as traversing
+// clears the tag when it guesses whether the node should be a map or an array.
+func fixRootTag(root *CandidateNode) {
+	switch root.Node.Kind {
+	case yaml.MappingNode:
+		root.Node.Tag = "!!map"
+	case yaml.SequenceNode:
+		root.Node.Tag = "!!seq"
+	}
+}
+
+// setValueAtPath assigns the value at the given path, creating any missing parents along the way.
+func setValueAtPath(d *dataTreeNavigator, context Context, candidate *CandidateNode, path []interface{}, value *CandidateNode) error {
+	assignmentOpNode := &ExpressionNode{
+		Operation: &Operation{OperationType: assignOpType, Preferences: assignPreferences{}},
+		LHS:       createTraversalTree(path, traversePreferences{}, false),
+		RHS:       &ExpressionNode{Operation: &Operation{OperationType: valueOpType, CandidateNode: value}},
+	}
+
+	_, err := d.GetMatchingNodes(context.SingleChildContext(candidate), assignmentOpNode)
+	return err
+}
+
+func getChildCandidates(candidate *CandidateNode) []*CandidateNode {
+	node := unwrapDoc(candidate.Node)
+	var children []*CandidateNode
+	switch node.Kind {
+	case yaml.MappingNode:
+		for index := 0; index < len(node.Content); index = index + 2 {
+			children = append(children, candidate.CreateChildInMap(node.Content[index], node.Content[index+1]))
+		}
+	case yaml.SequenceNode:
+		for index, child := range node.Content {
+			children = append(children, candidate.CreateChildInArray(index, child))
+		}
+	}
+	return children
+}
+
+func collectPaths(d *dataTreeNavigator, context Context, root *CandidateNode, candidate *CandidateNode, include func(child *CandidateNode) (bool, error), results *list.List) error {
+	for _, child := range getChildCandidates(candidate) {
+		includeChild, err := include(child)
+		if err != nil {
+			return err
+		}
+		if includeChild {
+			results.PushBack(root.CreateReplacement(createPathArrayNode(child.Path[len(root.Path):])))
+		}
+		if err := collectPaths(d, context, root, child, include, results); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func getPaths(d *dataTreeNavigator, context Context, include func(child *CandidateNode) (bool, error)) (Context, error) {
+	var results = list.New()
+	for el := context.MatchingNodes.Front(); el != nil; el = el.Next() {
+		candidate := el.Value.(*CandidateNode)
+		if err := collectPaths(d, context, candidate, candidate, include, results); err != nil {
+			return Context{}, err
+		}
+	}
+	return context.ChildContext(results), nil
+}
+
+func pathsOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	log.Debugf("-- pathsOperator")
+	return getPaths(d, context, func(child *CandidateNode) (bool, error) {
+		return true, nil
+	})
+}
+
+func pathsFilterOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	log.Debugf("-- pathsFilterOperator")
+	return getPaths(d, context, func(child *CandidateNode) (bool, error) {
+		return conditionIsTrue(d, context, child, expressionNode.RHS)
+	})
+}
+
+func leafPathsOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	log.Debugf("-- leafPathsOperator")
+	return getPaths(d, context, func(child *CandidateNode) (bool, error) {
+		typeName := getTypeName(child.Node)
+		return typeName != "array" && typeName != "object", nil
+	})
+}
+
+func getValueAtPathOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	log.Debugf("-- getValueAtPathOperator")
+	var results = list.New()
+	for el := context.MatchingNodes.Front(); el != nil; el = el.Next() {
+		candidate := el.Value.(*CandidateNode)
+		pathArrays, err := d.GetMatchingNodes(context.SingleReadonlyChildContext(candidate), expressionNode.RHS)
+		if err != nil {
+			return Context{}, err
+		}
+		for pathEl := pathArrays.MatchingNodes.Front(); pathEl != nil; pathEl = pathEl.Next() {
+			path, err := getPathArray(pathEl.Value.(*CandidateNode).Node, "getpath")
+			if err != nil {
+				return Context{}, err
+			}
+			found, err := getValueAtPath(candidate, path)
+			if err != nil {
+				return Context{}, err
+			}
+			if found == nil {
+				// like jq, missing paths are null
+				found = candidate.CreateReplacement(&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"})
+			}
+			results.PushBack(found)
+		}
+	}
+	return context.ChildContext(results), nil
+}
+
+func setValueAtPathOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	log.Debugf("-- setValueAtPathOperator")
+	// setpath(path; value)
+
+	if expressionNode.RHS.Operation.OperationType != blockOpType {
+		return Context{}, fmt.Errorf("setpath must be given a path and a value, e.g. setpath([\"a\", 0]; 1)")
+	}
+
+	for el := context.MatchingNodes.Front(); el != nil; el = el.Next() {
+		candidate := el.Value.(*CandidateNode)
+		candidateContext := context.SingleReadonlyChildContext(candidate)
+
+		values, err := d.GetMatchingNodes(candidateContext, expressionNode.RHS.RHS)
+		if err != nil {
+			return Context{}, err
+		}
+		if values.MatchingNodes.Front() == nil {
+			continue
+		}
+		value := values.MatchingNodes.Front().Value.(*CandidateNode)
+
+		pathArrays, err := d.GetMatchingNodes(candidateContext, expressionNode.RHS.LHS)
+		if err != nil {
+			return Context{}, err
+		}
+		for pathEl := pathArrays.MatchingNodes.Front(); pathEl != nil; pathEl = pathEl.Next() {
+			path, err := getPathArray(pathEl.Value.(*CandidateNode).Node, "setpath")
+			if err != nil {
+				return Context{}, err
+			}
+			if err := setValueAtPath(d, context, candidate, path, value); err != nil {
+				return Context{}, err
+			}
+		}
+	}
+	return context, nil
+}
+
+// comparePaths orders paths element by element, integers before strings.
+func comparePaths(a []interface{}, b []interface{}) int {
+	for index := 0; index < len(a) && index < len(b); index++ {
+		aInt, aIsInt := a[index].(int64)
+		bInt, bIsInt := b[index].(int64)
+		switch {
+		case aIsInt && bIsInt && aInt != bInt:
+			if aInt < bInt {
+				return -1
+			}
+			return 1
+		case aIsInt && !bIsInt:
+			return -1
+		case !aIsInt && bIsInt:
+			return 1
+		case !aIsInt && !bIsInt:
+			if comparison := strings.Compare(a[index].(string), b[index].(string)); comparison != 0 {
+				return comparison
+			}
+		}
+	}
+	return len(a) - len(b)
+}
+
+func deletePathsOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	log.Debugf("-- deletePathsOperator")
+	var results = list.New()
+
+	for el := context.MatchingNodes.Front(); el != nil; el = el.Next() {
+		candidate := el.Value.(*CandidateNode)
+		pathArrays, err := d.GetMatchingNodes(context.SingleReadonlyChildContext(candidate), expressionNode.RHS)
+		if err != nil {
+			return Context{}, err
+		}
+		if pathArrays.MatchingNodes.Front() == nil {
+			results.PushBack(candidate)
+			continue
+		}
+		pathsNode := unwrapDoc(pathArrays.MatchingNodes.Front().Value.(*CandidateNode).Node)
+		if pathsNode.Kind != yaml.SequenceNode {
+			return Context{}, fmt.Errorf("delpaths expects an array of paths, got %v", pathsNode.Tag)
+		}
+
+		paths := make([][]interface{}, len(pathsNode.Content))
+		for index, pathNode := range pathsNode.Content {
+			paths[index], err = getPathArray(pathNode, "delpaths")
+			if err != nil {
+				return Context{}, err
+			}
+		}
+		// delete from the end first, so the indices of the remaining paths are still valid
+		sort.SliceStable(paths, func(i, j int) bool {
+			return comparePaths(paths[i], paths[j]) > 0
+		})
+
+		result := candidate
+		for _, path := range paths {
+			if len(path) == 0 {
+				result = candidate.CreateReplacement(&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"})
+				break
+			}
+			found, err := getValueAtPath(candidate, path)
+			if err != nil {
+				return Context{}, err
+			}
+			if found == nil {
+				continue
+			}
+			if err := deleteFromParent(found); err != nil {
+				return Context{}, err
+			}
+		}
+		results.PushBack(result)
+	}
+	return context.ChildContext(results), nil
+}
+
+func createStreamEvents(node *yaml.Node, path []interface{}, events []*yaml.Node) []*yaml.Node {
+	node = unwrapDoc(node)
+	if (node.Kind != yaml.MappingNode && node.Kind != yaml.SequenceNode) || len(node.Content) == 0 {
+		// leaf event, [path, value]
+		return append(events, &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Content: []*yaml.Node{createPathArrayNode(path), node}})
+	}
+
+	var lastPath []interface{}
+	if node.Kind == yaml.MappingNode {
+		for index := 0; index < len(node.Content); index = index + 2 {
+			lastPath = append(path[:len(path):len(path)], node.Content[index].Value)
+			events = createStreamEvents(node.Content[index+1], lastPath, events)
+		}
+	} else {
+		for index, child := range node.Content {
+			lastPath = append(path[:len(path):len(path)], int64(index))
+			events = createStreamEvents(child, lastPath, events)
+		}
+	}
+	// closing event, [path of the last child]
+	return append(events, &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Content: []*yaml.Node{createPathArrayNode(lastPath)}})
+}
+
+func toStreamOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	log.Debugf("-- toStreamOperator")
+	var results = list.New()
+	for el := context.MatchingNodes.Front(); el != nil; el = el.Next() {
+		candidate := el.Value.(*CandidateNode)
+		for _, event := range createStreamEvents(candidate.Node, []interface{}{}, nil) {
+			results.PushBack(candidate.CreateReplacement(event))
+		}
+	}
+	return context.ChildContext(results), nil
+}
+
+func fromStreamOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	log.Debugf("-- fromStreamOperator")
+	var results = list.New()
+	for el := context.MatchingNodes.Front(); el != nil; el = el.Next() {
+		candidate := el.Value.(*CandidateNode)
+		events, err := d.GetMatchingNodes(context.SingleReadonlyChildContext(candidate), expressionNode.RHS)
+		if err != nil {
+			return Context{}, err
+		}
+
+		var current *CandidateNode
+		for eventEl := events.MatchingNodes.Front(); eventEl != nil; eventEl = eventEl.Next() {
+			event := unwrapDoc(eventEl.Value.(*CandidateNode).Node)
+			if event.Kind != yaml.SequenceNode || len(event.Content) == 0 || len(event.Content) > 2 {
+				return Context{}, fmt.Errorf("fromstream expects events like [path, leaf] or [path], got %v", event.Tag)
+			}
+			path, err := getPathArray(event.Content[0], "fromstream")
+			if err != nil {
+				return Context{}, err
+			}
+
+			if len(event.Content) == 2 && len(path) == 0 {
+				// a top level scalar (or empty array/map)
+				results.PushBack(candidate.CreateReplacement(event.Content[1]))
+			} else if len(event.Content) == 2 {
+				if current == nil {
+					current = createEmptyRoot(candidate)
+				}
+				if err := setValueAtPath(d, context, current, path, candidate.CreateReplacement(event.Content[1])); err != nil {
+					return Context{}, err
+				}
+			} else if len(path) == 1 && current != nil {
+				// closing event of the top level array/map
+				fixRootTag(current)
+				results.PushBack(current)
+				current = nil
+			}
+		}
+	}
+	return context.ChildContext(results), nil
+}
+
+func pickOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	log.Debugf("-- pickOperator")
+	var results = list.New()
+	for el := context.MatchingNodes.Front(); el != nil; el = el.Next() {
+		candidate := el.Value.(*CandidateNode)
+
+		// the path expression may create missing paths, so run it against a copy
+		var clonedNode yaml.Node
+		if err := copier.CopyWithOption(&clonedNode, candidate.Node, copier.Option{DeepCopy: true}); err != nil {
+			return Context{}, err
+		}
+		clone := candidate.CreateReplacement(&clonedNode)
+
+		picked, err := d.GetMatchingNodes(context.SingleChildContext(clone), expressionNode.RHS)
+		if err != nil {
+			return Context{}, err
+		}
+
+		result := createEmptyRoot(candidate)
+		for pickedEl := picked.MatchingNodes.Front(); pickedEl != nil; pickedEl = pickedEl.Next() {
+			pickedCandidate := pickedEl.Value.(*CandidateNode)
+			path := pickedCandidate.Path[len(clone.Path):]
+			if err := setValueAtPath(d, context, result, path, pickedCandidate); err != nil {
+				return Context{}, err
+			}
+		}
+		fixRootTag(result)
+		results.PushBack(result)
+	}
+	return context.ChildContext(results), nil
+}
@@ -63,6 +63,114 @@ var pathOperatorScenarios = []expressionScenario{
 			"D0, P[a 2], (!!seq)::- path:\n    - a\n    - 2\n  value: frog\n",
 		},
 	},
+	{
+		description:    "Get all paths",
+		subdescription: "Use `paths(f)` to only get the paths of nodes that match `f`, and `leaf_paths` for paths of scalars.",
+		document:       `{a: {b: [cat]}, c: 1}`,
+		expression:     `[paths], [paths(type == "number")], [leaf_paths]`,
+		expected: []string{
+			"D0, P[], (!!seq)::- - a\n- - a\n  - b\n- - a\n  - b\n  - 0\n- - c\n",
+			"D0, P[], (!!seq)::- - c\n",
+			"D0, P[], (!!seq)::- - a\n  - b\n  - 0\n- - c\n",
+		},
+	},
+	{
+		description:    "Get value at path",
+		subdescription: "Missing paths are null",
+		document:       `{a: {b: [cat, dog]}}`,
+		expression:     `getpath(["a", "b", 1]), getpath(["x", "y"])`,
+		expected: []string{
+			"D0, P[a b 1], (!!str)::dog\n",
+			"D0, P[], (!!null)::null\n",
+		},
+	},
+	{
+		description:    "Set value at path",
+		subdescription: "Missing parents are created, integers create arrays.",
+		document:       `{a: cat}`,
+		expression:     `setpath(["b", 0, "c"]; .a)`,
+		expected: []string{
+			"D0, P[], (doc)::{a: cat, b: [{c: cat}]}\n",
+		},
+	},
+	{
+		description:    "Set value at a path built at runtime",
+		subdescription: "e.g. from a properties style key",
+		document:       `{key: a.b, value: frog}`,
+		expression:     `setpath(.key | split("."); .value)`,
+		expected: []string{
+			"D0, P[], (doc)::{key: a.b, value: frog, a: {b: frog}}\n",
+		},
+	},
+	{
+		description: "Delete paths",
+		document:    `{a: [1, 2, 3], b: cat, c: dog}`,
+		expression:  `delpaths([["a", 0], ["a", 2], ["b"]])`,
+		expected: []string{
+			"D0, P[], (doc)::{a: [2], c: dog}\n",
+		},
+	},
+	{
+		skipDoc:    true,
+		document:   `{a: [1, 2, 3]}`,
+		expression: `delpaths([["a", 5], ["b", "c"]])`,
+		expected: []string{
+			"D0, P[], (doc)::{a: [1, 2, 3]}\n",
+		},
+	},
+	{
+		skipDoc:    true,
+		document:   `{a: [1, 2, 3]}`,
+		expression: `delpaths([[]])`,
+		expected: []string{
+			"D0, P[], (!!null)::null\n",
+		},
+	},
+	{
+		description:    "Stream",
+		subdescription: "Converts the document into a stream of [path, leaf] events, closing arrays and maps with a [path] event.",
+		document:       `{a: [1, {b: 2}]}`,
+		expression:     `tostream | @json`,
+		expected: []string{
+			"D0, P[], (!!str)::[[\"a\",0],1]\n",
+			"D0, P[], (!!str)::[[\"a\",1,\"b\"],2]\n",
+			"D0, P[], (!!str)::[[\"a\",1,\"b\"]]\n",
+			"D0, P[], (!!str)::[[\"a\",1]]\n",
+			"D0, P[], (!!str)::[[\"a\"]]\n",
+		},
+	},
+	{
+		description: "From stream",
+		document:    `{a: [1, {b: 2}]}`,
+		expression:  `fromstream(tostream | select(.[0] | join(".") != "a.0"))`,
+		expected: []string{
+			"D0, P[], (!!map)::a:\n    - null\n    - b: 2\n",
+		},
+	},
+	{
+		skipDoc:    true,
+		document:   `cat`,
+		expression: `[tostream], fromstream(tostream)`,
+		expected: []string{
+			"D0, P[], (!!seq)::- - []\n  - cat\n",
+			"D0, P[], (!!str)::cat\n",
+		},
+	},
+	{
+		description:    "Pick",
+		subdescription: "Creates a copy with just the given paths",
+		document:       `{a: {b: 1, c: 2}, d: [3, 4], e: 5}`,
+		expression:     `pick(.a.b, .d[1], .f)`,
+		expected: []string{
+			"D0, P[], (!!map)::a:\n    b: 1\nd:\n    - null\n    - 4\nf: null\n",
+		},
+	},
+	{
+		skipDoc:       true,
+		document:      `{a: cat}`,
+		expression:    `getpath("a")`,
+		expectedError: "getpath expects a path array, got !!str",
+	},
 }
 
 func TestPathOperatorsScenarios(t *testing.T) {
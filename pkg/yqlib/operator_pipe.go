@@ -6,6 +6,10 @@ func pipeOperator(d *dataTreeNavigator, context Context, expressionNode *Express
 	// BUT we still return the original context back (see jq)
 	// https://stedolan.github.io/jq/manual/#Variable/SymbolicBindingOperator:...as$identifier|...
 
+	if isAlternativePatternBinding(expressionNode.LHS) {
+		return pipeAlternativePatternsHandler(d, context, expressionNode)
+	}
+
 	lhs, err := d.GetMatchingNodes(context, expressionNode.LHS)
 	if err != nil {
 		return Context{}, err
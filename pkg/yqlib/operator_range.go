@@ -0,0 +1,122 @@
+package yqlib
+
+import (
+	"container/list"
+	"fmt"
+	"strconv"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+func flattenBlock(expressionNode *ExpressionNode) []*ExpressionNode {
+	if expressionNode.Operation.OperationType == blockOpType {
+		return append(flattenBlock(expressionNode.LHS), flattenBlock(expressionNode.RHS)...)
+	}
+	return []*ExpressionNode{expressionNode}
+}
+
+func getRangeParameter(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (*yaml.Node, error) {
+	result, err := d.GetMatchingNodes(context, expressionNode)
+	if err != nil {
+		return nil, err
+	}
+	if result.MatchingNodes.Front() == nil {
+		return nil, fmt.Errorf("range expects numbers, but got nothing")
+	}
+	node := unwrapDoc(result.MatchingNodes.Front().Value.(*CandidateNode).Node)
+	if node.Kind != yaml.ScalarNode || (node.Tag != "!!int" && node.Tag != "!!float") {
+		return nil, fmt.Errorf("range expects numbers, got %v instead", node.Tag)
+	}
+	return node, nil
+}
+
+// intRange and floatRange stop adding numbers once there are limit results, unless the limit is negative.
+func intRange(candidate *CandidateNode, parameters []*yaml.Node, results *list.List, limit int) error {
+	numbers := make([]int64, len(parameters))
+	for i, parameter := range parameters {
+		_, number, err := parseInt(parameter.Value)
+		if err != nil {
+			return err
+		}
+		numbers[i] = number
+	}
+	from, upto, by := numbers[0], numbers[1], numbers[2]
+	if by == 0 {
+		return fmt.Errorf("range step cannot be zero")
+	}
+	for current := from; ((by > 0 && current < upto) || (by < 0 && current > upto)) && (limit < 0 || results.Len() < limit); current = current + by {
+		results.PushBack(candidate.CreateReplacement(createScalarNode(current, fmt.Sprintf("%v", current))))
+	}
+	return nil
+}
+
+func floatRange(candidate *CandidateNode, parameters []*yaml.Node, results *list.List, limit int) error {
+	numbers := make([]float64, len(parameters))
+	for i, parameter := range parameters {
+		number, err := strconv.ParseFloat(parameter.Value, 64)
+		if err != nil {
+			return err
+		}
+		numbers[i] = number
+	}
+	from, upto, by := numbers[0], numbers[1], numbers[2]
+	if by == 0 {
+		return fmt.Errorf("range step cannot be zero")
+	}
+	for current := from; ((by > 0 && current < upto) || (by < 0 && current > upto)) && (limit < 0 || results.Len() < limit); current = current + by {
+		results.PushBack(candidate.CreateReplacement(createScalarNode(current, formatFloat(current))))
+	}
+	return nil
+}
+
+func rangeOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	log.Debugf("-- rangeOperator")
+	results, err := getRange(d, context, expressionNode, -1)
+	if err != nil {
+		return Context{}, err
+	}
+	return context.ChildContext(results), nil
+}
+
+// getRange returns the numbers of range(upto), range(from; upto) or range(from; upto; by), stopping
+// once there are limit of them when the limit isn't negative.
+func getRange(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode, limit int) (*list.List, error) {
+	parameterExps := flattenBlock(expressionNode.RHS)
+	if len(parameterExps) > 3 {
+		return nil, fmt.Errorf("range expects at most 3 parameters, got %v", len(parameterExps))
+	}
+
+	results := list.New()
+	for el := context.MatchingNodes.Front(); el != nil && (limit < 0 || results.Len() < limit); el = el.Next() {
+		candidate := el.Value.(*CandidateNode)
+
+		// defaults for from and by
+		parameters := []*yaml.Node{createScalarNode(0, "0"), nil, createScalarNode(1, "1")}
+		offset := 0
+		if len(parameterExps) == 1 {
+			offset = 1
+		}
+		for i, parameterExp := range parameterExps {
+			parameter, err := getRangeParameter(d, context.SingleReadonlyChildContext(candidate), parameterExp)
+			if err != nil {
+				return nil, err
+			}
+			parameters[i+offset] = parameter
+		}
+		allInts := true
+		for _, parameter := range parameters {
+			allInts = allInts && parameter.Tag == "!!int"
+		}
+
+		var err error
+		if allInts {
+			err = intRange(candidate, parameters, results, limit)
+		} else {
+			err = floatRange(candidate, parameters, results, limit)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return results, nil
+}
@@ -0,0 +1,80 @@
+package yqlib
+
+import (
+	"testing"
+)
+
+var rangeOperatorScenarios = []expressionScenario{
+	{
+		description: "Range up to a number",
+		expression:  `[range(4)]`,
+		expected: []string{
+			"D0, P[], (!!seq)::- 0\n- 1\n- 2\n- 3\n",
+		},
+	},
+	{
+		description: "Range between two numbers",
+		expression:  `[range(2; 5)]`,
+		expected: []string{
+			"D0, P[], (!!seq)::- 2\n- 3\n- 4\n",
+		},
+	},
+	{
+		description: "Range with a step",
+		expression:  `[range(0; 10; 3)]`,
+		expected: []string{
+			"D0, P[], (!!seq)::- 0\n- 3\n- 6\n- 9\n",
+		},
+	},
+	{
+		description: "Range counting down",
+		expression:  `[range(5; 0; -2)]`,
+		expected: []string{
+			"D0, P[], (!!seq)::- 5\n- 3\n- 1\n",
+		},
+	},
+	{
+		description: "Range of floats",
+		expression:  `[range(0; 1; 0.25)]`,
+		expected: []string{
+			"D0, P[], (!!seq)::- 0.0\n- 0.25\n- 0.5\n- 0.75\n",
+		},
+	},
+	{
+		description: "Range using values from the document",
+		document:    `{from: 1, upto: 3}`,
+		expression:  `[range(.from; .upto)]`,
+		expected: []string{
+			"D0, P[], (!!seq)::- 1\n- 2\n",
+		},
+	},
+	{
+		skipDoc:    true,
+		expression: `[range(5; 2)]`,
+		expected: []string{
+			"D0, P[], (!!seq)::[]\n",
+		},
+	},
+	{
+		skipDoc:       true,
+		expression:    `range(0; 5; 0)`,
+		expectedError: "range step cannot be zero",
+	},
+	{
+		skipDoc:       true,
+		expression:    `range("cat")`,
+		expectedError: "range expects numbers, got !!str instead",
+	},
+	{
+		skipDoc:       true,
+		expression:    `range(1; 2; 3; 4)`,
+		expectedError: "range expects at most 3 parameters, got 4",
+	},
+}
+
+func TestRangeOperatorScenarios(t *testing.T) {
+	for _, tt := range rangeOperatorScenarios {
+		testScenario(t, &tt)
+	}
+	documentOperatorScenarios(t, "range", rangeOperatorScenarios)
+}
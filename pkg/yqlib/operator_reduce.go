@@ -5,6 +5,17 @@ import (
 	"fmt"
 )
 
+func validateReduction(name string, expressionNode *ExpressionNode) error {
+	//ensure lhs is actually an assignment
+	//and rhs is a block (empty)
+	if expressionNode.LHS.Operation.OperationType != assignVariableOpType {
+		return fmt.Errorf("%v must be given a variables assignment, got %v instead", name, expressionNode.LHS.Operation.OperationType.Type)
+	} else if expressionNode.RHS.Operation.OperationType != blockOpType {
+		return fmt.Errorf("%v must be given a block, got %v instead", name, expressionNode.RHS.Operation.OperationType.Type)
+	}
+	return nil
+}
+
 func reduceOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
 	log.Debugf("-- reduceOp")
 	//.a as $var reduce (0; . + $var)
@@ -13,12 +24,8 @@ func reduceOperator(d *dataTreeNavigator, context Context, expressionNode *Expre
 	// '.' refers to the current accumulator, initialised to 0
 	// $var references a single element from the .a
 
-	//ensure lhs is actually an assignment
-	//and rhs is a block (empty)
-	if expressionNode.LHS.Operation.OperationType != assignVariableOpType {
-		return Context{}, fmt.Errorf("reduce must be given a variables assignment, got %v instead", expressionNode.LHS.Operation.OperationType.Type)
-	} else if expressionNode.RHS.Operation.OperationType != blockOpType {
-		return Context{}, fmt.Errorf("reduce must be given a block, got %v instead", expressionNode.RHS.Operation.OperationType.Type)
+	if err := validateReduction("reduce", expressionNode); err != nil {
+		return Context{}, err
 	}
 
 	arrayExpNode := expressionNode.LHS.LHS
@@ -28,7 +35,7 @@ func reduceOperator(d *dataTreeNavigator, context Context, expressionNode *Expre
 		return Context{}, err
 	}
 
-	variableName := expressionNode.LHS.RHS.Operation.StringValue
+	variablePattern := expressionNode.LHS.RHS
 
 	initExp := expressionNode.RHS.LHS
 
@@ -37,15 +44,15 @@ func reduceOperator(d *dataTreeNavigator, context Context, expressionNode *Expre
 		return Context{}, err
 	}
 
-	log.Debugf("with variable %v", variableName)
-
 	blockExp := expressionNode.RHS.RHS
 	for el := array.MatchingNodes.Front(); el != nil; el = el.Next() {
 		candidate := el.Value.(*CandidateNode)
 		log.Debugf("REDUCING WITH %v", NodeToString(candidate))
-		l := list.New()
-		l.PushBack(candidate)
-		accum.SetVariable(variableName, l)
+
+		accum, err = bindPattern(d, accum, variablePattern, candidate)
+		if err != nil {
+			return Context{}, err
+		}
 
 		accum, err = d.GetMatchingNodes(accum, blockExp)
 		if err != nil {
@@ -55,3 +62,60 @@ func reduceOperator(d *dataTreeNavigator, context Context, expressionNode *Expre
 
 	return accum, nil
 }
+
+func foreachOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	log.Debugf("-- foreachOp")
+	// foreach .a as $var (0; . + $var; [$var, .])
+	// like reduce, but the (optional) extract expression
+	// is run against the accumulator after every update.
+
+	if err := validateReduction("foreach", expressionNode); err != nil {
+		return Context{}, err
+	}
+
+	array, err := d.GetMatchingNodes(context, expressionNode.LHS.LHS)
+	if err != nil {
+		return Context{}, err
+	}
+
+	variablePattern := expressionNode.LHS.RHS
+
+	initExp := expressionNode.RHS.LHS
+	updateExp := expressionNode.RHS.RHS
+	var extractExp *ExpressionNode
+
+	if updateExp.Operation.OperationType == blockOpType {
+		extractExp = updateExp.RHS
+		updateExp = updateExp.LHS
+	}
+
+	accum, err := d.GetMatchingNodes(context, initExp)
+	if err != nil {
+		return Context{}, err
+	}
+
+	var results = list.New()
+
+	for el := array.MatchingNodes.Front(); el != nil; el = el.Next() {
+		candidate := el.Value.(*CandidateNode)
+		log.Debugf("FOREACH WITH %v", NodeToString(candidate))
+
+		accum, err = bindPattern(d, accum, variablePattern, candidate)
+		if err != nil {
+			return Context{}, err
+		}
+
+		accum, err = d.GetMatchingNodes(accum, updateExp)
+		if err != nil {
+			return Context{}, err
+		}
+
+		extracted, err := d.GetMatchingNodes(accum.ReadOnlyClone(), extractExp)
+		if err != nil {
+			return Context{}, err
+		}
+		results.PushBackList(extracted.MatchingNodes)
+	}
+
+	return context.ChildContext(results), nil
+}
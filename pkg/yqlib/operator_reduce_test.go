@@ -30,6 +30,54 @@ var reduceOperatorScenarios = []expressionScenario{
 			"D0, P[], (!!map)::Cathy: apples\nBob: bananas\n",
 		},
 	},
+	{
+		description: "Sum numbers using jq syntax",
+		document:    `[10,2, 5, 3]`,
+		expression:  `reduce .[] as $item (0; . + $item)`,
+		expected: []string{
+			"D0, P[], (!!int)::20\n",
+		},
+	},
+	{
+		description:    "Destructure array elements",
+		subdescription: "Each element is matched against the array pattern, missing elements are bound to null.",
+		document:       `[[a, 1], [b, 2], [c]]`,
+		expression:     `reduce .[] as [$key, $value] ({}; .[$key] = $value)`,
+		expected: []string{
+			"D0, P[], (!!map)::a: 1\nb: 2\nc: null\n",
+		},
+	},
+	{
+		description: "Destructure map elements",
+		document:    `[{name: Cathy, has: {fruit: apples}},{name: Bob, has: {fruit: bananas}}]`,
+		expression:  `reduce .[] as {name: $n, "has": {"fruit": $f}} ({}; .[$n] = $f)`,
+		expected: []string{
+			"D0, P[], (!!map)::Cathy: apples\nBob: bananas\n",
+		},
+	},
+	{
+		description: "Destructure map elements by variable name",
+		document:    `[{name: Cathy}, {name: Bob}]`,
+		expression:  `.[] as {$name} ireduce (""; . + $name)`,
+		expected: []string{
+			"D0, P[], (!!str)::CathyBob\n",
+		},
+	},
+	{
+		description: "Reduce nested in an expression",
+		skipDoc:     true,
+		document:    `{a: [1, 2], b: [3, 4]}`,
+		expression:  `{"total": (.b | reduce .[] as $x (.[0]; . * $x)), "first": .a[0]} | .total + .first`,
+		expected: []string{
+			"D0, P[total], (!!int)::37\n",
+		},
+	},
+	{
+		skipDoc:       true,
+		document:      `[1, 2]`,
+		expression:    `reduce .[] as [$a] (0; . + $a)`,
+		expectedError: "cannot destructure !!int as an array",
+	},
 }
 
 func TestReduceOperatorScenarios(t *testing.T) {
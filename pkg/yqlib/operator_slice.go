@@ -0,0 +1,162 @@
+package yqlib
+
+import (
+	"container/list"
+	"fmt"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+func getSliceIndex(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode, defaultValue int, length int) (int, error) {
+	result, err := d.GetMatchingNodes(context.ReadOnlyClone(), expressionNode)
+	if err != nil {
+		return 0, err
+	}
+	if result.MatchingNodes.Front() == nil {
+		return defaultValue, nil
+	}
+	node := unwrapDoc(result.MatchingNodes.Front().Value.(*CandidateNode).Node)
+	if node.Tag == "!!null" {
+		return defaultValue, nil
+	} else if node.Kind != yaml.ScalarNode || node.Tag != "!!int" {
+		return 0, fmt.Errorf("cannot slice with %v, slice indices must be integers", node.Tag)
+	}
+	_, index, err := parseInt(node.Value)
+	if err != nil {
+		return 0, err
+	}
+	relativeIndex := int(index)
+	if relativeIndex < 0 {
+		relativeIndex = length + relativeIndex
+	}
+	if relativeIndex < 0 {
+		return 0, nil
+	} else if relativeIndex > length {
+		return length, nil
+	}
+	return relativeIndex, nil
+}
+
+func getSliceRange(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode, length int) (int, int, error) {
+	from, err := getSliceIndex(d, context, expressionNode.RHS.LHS, 0, length)
+	if err != nil {
+		return 0, 0, err
+	}
+	to, err := getSliceIndex(d, context, expressionNode.RHS.RHS, length, length)
+	if err != nil {
+		return 0, 0, err
+	}
+	if to < from {
+		to = from
+	}
+	return from, to, nil
+}
+
+func isSliceExpression(expressionNode *ExpressionNode) bool {
+	return expressionNode != nil && expressionNode.Operation != nil && expressionNode.Operation.OperationType == sliceOpType
+}
+
+// updateSlices replaces the items of each array sliced by the expression, e.g. `.a[1:3]`, with the array
+// getValue returns for that slice. Slices are copies, so updating them like other nodes would do nothing.
+func updateSlices(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode, getValue func(slice *CandidateNode) (*CandidateNode, error)) error {
+	lhs, err := d.GetMatchingNodes(context, expressionNode.LHS)
+	if err != nil {
+		return err
+	}
+	for el := lhs.MatchingNodes.Front(); el != nil; el = el.Next() {
+		candidate := el.Value.(*CandidateNode)
+		node := unwrapDoc(candidate.Node)
+		if node.Tag != "!!null" && node.Kind != yaml.SequenceNode {
+			return fmt.Errorf("cannot update a slice of %v, only slices of arrays can be updated", node.Tag)
+		}
+
+		from, to, err := getSliceRange(d, context, expressionNode, len(node.Content))
+		if err != nil {
+			return err
+		}
+		slicedNode := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Style: node.Style}
+		slicedNode.Content = make([]*yaml.Node, to-from)
+		copy(slicedNode.Content, node.Content[from:to])
+
+		value, err := getValue(candidate.CreateReplacement(slicedNode))
+		if err != nil {
+			return err
+		} else if value == nil {
+			continue
+		}
+		valueNode := unwrapDoc(value.Node)
+		if valueNode.Kind != yaml.SequenceNode {
+			return fmt.Errorf("a slice can only be updated with an array, got %v", valueNode.Tag)
+		}
+
+		content := make([]*yaml.Node, 0, len(node.Content)-(to-from)+len(valueNode.Content))
+		content = append(content, node.Content[:from]...)
+		content = append(content, valueNode.Content...)
+		content = append(content, node.Content[to:]...)
+		if node.Tag == "!!null" {
+			node.Kind = yaml.SequenceNode
+			node.Tag = "!!seq"
+			node.Value = ""
+		}
+		node.Content = content
+	}
+	return nil
+}
+
+// firstResult returns the first result of the expression, or nil if there are none.
+func firstResult(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (*CandidateNode, error) {
+	result, err := d.GetMatchingNodes(context, expressionNode)
+	if err != nil || result.MatchingNodes.Front() == nil {
+		return nil, err
+	}
+	return result.MatchingNodes.Front().Value.(*CandidateNode), nil
+}
+
+func sliceOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	log.Debugf("-- sliceOperator")
+	// .[from:to] is parsed as . SLICE (from; to)
+
+	lhs, err := d.GetMatchingNodes(context, expressionNode.LHS)
+	if err != nil {
+		return Context{}, err
+	}
+
+	var results = list.New()
+
+	for el := lhs.MatchingNodes.Front(); el != nil; el = el.Next() {
+		candidate := el.Value.(*CandidateNode)
+		node := unwrapDoc(candidate.Node)
+
+		var length int
+		var codePoints []rune
+		switch {
+		case node.Tag == "!!null":
+			results.PushBack(candidate)
+			continue
+		case node.Kind == yaml.SequenceNode:
+			length = len(node.Content)
+		case isStringNode(node):
+			codePoints = []rune(node.Value)
+			length = len(codePoints)
+		default:
+			return Context{}, fmt.Errorf("cannot slice %v, can only slice arrays and strings", node.Tag)
+		}
+
+		from, to, err := getSliceRange(d, context, expressionNode, length)
+		if err != nil {
+			return Context{}, err
+		}
+
+		if node.Kind == yaml.SequenceNode {
+			slicedNode := &yaml.Node{Kind: yaml.SequenceNode, Tag: node.Tag, Style: node.Style}
+			slicedNode.Content = make([]*yaml.Node, to-from)
+			copy(slicedNode.Content, node.Content[from:to])
+			results.PushBack(candidate.CreateReplacement(slicedNode))
+		} else {
+			slicedNode := &yaml.Node{Kind: yaml.ScalarNode, Tag: node.Tag, Style: node.Style, Value: string(codePoints[from:to])}
+			results.PushBack(candidate.CreateReplacement(slicedNode))
+		}
+	}
+
+	return context.ChildContext(results), nil
+}
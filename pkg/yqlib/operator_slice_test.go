@@ -0,0 +1,133 @@
+package yqlib
+
+import (
+	"testing"
+)
+
+var sliceOperatorScenarios = []expressionScenario{
+	{
+		description: "Slice an array",
+		document:    `[a, b, c, d, e]`,
+		expression:  `.[1:3]`,
+		expected: []string{
+			"D0, P[], (!!seq)::[b, c]\n",
+		},
+	},
+	{
+		description:    "Slice with negative and missing indices",
+		subdescription: "Negative indices count from the end, a missing index means the start or end of the array.",
+		document:       `[a, b, c, d, e]`,
+		expression:     `.[-2:], .[:2]`,
+		expected: []string{
+			"D0, P[], (!!seq)::[d, e]\n",
+			"D0, P[], (!!seq)::[a, b]\n",
+		},
+	},
+	{
+		description: "Slice a nested array",
+		document:    `{a: {b: [1, 2, 3]}}`,
+		expression:  `.a.b[1:]`,
+		expected: []string{
+			"D0, P[a b], (!!seq)::[2, 3]\n",
+		},
+	},
+	{
+		description:    "Slice a string",
+		subdescription: "Strings are sliced by unicode code points",
+		document:       `a: héllo world`,
+		expression:     `.a[2:5]`,
+		expected: []string{
+			"D0, P[a], (!!str)::llo\n",
+		},
+	},
+	{
+		description: "Slice using expressions",
+		document:    `{from: 1, items: [a, b, c]}`,
+		expression:  `.items[.from:.from + 1]`,
+		expected: []string{
+			"D0, P[items], (!!seq)::[b]\n",
+		},
+	},
+	{
+		skipDoc:    true,
+		document:   `[a, b, c]`,
+		expression: `.[2:1], .[5:10], .[1:2][0]`,
+		expected: []string{
+			"D0, P[], (!!seq)::[]\n",
+			"D0, P[], (!!seq)::[]\n",
+			"D0, P[0], (!!str)::b\n",
+		},
+	},
+	{
+		description:    "Assign to a slice",
+		subdescription: "The items in the slice are replaced with the items of the array.",
+		document:       `[a, b, c, d]`,
+		expression:     `.[1:3] = ["x"]`,
+		expected: []string{
+			"D0, P[], (doc)::[a, x, d]\n",
+		},
+	},
+	{
+		description: "Update a slice",
+		document:    `[1, 2, 3, 4]`,
+		expression:  `.[1:3] |= map(. * 10)`,
+		expected: []string{
+			"D0, P[], (doc)::[1, 20, 30, 4]\n",
+		},
+	},
+	{
+		skipDoc:    true,
+		document:   `{a: [1, 2, 3]}`,
+		expression: `.a[:1] += ["x"]`,
+		expected: []string{
+			"D0, P[], (doc)::{a: [1, x, 2, 3]}\n",
+		},
+	},
+	{
+		skipDoc:    true,
+		document:   `{a: [1, 2, 3]}`,
+		expression: `.b[1:] = ["x"]`,
+		expected: []string{
+			"D0, P[], (doc)::{a: [1, 2, 3], b: [x]}\n",
+		},
+	},
+	{
+		skipDoc:       true,
+		document:      `{a: [1, 2, 3]}`,
+		expression:    `.a[1:] = "x"`,
+		expectedError: "a slice can only be updated with an array, got !!str",
+	},
+	{
+		skipDoc:       true,
+		document:      `{a: cat}`,
+		expression:    `.a[1:] = ["x"]`,
+		expectedError: "cannot update a slice of !!str, only slices of arrays can be updated",
+	},
+	{
+		skipDoc:    true,
+		document:   `{a: [1, 2, 3]}`,
+		expression: `.a[1:] | length`,
+		expected: []string{
+			"D0, P[a], (!!int)::2\n",
+		},
+	},
+	{
+		skipDoc:       true,
+		document:      `{a: 3}`,
+		expression:    `.a[1:]`,
+		expectedError: "cannot slice !!int, can only slice arrays and strings",
+	},
+	{
+		skipDoc:       true,
+		document:      `[1, 2]`,
+		expression:    `.["a":]`,
+		expectedError: "cannot slice with !!str, slice indices must be integers",
+	},
+}
+
+func TestSliceOperatorScenarios(t *testing.T) {
+	for _, tt := range sliceOperatorScenarios {
+		testScenario(t, &tt)
+	}
+	documentOperatorScenarios(t, "slice", sliceOperatorScenarios)
+}
@@ -31,29 +31,9 @@ func sortByOperator(d *dataTreeNavigator, context Context, expressionNode *Expre
 			return context, fmt.Errorf("node at path [%v] is not an array (it's a %v)", candidate.GetNicePath(), candidate.GetNiceTag())
 		}
 
-		sortableArray := make(sortableNodeArray, len(candidateNode.Content))
-
-		for i, originalNode := range candidateNode.Content {
-
-			childCandidate := candidate.CreateChildInArray(i, originalNode)
-			compareContext, err := d.GetMatchingNodes(context.SingleReadonlyChildContext(childCandidate), expressionNode.RHS)
-			if err != nil {
-				return Context{}, err
-			}
-
-			nodeToCompare := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null"}
-			if compareContext.MatchingNodes.Len() > 0 {
-				nodeToCompare = compareContext.MatchingNodes.Front().Value.(*CandidateNode).Node
-			}
-
-			log.Debug("going to compare %v by %v", NodeToString(candidate.CreateReplacement(originalNode)), NodeToString(candidate.CreateReplacement(nodeToCompare)))
-
-			sortableArray[i] = sortableNode{Node: originalNode, NodeToCompare: nodeToCompare}
-
-			if nodeToCompare.Kind != yaml.ScalarNode {
-				return Context{}, fmt.Errorf("sort only works for scalars, got %v", nodeToCompare.Tag)
-			}
-
+		sortableArray, err := createSortableNodeArray(d, context, candidate, expressionNode.RHS, "sort")
+		if err != nil {
+			return Context{}, err
 		}
 
 		sort.Stable(sortableArray)
@@ -69,6 +49,36 @@ func sortByOperator(d *dataTreeNavigator, context Context, expressionNode *Expre
 	return context.ChildContext(results), nil
 }
 
+// createSortableNodeArray pairs each element of the array with the (scalar) result of the given expression to compare by.
+func createSortableNodeArray(d *dataTreeNavigator, context Context, candidate *CandidateNode, compareByExp *ExpressionNode, operatorName string) (sortableNodeArray, error) {
+	candidateNode := unwrapDoc(candidate.Node)
+	sortableArray := make(sortableNodeArray, len(candidateNode.Content))
+
+	for i, originalNode := range candidateNode.Content {
+
+		childCandidate := candidate.CreateChildInArray(i, originalNode)
+		compareContext, err := d.GetMatchingNodes(context.SingleReadonlyChildContext(childCandidate), compareByExp)
+		if err != nil {
+			return nil, err
+		}
+
+		nodeToCompare := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null"}
+		if compareContext.MatchingNodes.Len() > 0 {
+			nodeToCompare = compareContext.MatchingNodes.Front().Value.(*CandidateNode).Node
+		}
+
+		log.Debug("going to compare %v by %v", NodeToString(candidate.CreateReplacement(originalNode)), NodeToString(candidate.CreateReplacement(nodeToCompare)))
+
+		sortableArray[i] = sortableNode{Node: originalNode, NodeToCompare: nodeToCompare}
+
+		if nodeToCompare.Kind != yaml.ScalarNode {
+			return nil, fmt.Errorf("%v only works for scalars, got %v", operatorName, nodeToCompare.Tag)
+		}
+
+	}
+	return sortableArray, nil
+}
+
 type sortableNode struct {
 	Node          *yaml.Node
 	NodeToCompare *yaml.Node
@@ -103,6 +113,12 @@ func (a sortableNodeArray) Less(i, j int) bool {
 		}
 
 		return !lhsTruthy && rhsTruthy
+	} else if lhs.Tag == "!!timestamp" && rhs.Tag == "!!timestamp" {
+		// like <, timestamps are ordered by time rather than by how they are written
+		if result, err := compareScalars(lhs, rhs); err == nil {
+			return result < 0
+		}
+		return strings.Compare(lhs.Value, rhs.Value) < 0
 	} else if lhs.Tag != rhs.Tag || lhs.Tag == "!!str" {
 		return strings.Compare(lhs.Value, rhs.Value) < 0
 	} else if lhs.Tag == "!!int" && rhs.Tag == "!!int" {
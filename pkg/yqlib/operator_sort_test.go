@@ -70,6 +70,15 @@ var sortByOperatorScenarios = []expressionScenario{
 			"D0, P[], (!!seq)::[{a: false, b: 1}, {a: false, b: 3}, {a: true, b: 2}]\n",
 		},
 	},
+	{
+		skipDoc:     true,
+		description: "timestamps are sorted by time",
+		document:    "[2020-01-01T00:00:00+10:00, 2019-12-31T20:00:00Z]",
+		expression:  `sort`,
+		expected: []string{
+			"D0, P[], (!!seq)::['2020-01-01T00:00:00+10:00', '2019-12-31T20:00:00Z']\n",
+		},
+	},
 }
 
 func TestSortByOperatorScenarios(t *testing.T) {
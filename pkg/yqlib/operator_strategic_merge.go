@@ -0,0 +1,204 @@
+package yqlib
+
+import (
+	"container/list"
+	"fmt"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+// patchDirectiveKey is the kustomize style directive, e.g. `$patch: delete`, that can be given
+// in maps and array items of the patch.
+const patchDirectiveKey = "$patch"
+
+func strategicMergeOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	log.Debugf("-- strategicMergeOperator")
+	params := flattenBlock(expressionNode.RHS)
+	if len(params) != 2 {
+		return Context{}, fmt.Errorf("strategic_merge expects 2 parameters, a patch and a key expression, got %v", len(params))
+	}
+	prefs := multiplyPreferences{MergeKey: params[1]}
+	results := list.New()
+
+	for el := context.MatchingNodes.Front(); el != nil; el = el.Next() {
+		candidate := el.Value.(*CandidateNode)
+		patch, err := getNodeParameter(d, context, candidate, params[0], "strategic_merge")
+		if err != nil {
+			return Context{}, err
+		}
+		result, err := multiply(prefs)(d, context, candidate, candidate.CreateReplacement(patch))
+		if err != nil {
+			return Context{}, err
+		}
+		results.PushBack(result)
+	}
+	return context.ChildContext(results), nil
+}
+
+func strategicMerge(d *dataTreeNavigator, context Context, lhs *CandidateNode, rhs *CandidateNode, key *ExpressionNode) (*CandidateNode, error) {
+	merger := &strategicMerger{d: d, context: context, key: key}
+	return mergeCopies(lhs, rhs, merger.mergeNodes)
+}
+
+type strategicMerger struct {
+	d       *dataTreeNavigator
+	context Context
+	key     *ExpressionNode
+}
+
+func getPatchDirective(node *yaml.Node) string {
+	if node.Kind != yaml.MappingNode {
+		return ""
+	}
+	if index := jsonPatchChildIndex(node, patchDirectiveKey); index != -1 {
+		return node.Content[index].Value
+	}
+	return ""
+}
+
+// removePatchDirectives removes any directives from the node and its children, for values that have nothing to merge with.
+func removePatchDirectives(node *yaml.Node) *yaml.Node {
+	if node.Kind == yaml.MappingNode {
+		content := make([]*yaml.Node, 0, len(node.Content))
+		for index := 0; index < len(node.Content); index = index + 2 {
+			if node.Content[index].Value != patchDirectiveKey {
+				content = append(content, node.Content[index], removePatchDirectives(node.Content[index+1]))
+			}
+		}
+		node.Content = content
+	} else if node.Kind == yaml.SequenceNode {
+		content := make([]*yaml.Node, 0, len(node.Content))
+		for _, item := range node.Content {
+			if getPatchDirective(item) == "" || len(item.Content) > 2 {
+				content = append(content, removePatchDirectives(item))
+			}
+		}
+		node.Content = content
+	}
+	return node
+}
+
+func (m *strategicMerger) mergeNodes(target *yaml.Node, patch *yaml.Node) (*yaml.Node, error) {
+	target = followAlias(target)
+	patch = followAlias(patch)
+
+	if target.Kind == yaml.MappingNode && patch.Kind == yaml.MappingNode && getPatchDirective(patch) != "replace" {
+		return m.mergeMaps(target, patch)
+	} else if target.Kind == yaml.SequenceNode && patch.Kind == yaml.SequenceNode {
+		return m.mergeSequences(target, patch)
+	}
+	keepComments(target, patch)
+	return removePatchDirectives(patch), nil
+}
+
+func (m *strategicMerger) mergeMaps(target *yaml.Node, patch *yaml.Node) (*yaml.Node, error) {
+	for index := 0; index < len(patch.Content); index = index + 2 {
+		key := patch.Content[index]
+		value := followAlias(patch.Content[index+1])
+		if key.Value == patchDirectiveKey {
+			continue
+		}
+		indexInTarget := jsonPatchChildIndex(target, key.Value)
+
+		if getPatchDirective(value) == "delete" {
+			if indexInTarget != -1 {
+				target.Content = append(target.Content[:indexInTarget-1], target.Content[indexInTarget+1:]...)
+			}
+		} else if indexInTarget == -1 {
+			target.Content = append(target.Content, key, removePatchDirectives(value))
+		} else {
+			merged, err := m.mergeNodes(target.Content[indexInTarget], value)
+			if err != nil {
+				return nil, err
+			}
+			target.Content[indexInTarget] = merged
+		}
+	}
+	return target, nil
+}
+
+// getKey returns the key of an array item, or nil if it doesn't have one.
+func (m *strategicMerger) getKey(item *yaml.Node) (*yaml.Node, error) {
+	if followAlias(item).Kind != yaml.MappingNode {
+		return nil, nil
+	}
+	result, err := m.d.GetMatchingNodes(m.context.SingleReadonlyChildContext(&CandidateNode{Node: item}), m.key)
+	if err != nil {
+		return nil, err
+	}
+	if result.MatchingNodes.Front() == nil {
+		return nil, nil
+	}
+	key := unwrapDoc(result.MatchingNodes.Front().Value.(*CandidateNode).Node)
+	if key.Tag == "!!null" {
+		return nil, nil
+	}
+	return key, nil
+}
+
+func (m *strategicMerger) findItem(target *yaml.Node, key *yaml.Node) (int, error) {
+	for index, item := range target.Content {
+		itemKey, err := m.getKey(item)
+		if err != nil {
+			return -1, err
+		}
+		if itemKey != nil && recursiveNodeEqual(itemKey, key) {
+			return index, nil
+		}
+	}
+	return -1, nil
+}
+
+// mergeSequences merges the items with the same key, and appends the rest. If none of the patch
+// items have a key (e.g. an array of strings) the array is replaced, like multiply does.
+func (m *strategicMerger) mergeSequences(target *yaml.Node, patch *yaml.Node) (*yaml.Node, error) {
+	keys := make([]*yaml.Node, len(patch.Content))
+	hasKeys := false
+	for index, item := range patch.Content {
+		if getPatchDirective(item) == "replace" && len(item.Content) == 2 {
+			// a `- $patch: replace` item replaces the whole array
+			return removePatchDirectives(patch), nil
+		}
+		key, err := m.getKey(item)
+		if err != nil {
+			return nil, err
+		}
+		keys[index] = key
+		hasKeys = hasKeys || key != nil
+	}
+	if !hasKeys {
+		return removePatchDirectives(patch), nil
+	}
+
+	for index, item := range patch.Content {
+		if keys[index] == nil {
+			target.Content = append(target.Content, removePatchDirectives(item))
+			continue
+		}
+		indexInTarget, err := m.findItem(target, keys[index])
+		if err != nil {
+			return nil, err
+		}
+		directive := getPatchDirective(item)
+
+		if directive == "delete" {
+			if indexInTarget != -1 {
+				target.Content = append(target.Content[:indexInTarget], target.Content[indexInTarget+1:]...)
+			}
+		} else if indexInTarget == -1 || directive == "replace" {
+			item = removePatchDirectives(item)
+			if indexInTarget == -1 {
+				target.Content = append(target.Content, item)
+			} else {
+				target.Content[indexInTarget] = item
+			}
+		} else {
+			merged, err := m.mergeNodes(target.Content[indexInTarget], item)
+			if err != nil {
+				return nil, err
+			}
+			target.Content[indexInTarget] = merged
+		}
+	}
+	return target, nil
+}
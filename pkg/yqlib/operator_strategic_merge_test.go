@@ -0,0 +1,90 @@
+package yqlib
+
+import (
+	"testing"
+)
+
+var strategicMergeOperatorScenarios = []expressionScenario{
+	{
+		description:    "Merge arrays of objects by key",
+		subdescription: "Items with the same key are deeply merged, the rest are appended.",
+		document:       `{a: [{name: app, image: "app:1", ports: [80]}, {name: sidecar, image: "side:1"}], b: [{name: app, image: "app:2"}, {name: new, image: "new:1"}]}`,
+		expression:     `.b as $patch | .a | strategic_merge($patch; .name)`,
+		expected: []string{
+			"D0, P[a], (!!seq)::[{name: app, image: \"app:2\", ports: [80]}, {name: sidecar, image: \"side:1\"}, {name: new, image: \"new:1\"}]\n",
+		},
+	},
+	{
+		description:    "Merge nested arrays by key",
+		subdescription: "The key expression is used for every array. Arrays without keys, like `args` here, are replaced.",
+		document: `spec:
+  containers:
+    - name: app
+      args: [--a]
+      env:
+        - {name: A, value: "1"}
+        - {name: B, value: "2"}
+patch:
+  spec:
+    containers:
+      - name: app
+        args: [--b]
+        env:
+          - {name: B, value: "3"}
+`,
+		expression: `strategic_merge(.patch; .name) | del(.patch)`,
+		expected: []string{
+			"D0, P[], (!!map)::spec:\n    containers:\n        - name: app\n          args: [--b]\n          env:\n            - {name: A, value: \"1\"}\n            - {name: B, value: \"3\"}\n",
+		},
+	},
+	{
+		description:    "Use different keys",
+		subdescription: "Use the alternative operator to match on whichever key the items have.",
+		document:       `{a: {ports: [{containerPort: 80, protocol: TCP}], volumes: [{name: data}]}, b: {ports: [{containerPort: 80, protocol: UDP}], volumes: [{name: data, emptyDir: {}}]}}`,
+		expression:     `.b as $patch | .a | strategic_merge($patch; .name // .containerPort)`,
+		expected: []string{
+			"D0, P[a], (!!map)::{ports: [{containerPort: 80, protocol: UDP}], volumes: [{name: data, emptyDir: {}}]}\n",
+		},
+	},
+	{
+		description:    "Delete items",
+		subdescription: "Like kustomize, `$patch: delete` removes the matching item or key.",
+		document:       `{a: {items: [{name: x}, {name: y}], config: {c: 1}, d: 1}, b: {items: [{name: x, $patch: delete}], config: {$patch: delete}}}`,
+		expression:     `.b as $patch | .a | strategic_merge($patch; .name)`,
+		expected: []string{
+			"D0, P[a], (!!map)::{items: [{name: y}], d: 1}\n",
+		},
+	},
+	{
+		description:    "Replace items",
+		subdescription: "`$patch: replace` replaces the matching item or map instead of merging it. A `- $patch: replace` item replaces the whole array.",
+		document:       `{a: {items: [{name: x, v: 1, w: 1}], config: {c: 1, d: 1}, list: [{name: l1}]}, b: {items: [{name: x, v: 2, $patch: replace}], config: {c: 2, $patch: replace}, list: [{$patch: replace}, {name: l2}]}}`,
+		expression:     `.b as $patch | .a | strategic_merge($patch; .name)`,
+		expected: []string{
+			"D0, P[a], (!!map)::{items: [{name: x, v: 2}], config: {c: 2}, list: [{name: l2}]}\n",
+		},
+	},
+	{
+		description:    "Merge another file",
+		subdescription: "Comments of the original are kept.",
+		document:       "# deployment\ncontainers:\n  - name: app # main\n    image: app:1\n",
+		expression:     `strategic_merge({"containers": [{"name": "app", "image": "app:2"}, {"name": "new", "image": "new:1"}]}; .name)`,
+		expected: []string{
+			"D0, P[], (!!map)::# deployment\ncontainers:\n    - name: app # main\n      image: app:2\n    - name: new\n      image: new:1\n",
+		},
+	},
+	{
+		description:   "Wrong number of parameters",
+		skipDoc:       true,
+		document:      `{a: []}`,
+		expression:    `strategic_merge(.a)`,
+		expectedError: "strategic_merge expects 2 parameters, a patch and a key expression, got 1",
+	},
+}
+
+func TestStrategicMergeOperatorScenarios(t *testing.T) {
+	for _, tt := range strategicMergeOperatorScenarios {
+		testScenario(t, &tt)
+	}
+	documentOperatorScenarios(t, "strategic-merge", strategicMergeOperatorScenarios)
+}
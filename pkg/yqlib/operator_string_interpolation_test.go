@@ -0,0 +1,84 @@
+package yqlib
+
+import (
+	"testing"
+)
+
+var stringInterpolationOperatorScenarios = []expressionScenario{
+	{
+		description: "Interpolate values into a string",
+		document:    `{name: nginx, tag: 1.21}`,
+		expression:  `"registry/\(.name):\(.tag)"`,
+		expected: []string{
+			"D0, P[], (!!str)::registry/nginx:1.21\n",
+		},
+	},
+	{
+		description:    "Interpolate maps and arrays",
+		subdescription: "Values that are not strings are encoded as json",
+		document:       `{a: {b: [1, true]}}`,
+		expression:     `"a is \(.a)"`,
+		expected: []string{
+			"D0, P[], (!!str)::a is {\"b\":[1,true]}\n",
+		},
+	},
+	{
+		description:    "Interpolated expressions can contain strings",
+		subdescription: "Including other interpolated strings",
+		document:       `{a: cat}`,
+		expression:     `"\(.b // "no b") and \("the \(.a)")"`,
+		expected: []string{
+			"D0, P[], (!!str)::no b and the cat\n",
+		},
+	},
+	{
+		description:    "Multiple results",
+		subdescription: "Each combination of results from the interpolated expressions is returned",
+		document:       `{a: [1, 2]}`,
+		expression:     `"item \(.a[])"`,
+		expected: []string{
+			"D0, P[], (!!str)::item 1\n",
+			"D0, P[], (!!str)::item 2\n",
+		},
+	},
+	{
+		description:    "Escape interpolated values for shell",
+		subdescription: "Prefix the string with a format, and it will be applied to each interpolated value (but not the rest of the string).",
+		document:       `{file: "it's here.txt"}`,
+		expression:     `@sh "cat \(.file)"`,
+		expected: []string{
+			"D0, P[], (!!str)::cat 'it'\\''s here.txt'\n",
+		},
+	},
+	{
+		description: "Encode interpolated values as json",
+		document:    `{a: cat, b: [1, 2]}`,
+		expression:  `@json "a=\(.a), b=\(.b)"`,
+		expected: []string{
+			"D0, P[], (!!str)::a=\"cat\", b=[1,2]\n",
+		},
+	},
+	{
+		skipDoc:    true,
+		document:   `{a: 1}`,
+		expression: `"quote \"\(.a)\""`,
+		expected: []string{
+			"D0, P[], (!!str)::quote \"1\"\n",
+		},
+	},
+	{
+		skipDoc:    true,
+		document:   `{a: 1}`,
+		expression: `[.a] | map("n=\((. + 1) * 2)")`,
+		expected: []string{
+			"D0, P[], (!!seq)::- n=4\n",
+		},
+	},
+}
+
+func TestStringInterpolationOperatorScenarios(t *testing.T) {
+	for _, tt := range stringInterpolationOperatorScenarios {
+		testScenario(t, &tt)
+	}
+	documentOperatorScenarios(t, "string-interpolation", stringInterpolationOperatorScenarios)
+}
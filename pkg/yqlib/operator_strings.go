@@ -3,75 +3,131 @@ package yqlib
 import (
 	"container/list"
 	"fmt"
+	"math"
 	"regexp"
+	"strconv"
 	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
 
 	"gopkg.in/yaml.v3"
 )
 
-func getSubstituteParameters(d *dataTreeNavigator, block *ExpressionNode, context Context) (string, string, error) {
-	regEx := ""
-	replacementText := ""
+// getSubstituteParameters returns the regex, replacement and flags expressions, supporting
+// both sub(regex; replacement; flags) and the original sub(regex, replacement) forms.
+func getSubstituteParameters(expressionNode *ExpressionNode, operatorName string) (*ExpressionNode, *ExpressionNode, *ExpressionNode, error) {
+	switch expressionNode.Operation.OperationType {
+	case blockOpType:
+		params := flattenBlock(expressionNode)
+		if len(params) > 3 {
+			return nil, nil, nil, fmt.Errorf("%v expects at most 3 parameters, got %v", operatorName, len(params))
+		} else if len(params) == 3 {
+			return params[0], params[1], params[2], nil
+		}
+		return params[0], params[1], nil, nil
+	case unionOpType:
+		return expressionNode.LHS, expressionNode.RHS, nil, nil
+	}
+	return nil, nil, nil, fmt.Errorf("%v expects a regex and a replacement, e.g. %v(\"a\"; \"b\")", operatorName, operatorName)
+}
 
-	regExNodes, err := d.GetMatchingNodes(context.ReadOnlyClone(), block.LHS)
+func getSubstitution(d *dataTreeNavigator, context Context, regEx *regexp.Regexp, replacementExp *ExpressionNode, value string, matchIndex []int) (string, bool, error) {
+	// the replacement expression is run against the named captures of the match
+	captures := createCapturesNode(regEx, value, matchIndex)
+	replacements, err := d.GetMatchingNodes(context.SingleReadonlyChildContext(&CandidateNode{Node: captures}), replacementExp)
 	if err != nil {
-		return "", "", err
+		return "", false, err
+	}
+	if replacements.MatchingNodes.Front() == nil {
+		return "", false, nil
 	}
-	if regExNodes.MatchingNodes.Front() != nil {
-		regEx = regExNodes.MatchingNodes.Front().Value.(*CandidateNode).Node.Value
+	replacementNode := unwrapDoc(replacements.MatchingNodes.Front().Value.(*CandidateNode).Node)
+	if replacementNode.Kind != yaml.ScalarNode {
+		return "", false, fmt.Errorf("cannot substitute with %v, the replacement must be a string", replacementNode.Tag)
 	}
+	// golang style references to the capture groups (e.g. ${1}) are still supported
+	return string(regEx.ExpandString(nil, replacementNode.Value, value, matchIndex)), true, nil
+}
 
-	log.Debug("regEx %v", regEx)
+// substitute replaces all the matches, whatever the flags, as sub always has in yq.
+func substitute(d *dataTreeNavigator, context Context, candidate *CandidateNode, regExExp *ExpressionNode, replacementExp *ExpressionNode, flagsExp *ExpressionNode) (*CandidateNode, error) {
+	node := unwrapDoc(candidate.Node)
+	if node.Tag != "!!str" {
+		return nil, fmt.Errorf("cannot substitute with %v, can only substitute strings. Hint: Most often you'll want to use '|=' over '=' for this operation", node.Tag)
+	}
+	candidateContext := context.SingleReadonlyChildContext(candidate)
 
-	replacementNodes, err := d.GetMatchingNodes(context, block.RHS)
+	regExStr, err := getFirstValue(d, candidateContext, regExExp)
 	if err != nil {
-		return "", "", err
+		return nil, err
+	}
+	flags := ""
+	if flagsExp != nil {
+		flags, err = getFirstValue(d, candidateContext, flagsExp)
+		if err != nil {
+			return nil, err
+		}
 	}
-	if replacementNodes.MatchingNodes.Front() != nil {
-		replacementText = replacementNodes.MatchingNodes.Front().Value.(*CandidateNode).Node.Value
+	matchPrefs, err := parseMatchFlags(flags)
+	if err != nil {
+		return nil, err
 	}
+	matchPrefs.Global = true
 
-	return regEx, replacementText, nil
-}
-
-func substitute(original string, regex *regexp.Regexp, replacement string) *yaml.Node {
-	replacedString := regex.ReplaceAllString(original, replacement)
-	return &yaml.Node{Kind: yaml.ScalarNode, Value: replacedString, Tag: "!!str"}
-}
-
-func substituteStringOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
-	//rhs  block operator
-	//lhs of block = regex
-	//rhs of block = replacement expression
-	block := expressionNode.RHS
+	regEx, err := compileRegex(regExStr, matchPrefs)
+	if err != nil {
+		return nil, err
+	}
 
-	regExStr, replacementText, err := getSubstituteParameters(d, block, context)
+	_, allIndices := getMatches(matchPrefs, regEx, node.Value)
 
-	if err != nil {
-		return Context{}, err
+	var replaced strings.Builder
+	lastIndex := 0
+	for _, matchIndex := range allIndices {
+		replacement, found, err := getSubstitution(d, candidateContext, regEx, replacementExp, node.Value, matchIndex)
+		if err != nil {
+			return nil, err
+		} else if !found {
+			return nil, nil
+		}
+		replaced.WriteString(node.Value[lastIndex:matchIndex[0]])
+		replaced.WriteString(replacement)
+		lastIndex = matchIndex[1]
 	}
+	replaced.WriteString(node.Value[lastIndex:])
+
+	return candidate.CreateReplacement(&yaml.Node{Kind: yaml.ScalarNode, Value: replaced.String(), Tag: "!!str"}), nil
+}
 
-	regEx, err := regexp.Compile(regExStr)
+func substituteStrings(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode, operatorName string) (Context, error) {
+	regExExp, replacementExp, flagsExp, err := getSubstituteParameters(expressionNode.RHS, operatorName)
 	if err != nil {
 		return Context{}, err
 	}
-
 	var results = list.New()
 
 	for el := context.MatchingNodes.Front(); el != nil; el = el.Next() {
-		candidate := el.Value.(*CandidateNode)
-		node := unwrapDoc(candidate.Node)
-		if node.Tag != "!!str" {
-			return Context{}, fmt.Errorf("cannot substitute with %v, can only substitute strings. Hint: Most often you'll want to use '|=' over '=' for this operation", node.Tag)
+		result, err := substitute(d, context, el.Value.(*CandidateNode), regExExp, replacementExp, flagsExp)
+		if err != nil {
+			return Context{}, err
+		}
+		if result != nil {
+			results.PushBack(result)
 		}
-
-		targetNode := substitute(node.Value, regEx, replacementText)
-		result := candidate.CreateReplacement(targetNode)
-		results.PushBack(result)
 	}
 
 	return context.ChildContext(results), nil
+}
+
+func substituteStringOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	log.Debugf("-- substituteStringOperator")
+	return substituteStrings(d, context, expressionNode, "sub")
+}
 
+func globalSubstituteStringOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	log.Debugf("-- globalSubstituteStringOperator")
+	return substituteStrings(d, context, expressionNode, "gsub")
 }
 
 func addMatch(original []*yaml.Node, match string, offset int, name string) []*yaml.Node {
@@ -106,19 +162,120 @@ func addMatch(original []*yaml.Node, match string, offset int, name string) []*y
 }
 
 type matchPreferences struct {
-	Global bool
+	Global      bool
+	IgnoreCase  bool
+	Extended    bool
+	IgnoreEmpty bool
+}
+
+// parseMatchFlags parses the jq style regex flags:
+// g (global), i (ignore case), x (extended - ignore whitespace and comments) and n (ignore empty matches)
+func parseMatchFlags(flags string) (matchPreferences, error) {
+	matchPrefs := matchPreferences{}
+	for _, flag := range flags {
+		switch flag {
+		case 'g':
+			matchPrefs.Global = true
+		case 'i':
+			matchPrefs.IgnoreCase = true
+		case 'x':
+			matchPrefs.Extended = true
+		case 'n':
+			matchPrefs.IgnoreEmpty = true
+		default:
+			return matchPrefs, fmt.Errorf(`Unrecognised match params '%v', please see docs at https://mikefarah.gitbook.io/yq/operators/string-operators`, flags)
+		}
+	}
+	return matchPrefs, nil
+}
+
+// stripExtendedRegex removes the whitespace and comments from an extended regex,
+// as golang does not support the 'x' flag.
+func stripExtendedRegex(pattern string) string {
+	var stripped strings.Builder
+	inClass := false
+	inComment := false
+	for index := 0; index < len(pattern); index++ {
+		char := pattern[index]
+		switch {
+		case inComment:
+			inComment = char != '\n'
+		case char == '\\' && index+1 < len(pattern):
+			stripped.WriteByte(char)
+			stripped.WriteByte(pattern[index+1])
+			index++
+		case inClass:
+			inClass = char != ']'
+			stripped.WriteByte(char)
+		case char == '[':
+			inClass = true
+			stripped.WriteByte(char)
+		case char == '#':
+			inComment = true
+		case !unicode.IsSpace(rune(char)):
+			stripped.WriteByte(char)
+		}
+	}
+	return stripped.String()
+}
+
+// maxCachedRegexes limits the cache, as regexes built from the data (e.g. `test(.pattern)`)
+// would otherwise be kept forever by long running programs using yqlib.
+const maxCachedRegexes = 256
+
+var regexCache = make(map[string]*regexp.Regexp)
+var regexCacheLock sync.Mutex
+
+// compileRegex compiles the regex with the given flags, caching the result as
+// the same regex is typically used for many candidates. The cache is cleared when it is full.
+func compileRegex(pattern string, matchPrefs matchPreferences) (*regexp.Regexp, error) {
+	if matchPrefs.Extended {
+		pattern = stripExtendedRegex(pattern)
+	}
+	if matchPrefs.IgnoreCase {
+		pattern = "(?i)" + pattern
+	}
+
+	regexCacheLock.Lock()
+	defer regexCacheLock.Unlock()
+	if regEx, ok := regexCache[pattern]; ok {
+		return regEx, nil
+	}
+	regEx, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	if len(regexCache) >= maxCachedRegexes {
+		regexCache = make(map[string]*regexp.Regexp)
+	}
+	regexCache[pattern] = regEx
+	return regEx, nil
 }
 
 func getMatches(matchPrefs matchPreferences, regEx *regexp.Regexp, value string) ([][]string, [][]int) {
 	var allMatches [][]string
 	var allIndices [][]int
 
-	if matchPrefs.Global {
-		allMatches = regEx.FindAllStringSubmatch(value, -1)
-		allIndices = regEx.FindAllStringSubmatchIndex(value, -1)
-	} else {
-		allMatches = [][]string{regEx.FindStringSubmatch(value)}
-		allIndices = [][]int{regEx.FindStringSubmatchIndex(value)}
+	limit := 1
+	if matchPrefs.Global || matchPrefs.IgnoreEmpty {
+		limit = -1
+	}
+
+	for _, indices := range regEx.FindAllStringSubmatchIndex(value, limit) {
+		if matchPrefs.IgnoreEmpty && indices[0] == indices[1] {
+			continue
+		}
+		matches := make([]string, len(indices)/2)
+		for i := range matches {
+			if indices[i*2] >= 0 {
+				matches[i] = value[indices[i*2]:indices[i*2+1]]
+			}
+		}
+		allMatches = append(allMatches, matches)
+		allIndices = append(allIndices, indices)
+		if !matchPrefs.Global {
+			break
+		}
 	}
 
 	log.Debug("allMatches, %v", allMatches)
@@ -156,43 +313,51 @@ func match(matchPrefs matchPreferences, regEx *regexp.Regexp, candidate *Candida
 
 }
 
-func capture(matchPrefs matchPreferences, regEx *regexp.Regexp, candidate *CandidateNode, value string, results *list.List) {
+// createCapturesNode creates a map of the named capture groups of a match
+func createCapturesNode(regEx *regexp.Regexp, value string, matchIndex []int) *yaml.Node {
 	subNames := regEx.SubexpNames()
-	allMatches, allIndices := getMatches(matchPrefs, regEx, value)
-
-	// if all matches just has an empty array in it,
-	// then nothing matched
-	if len(allMatches) > 0 && len(allMatches[0]) == 0 {
-		return
-	}
+	capturesNode := &yaml.Node{Kind: yaml.MappingNode}
 
-	for i, matches := range allMatches {
-		capturesNode := &yaml.Node{Kind: yaml.MappingNode}
+	for j := 1; j < len(subNames); j++ {
+		capturesNode.Content = append(capturesNode.Content,
+			createScalarNode(subNames[j], subNames[j]))
 
-		_, submatches := matches[0], matches[1:]
-		for j, submatch := range submatches {
+		offset := matchIndex[j*2]
+		// offset of -1 means there was no match, force a null value like jq
+		if offset < 0 {
 			capturesNode.Content = append(capturesNode.Content,
-				createScalarNode(subNames[j+1], subNames[j+1]))
-
-			offset := allIndices[i][2+j*2]
-			// offset of -1 means there was no match, force a null value like jq
-			if offset < 0 {
-				capturesNode.Content = append(capturesNode.Content,
-					createScalarNode(nil, "null"),
-				)
-			} else {
-				capturesNode.Content = append(capturesNode.Content,
-					createScalarNode(submatch, submatch),
-				)
-			}
+				createScalarNode(nil, "null"),
+			)
+		} else {
+			submatch := value[offset:matchIndex[j*2+1]]
+			capturesNode.Content = append(capturesNode.Content,
+				createScalarNode(submatch, submatch),
+			)
 		}
+	}
+	return capturesNode
+}
 
-		results.PushBack(candidate.CreateReplacement(capturesNode))
+func capture(matchPrefs matchPreferences, regEx *regexp.Regexp, candidate *CandidateNode, value string, results *list.List) {
+	_, allIndices := getMatches(matchPrefs, regEx, value)
 
+	for _, matchIndex := range allIndices {
+		results.PushBack(candidate.CreateReplacement(createCapturesNode(regEx, value, matchIndex)))
 	}
 
 }
 
+func getFirstValue(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (string, error) {
+	result, err := d.GetMatchingNodes(context.ReadOnlyClone(), expressionNode)
+	if err != nil {
+		return "", err
+	}
+	if result.MatchingNodes.Front() == nil {
+		return "", nil
+	}
+	return result.MatchingNodes.Front().Value.(*CandidateNode).Node.Value, nil
+}
+
 func extractMatchArguments(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (*regexp.Regexp, matchPreferences, error) {
 	regExExpNode := expressionNode.RHS
 
@@ -202,37 +367,22 @@ func extractMatchArguments(d *dataTreeNavigator, context Context, expressionNode
 	if expressionNode.RHS.Operation.OperationType == blockOpType {
 		block := expressionNode.RHS
 		regExExpNode = block.LHS
-		replacementNodes, err := d.GetMatchingNodes(context, block.RHS)
+		paramText, err := getFirstValue(d, context, block.RHS)
 		if err != nil {
 			return nil, matchPrefs, err
 		}
-		paramText := ""
-		if replacementNodes.MatchingNodes.Front() != nil {
-			paramText = replacementNodes.MatchingNodes.Front().Value.(*CandidateNode).Node.Value
-		}
-		if strings.Contains(paramText, "g") {
-			paramText = strings.ReplaceAll(paramText, "g", "")
-			matchPrefs.Global = true
-		}
-		if strings.Contains(paramText, "i") {
-			return nil, matchPrefs, fmt.Errorf(`'i' is not a valid option for match. To ignore case, use an expression like match("(?i)cat")`)
-		}
-		if len(paramText) > 0 {
-			return nil, matchPrefs, fmt.Errorf(`Unrecognised match params '%v', please see docs at https://mikefarah.gitbook.io/yq/operators/string-operators`, paramText)
+		matchPrefs, err = parseMatchFlags(paramText)
+		if err != nil {
+			return nil, matchPrefs, err
 		}
 	}
 
-	regExNodes, err := d.GetMatchingNodes(context.ReadOnlyClone(), regExExpNode)
+	regExStr, err := getFirstValue(d, context, regExExpNode)
 	if err != nil {
 		return nil, matchPrefs, err
 	}
-	log.Debug(NodesToString(regExNodes.MatchingNodes))
-	regExStr := ""
-	if regExNodes.MatchingNodes.Front() != nil {
-		regExStr = regExNodes.MatchingNodes.Front().Value.(*CandidateNode).Node.Value
-	}
 	log.Debug("regEx %v", regExStr)
-	regEx, err := regexp.Compile(regExStr)
+	regEx, err := compileRegex(regExStr, matchPrefs)
 	return regEx, matchPrefs, err
 }
 
@@ -386,3 +536,339 @@ func split(value string, spltStr string) *yaml.Node {
 
 	return &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Content: contents}
 }
+
+func isStringNode(node *yaml.Node) bool {
+	if node.Kind != yaml.ScalarNode {
+		return false
+	}
+	if !strings.HasPrefix(node.Tag, "!!") {
+		// custom tag - we have to have a guess
+		return guessTagFromCustomType(node) == "!!str"
+	}
+	return node.Tag == "!!str"
+}
+
+func getStringParameter(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode, operatorName string) (string, error) {
+	rhs, err := d.GetMatchingNodes(context.ReadOnlyClone(), expressionNode)
+	if err != nil {
+		return "", err
+	}
+	if rhs.MatchingNodes.Front() == nil {
+		return "", fmt.Errorf("%v expects a string parameter, but got nothing", operatorName)
+	}
+	node := unwrapDoc(rhs.MatchingNodes.Front().Value.(*CandidateNode).Node)
+	if !isStringNode(node) {
+		return "", fmt.Errorf("%v expects a string parameter, got %v instead", operatorName, node.Tag)
+	}
+	return node.Value, nil
+}
+
+// mapStrings replaces the value of each matching string, keeping its tag and style.
+func mapStrings(context Context, operatorName string, mapper func(value string) string) (Context, error) {
+	var results = list.New()
+
+	for el := context.MatchingNodes.Front(); el != nil; el = el.Next() {
+		candidate := el.Value.(*CandidateNode)
+		node := unwrapDoc(candidate.Node)
+		if !isStringNode(node) {
+			return Context{}, fmt.Errorf("%v can only be used on strings, got %v", operatorName, node.Tag)
+		}
+		targetNode := &yaml.Node{Kind: yaml.ScalarNode, Tag: node.Tag, Style: node.Style, Value: mapper(node.Value)}
+		results.PushBack(candidate.CreateReplacement(targetNode))
+	}
+
+	return context.ChildContext(results), nil
+}
+
+func asciiDowncaseOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	log.Debugf("-- asciiDowncaseOperator")
+	return mapStrings(context, "ascii_downcase", func(value string) string {
+		return strings.Map(func(r rune) rune {
+			if r >= 'A' && r <= 'Z' {
+				return r + ('a' - 'A')
+			}
+			return r
+		}, value)
+	})
+}
+
+func asciiUpcaseOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	log.Debugf("-- asciiUpcaseOperator")
+	return mapStrings(context, "ascii_upcase", func(value string) string {
+		return strings.Map(func(r rune) rune {
+			if r >= 'a' && r <= 'z' {
+				return r - ('a' - 'A')
+			}
+			return r
+		}, value)
+	})
+}
+
+func trimOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	log.Debugf("-- trimOperator")
+	return mapStrings(context, "trim", strings.TrimSpace)
+}
+
+// trimString is like jq's ltrimstr and rtrimstr, anything that is not a string is returned unchanged.
+func trimString(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode, trim func(value string, affix string) string) (Context, error) {
+	rhs, err := d.GetMatchingNodes(context.ReadOnlyClone(), expressionNode.RHS)
+	if err != nil {
+		return Context{}, err
+	}
+	if rhs.MatchingNodes.Front() == nil || !isStringNode(unwrapDoc(rhs.MatchingNodes.Front().Value.(*CandidateNode).Node)) {
+		return context, nil
+	}
+	affix := unwrapDoc(rhs.MatchingNodes.Front().Value.(*CandidateNode).Node).Value
+
+	var results = list.New()
+	for el := context.MatchingNodes.Front(); el != nil; el = el.Next() {
+		candidate := el.Value.(*CandidateNode)
+		node := unwrapDoc(candidate.Node)
+		if !isStringNode(node) {
+			results.PushBack(candidate)
+			continue
+		}
+		targetNode := &yaml.Node{Kind: yaml.ScalarNode, Tag: node.Tag, Style: node.Style, Value: trim(node.Value, affix)}
+		results.PushBack(candidate.CreateReplacement(targetNode))
+	}
+	return context.ChildContext(results), nil
+}
+
+func ltrimstrOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	log.Debugf("-- ltrimstrOperator")
+	return trimString(d, context, expressionNode, strings.TrimPrefix)
+}
+
+func rtrimstrOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	log.Debugf("-- rtrimstrOperator")
+	return trimString(d, context, expressionNode, strings.TrimSuffix)
+}
+
+func testString(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode, operatorName string, test func(value string, affix string) bool) (Context, error) {
+	affix, err := getStringParameter(d, context, expressionNode.RHS, operatorName)
+	if err != nil {
+		return Context{}, err
+	}
+
+	var results = list.New()
+	for el := context.MatchingNodes.Front(); el != nil; el = el.Next() {
+		candidate := el.Value.(*CandidateNode)
+		node := unwrapDoc(candidate.Node)
+		if !isStringNode(node) {
+			return Context{}, fmt.Errorf("%v can only be used on strings, got %v", operatorName, node.Tag)
+		}
+		results.PushBack(createBooleanCandidate(candidate, test(node.Value, affix)))
+	}
+	return context.ChildContext(results), nil
+}
+
+func startsWithOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	log.Debugf("-- startsWithOperator")
+	return testString(d, context, expressionNode, "startswith", strings.HasPrefix)
+}
+
+func endsWithOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	log.Debugf("-- endsWithOperator")
+	return testString(d, context, expressionNode, "endswith", strings.HasSuffix)
+}
+
+// stringIndices finds the (possibly overlapping) positions of search in value, counted in unicode code points.
+func stringIndices(value string, search string) []int {
+	indices := make([]int, 0)
+	if search == "" {
+		return indices
+	}
+	for offset := 0; offset < len(value); {
+		found := strings.Index(value[offset:], search)
+		if found < 0 {
+			break
+		}
+		indices = append(indices, utf8.RuneCountInString(value[:offset+found]))
+		_, runeSize := utf8.DecodeRuneInString(value[offset+found:])
+		offset = offset + found + runeSize
+	}
+	return indices
+}
+
+func findIndices(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode, operatorName string, pick func(indices []int) *yaml.Node) (Context, error) {
+	rhs, err := d.GetMatchingNodes(context.ReadOnlyClone(), expressionNode.RHS)
+	if err != nil {
+		return Context{}, err
+	}
+	if rhs.MatchingNodes.Front() == nil {
+		return Context{}, fmt.Errorf("%v expects a parameter, but got nothing", operatorName)
+	}
+	search := unwrapDoc(rhs.MatchingNodes.Front().Value.(*CandidateNode).Node)
+
+	var results = list.New()
+	for el := context.MatchingNodes.Front(); el != nil; el = el.Next() {
+		candidate := el.Value.(*CandidateNode)
+		node := unwrapDoc(candidate.Node)
+		if node.Tag == "!!null" {
+			results.PushBack(candidate)
+			continue
+		} else if node.Kind == yaml.SequenceNode {
+			results.PushBack(candidate.CreateReplacement(pick(arrayIndices(node, search))))
+			continue
+		} else if !isStringNode(node) {
+			return Context{}, fmt.Errorf("%v can only be used on strings and arrays, got %v", operatorName, node.Tag)
+		} else if !isStringNode(search) {
+			return Context{}, fmt.Errorf("%v expects a string parameter, got %v instead", operatorName, search.Tag)
+		}
+		results.PushBack(candidate.CreateReplacement(pick(stringIndices(node.Value, search.Value))))
+	}
+	return context.ChildContext(results), nil
+}
+
+func createIntNode(number int) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: fmt.Sprintf("%v", number)}
+}
+
+func indexOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	log.Debugf("-- indexOperator")
+	return findIndices(d, context, expressionNode, "index", func(indices []int) *yaml.Node {
+		if len(indices) == 0 {
+			return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}
+		}
+		return createIntNode(indices[0])
+	})
+}
+
+func rindexOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	log.Debugf("-- rindexOperator")
+	return findIndices(d, context, expressionNode, "rindex", func(indices []int) *yaml.Node {
+		if len(indices) == 0 {
+			return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}
+		}
+		return createIntNode(indices[len(indices)-1])
+	})
+}
+
+func indicesOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	log.Debugf("-- indicesOperator")
+	return findIndices(d, context, expressionNode, "indices", func(indices []int) *yaml.Node {
+		seq := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		for _, index := range indices {
+			seq.Content = append(seq.Content, createIntNode(index))
+		}
+		return seq
+	})
+}
+
+func explodeStringOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	log.Debugf("-- explodeStringOperator")
+	var results = list.New()
+	for el := context.MatchingNodes.Front(); el != nil; el = el.Next() {
+		candidate := el.Value.(*CandidateNode)
+		node := unwrapDoc(candidate.Node)
+		if !isStringNode(node) {
+			return Context{}, fmt.Errorf("explode can only be used on strings, got %v", node.Tag)
+		}
+		seq := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		for _, codePoint := range node.Value {
+			seq.Content = append(seq.Content, createIntNode(int(codePoint)))
+		}
+		results.PushBack(candidate.CreateReplacement(seq))
+	}
+	return context.ChildContext(results), nil
+}
+
+func parseCodePoint(node *yaml.Node) (rune, error) {
+	if node.Kind != yaml.ScalarNode || node.Tag != "!!int" {
+		return 0, fmt.Errorf("cannot implode %v, can only implode arrays of code points", node.Tag)
+	}
+	_, codePoint, err := parseInt(node.Value)
+	if err != nil {
+		return 0, err
+	}
+	if codePoint < 0 || codePoint > utf8.MaxRune {
+		return 0, fmt.Errorf("%v is not a valid code point", codePoint)
+	}
+	return rune(codePoint), nil
+}
+
+func implodeOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	log.Debugf("-- implodeOperator")
+	var results = list.New()
+	for el := context.MatchingNodes.Front(); el != nil; el = el.Next() {
+		candidate := el.Value.(*CandidateNode)
+		node := unwrapDoc(candidate.Node)
+		if node.Kind != yaml.SequenceNode {
+			return Context{}, fmt.Errorf("cannot implode %v, can only implode arrays of code points", node.Tag)
+		}
+		var builder strings.Builder
+		for _, child := range node.Content {
+			codePoint, err := parseCodePoint(child)
+			if err != nil {
+				return Context{}, err
+			}
+			builder.WriteRune(codePoint)
+		}
+		results.PushBack(candidate.CreateReplacement(&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: builder.String()}))
+	}
+	return context.ChildContext(results), nil
+}
+
+func asciiOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	log.Debugf("-- asciiOperator")
+	var results = list.New()
+	for el := context.MatchingNodes.Front(); el != nil; el = el.Next() {
+		candidate := el.Value.(*CandidateNode)
+		node := unwrapDoc(candidate.Node)
+		codePoint, err := parseCodePoint(node)
+		if err != nil || codePoint > unicode.MaxASCII {
+			return Context{}, fmt.Errorf("ascii can only be used on integers from 0 to 127, got %v", node.Value)
+		}
+		results.PushBack(candidate.CreateReplacement(&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: string(codePoint)}))
+	}
+	return context.ChildContext(results), nil
+}
+
+func toStringOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	log.Debugf("-- toStringOperator")
+	var results = list.New()
+	for el := context.MatchingNodes.Front(); el != nil; el = el.Next() {
+		candidate := el.Value.(*CandidateNode)
+		node := unwrapDoc(candidate.Node)
+		value := node.Value
+		if node.Kind != yaml.ScalarNode {
+			// like jq, maps and arrays are encoded as json
+			encoded, err := encodeToString(candidate.CreateReplacement(node), encoderPreferences{format: JSONOutputFormat, indent: 0})
+			if err != nil {
+				return Context{}, err
+			}
+			value = strings.TrimSuffix(encoded, "\n")
+		}
+		results.PushBack(candidate.CreateReplacement(&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}))
+	}
+	return context.ChildContext(results), nil
+}
+
+func toNumberOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	log.Debugf("-- toNumberOperator")
+	var results = list.New()
+	for el := context.MatchingNodes.Front(); el != nil; el = el.Next() {
+		candidate := el.Value.(*CandidateNode)
+		node := unwrapDoc(candidate.Node)
+		if node.Kind == yaml.ScalarNode && (node.Tag == "!!int" || node.Tag == "!!float") {
+			results.PushBack(candidate)
+			continue
+		} else if tag := resolveTag(node); node.Kind == yaml.ScalarNode && (tag == "!!int" || tag == "!!float") {
+			// custom tagged number, retag it
+			results.PushBack(candidate.CreateReplacement(&yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: node.Value}))
+			continue
+		} else if !isStringNode(node) {
+			return Context{}, fmt.Errorf("cannot convert %v to a number", node.Tag)
+		}
+
+		value := strings.TrimSpace(node.Value)
+		if _, _, err := parseInt(value); err == nil {
+			results.PushBack(candidate.CreateReplacement(&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: value}))
+		} else if number, err := strconv.ParseFloat(value, 64); err == nil && !math.IsInf(number, 0) && !math.IsNaN(number) {
+			results.PushBack(candidate.CreateReplacement(&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!float", Value: value}))
+		} else {
+			return Context{}, fmt.Errorf("cannot parse '%v' as a number", node.Value)
+		}
+	}
+	return context.ChildContext(results), nil
+}
@@ -1,6 +1,7 @@
 package yqlib
 
 import (
+	"fmt"
 	"testing"
 )
 
@@ -135,6 +136,80 @@ var stringsOperatorScenarios = []expressionScenario{
 			"D0, P[], (doc)::a: cart\nb: heart\n",
 		},
 	},
+	{
+		description:    "Substitute with flags",
+		subdescription: "Flags don't change how many matches are replaced, `sub` always replaces all of them.",
+		document:       `a: hello cat Cat`,
+		expression:     `.a |= sub("l"; "L"; "i") | .a |= sub("cat"; "dog"; "i")`,
+		expected: []string{
+			"D0, P[], (doc)::a: heLLo dog dog\n",
+		},
+	},
+	{
+		description:    "Substitute the first match",
+		subdescription: "Match the rest of the string too, to only replace the first match.",
+		document:       `a: cat cat`,
+		expression:     `.a |= sub("^(.*?)cat"; "${1}dog")`,
+		expected: []string{
+			"D0, P[], (doc)::a: dog cat\n",
+		},
+	},
+	{
+		description: "Substitute all matches, ignoring case",
+		document:    `a: Cat cat`,
+		expression:  `.a |= gsub("cat"; "dog"; "i")`,
+		expected: []string{
+			"D0, P[], (doc)::a: dog dog\n",
+		},
+	},
+	{
+		description:    "Substitute with named captures",
+		subdescription: "The replacement is an expression, run against a map of the named captures of each match.",
+		document:       `a: v1.2`,
+		expression:     `.a |= gsub("(?<n>\d+)"; "<\(.n)>")`,
+		expected: []string{
+			"D0, P[], (doc)::a: v<1>.<2>\n",
+		},
+	},
+	{
+		skipDoc:     true,
+		description: "Extended regex",
+		document:    `a: cat dog`,
+		expression:  `.a |= gsub("c a t | d o g  # animals"; "x"; "x")`,
+		expected: []string{
+			"D0, P[], (doc)::a: x x\n",
+		},
+	},
+	{
+		skipDoc:     true,
+		description: "Ignore empty matches",
+		document:    `a: abc`,
+		expression:  `.a |= [gsub("x*"; "-"; "n"), gsub("x*"; "-")]`,
+		expected: []string{
+			"D0, P[], (doc)::a:\n    - abc\n    - -a-b-c-\n",
+		},
+	},
+	{
+		skipDoc:     true,
+		description: "Match with case insensitive flag",
+		document:    `foo FOO`,
+		expression:  `[match("foo"; "gi") | .offset]`,
+		expected: []string{
+			"D0, P[], (!!seq)::- 0\n- 4\n",
+		},
+	},
+	{
+		skipDoc:       true,
+		document:      `a: cat`,
+		expression:    `.a | sub("cat"; "dog"; "q")`,
+		expectedError: "Unrecognised match params 'q', please see docs at https://mikefarah.gitbook.io/yq/operators/string-operators",
+	},
+	{
+		skipDoc:       true,
+		document:      `a: cat`,
+		expression:    `.a | sub("cat"; [1])`,
+		expectedError: "cannot substitute with !!seq, the replacement must be a string",
+	},
 	{
 		description: "Split strings",
 		document:    `"cat; meow; 1; ; true"`,
@@ -164,6 +239,127 @@ var stringsOperatorScenarios = []expressionScenario{
 		expression: `split("; ")`,
 		expected:   []string{},
 	},
+	{
+		description: "Change case",
+		document:    `["Hello World", "ÉCOLE"]`,
+		expression:  `.[] | [ascii_downcase, ascii_upcase]`,
+		expected: []string{
+			"D0, P[0], (!!seq)::- \"hello world\"\n- \"HELLO WORLD\"\n",
+			"D0, P[1], (!!seq)::- \"École\"\n- \"ÉCOLE\"\n",
+		},
+	},
+	{
+		description:    "Trim prefixes and suffixes",
+		subdescription: "Values that are not strings are left as is.",
+		document:       `[app-frontend, app-backend, 3]`,
+		expression:     `map(ltrimstr("app-") | rtrimstr("end"))`,
+		expected: []string{
+			"D0, P[], (!!seq)::[front, back, 3]\n",
+		},
+	},
+	{
+		description: "Trim whitespace",
+		document:    `a: "  cat  "`,
+		expression:  `.a | trim`,
+		expected: []string{
+			"D0, P[a], (!!str)::cat\n",
+		},
+	},
+	{
+		skipDoc:       true,
+		document:      `a: 3`,
+		expression:    `.a | trim`,
+		expectedError: "trim can only be used on strings, got !!int",
+	},
+	{
+		description: "Starts and ends with",
+		document:    `[cat.yml, dog.json]`,
+		expression:  `.[] | [startswith("cat"), endswith(".yml")]`,
+		expected: []string{
+			"D0, P[0], (!!seq)::- true\n- true\n",
+			"D0, P[1], (!!seq)::- false\n- false\n",
+		},
+	},
+	{
+		skipDoc:       true,
+		document:      `a: cat`,
+		expression:    `.a | startswith(1)`,
+		expectedError: "startswith expects a string parameter, got !!int instead",
+	},
+	{
+		description:    "Find substrings",
+		subdescription: "Indices are counted in unicode code points. Matches may overlap.",
+		document:       `a: "é, b, ccc"`,
+		expression:     `.a | [index(", "), rindex(", "), indices("cc"), index("z")]`,
+		expected: []string{
+			"D0, P[a], (!!seq)::- 1\n- 4\n- - 6\n  - 7\n- null\n",
+		},
+	},
+	{
+		description:    "Explode and implode",
+		subdescription: "Convert strings to and from arrays of unicode code points",
+		document:       `a: "ab🐱"`,
+		expression:     `.a | explode | (., implode)`,
+		expected: []string{
+			"D0, P[a], (!!seq)::- 97\n- 98\n- 128049\n",
+			"D0, P[a], (!!str)::ab🐱\n",
+		},
+	},
+	{
+		skipDoc:       true,
+		document:      `[cat]`,
+		expression:    `implode`,
+		expectedError: "cannot implode !!str, can only implode arrays of code points",
+	},
+	{
+		description: "Ascii",
+		document:    `[65, 97]`,
+		expression:  `map(ascii)`,
+		expected: []string{
+			"D0, P[], (!!seq)::[A, a]\n",
+		},
+	},
+	{
+		skipDoc:       true,
+		document:      `[200]`,
+		expression:    `map(ascii)`,
+		expectedError: "ascii can only be used on integers from 0 to 127, got 200",
+	},
+	{
+		description:    "Convert to string",
+		subdescription: "Maps and arrays are converted to json",
+		document:       `[1, true, null, cat, {a: [1, 2]}]`,
+		expression:     `.[] |= tostring`,
+		expected: []string{
+			"D0, P[], (doc)::[\"1\", \"true\", \"null\", cat, '{\"a\":[1,2]}']\n",
+		},
+	},
+	{
+		description: "Convert to number",
+		document:    `[1, "2", "3.5", "0x10"]`,
+		expression:  `map(tonumber)`,
+		expected: []string{
+			"D0, P[], (!!seq)::[1, 2, 3.5, 0x10]\n",
+		},
+	},
+	{
+		skipDoc:       true,
+		document:      `a: cat`,
+		expression:    `.a | tonumber`,
+		expectedError: "cannot parse 'cat' as a number",
+	},
+	{
+		skipDoc:       true,
+		document:      `a: Inf`,
+		expression:    `.a | tonumber`,
+		expectedError: "cannot parse 'Inf' as a number",
+	},
+	{
+		skipDoc:       true,
+		document:      `a: [1]`,
+		expression:    `.a | tonumber`,
+		expectedError: "cannot convert !!seq to a number",
+	},
 }
 
 func TestStringsOperatorScenarios(t *testing.T) {
@@ -172,3 +368,16 @@ func TestStringsOperatorScenarios(t *testing.T) {
 	}
 	documentOperatorScenarios(t, "string-operators", stringsOperatorScenarios)
 }
+
+func TestRegexCacheIsLimited(t *testing.T) {
+	for i := 0; i < maxCachedRegexes*2; i++ {
+		if _, err := compileRegex(fmt.Sprintf("cat%v", i), matchPreferences{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	regexCacheLock.Lock()
+	defer regexCacheLock.Unlock()
+	if len(regexCache) > maxCachedRegexes {
+		t.Errorf("expected at most %v cached regexes, got %v", maxCachedRegexes, len(regexCache))
+	}
+}
@@ -26,15 +26,10 @@ func subtractOperator(d *dataTreeNavigator, context Context, expressionNode *Exp
 
 func subtractArray(lhs *CandidateNode, rhs *CandidateNode) (*CandidateNode, error) {
 	newLHSArray := make([]*yaml.Node, 0)
+	rhsKeys := canonicalNodeKeySet(rhs.Node.Content)
 
 	for lindex := 0; lindex < len(lhs.Node.Content); lindex = lindex + 1 {
-		shouldInclude := true
-		for rindex := 0; rindex < len(rhs.Node.Content) && shouldInclude; rindex = rindex + 1 {
-			if recursiveNodeEqual(lhs.Node.Content[lindex], rhs.Node.Content[rindex]) {
-				shouldInclude = false
-			}
-		}
-		if shouldInclude {
+		if !rhsKeys[canonicalNodeKey(lhs.Node.Content[lindex])] {
 			newLHSArray = append(newLHSArray, lhs.Node.Content[lindex])
 		}
 	}
@@ -91,6 +86,19 @@ func subtractScalars(target *CandidateNode, lhs *yaml.Node, rhs *yaml.Node) (*Ca
 
 	if lhsTag == "!!str" {
 		return nil, fmt.Errorf("strings cannot be subtracted")
+	} else if lhsTag == "!!timestamp" && rhsTag == "!!timestamp" {
+		if err := subtractTimestamps(target, lhs, rhs); err != nil {
+			return nil, err
+		}
+	} else if lhsTag == "!!timestamp" && rhsTag == "!!str" {
+		// a quoted timestamp, as in json, is subtracted as a timestamp; anything else is a duration
+		if _, err := parseTimestamp(rhs); err == nil {
+			if err := subtractTimestamps(target, lhs, rhs); err != nil {
+				return nil, err
+			}
+		} else if err := addDuration(target, lhs, rhs, -1); err != nil {
+			return nil, err
+		}
 	} else if lhsTag == "!!int" && rhsTag == "!!int" {
 		format, lhsNum, err := parseInt(lhs.Value)
 		if err != nil {
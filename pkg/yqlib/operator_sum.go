@@ -0,0 +1,104 @@
+package yqlib
+
+import (
+	"container/list"
+	"fmt"
+	"strings"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+// addArray adds all the elements of the array together with '+', nil is returned when the array is empty.
+func addArray(d *dataTreeNavigator, context Context, candidate *CandidateNode, numbersOnly bool, operatorName string) (*CandidateNode, error) {
+	candidateNode := unwrapDoc(candidate.Node)
+	if candidateNode.Kind != yaml.SequenceNode {
+		return nil, fmt.Errorf("node at path [%v] is not an array (it's a %v)", candidate.GetNicePath(), candidate.GetNiceTag())
+	}
+
+	var total *CandidateNode
+	for i, childNode := range candidateNode.Content {
+		child := candidate.CreateChildInArray(i, childNode)
+		if numbersOnly {
+			tag := childNode.Tag
+			if !strings.HasPrefix(tag, "!!") {
+				tag = guessTagFromCustomType(childNode)
+			}
+			if childNode.Kind != yaml.ScalarNode || (tag != "!!int" && tag != "!!float") {
+				return nil, fmt.Errorf("%v only works for numbers, got %v", operatorName, childNode.Tag)
+			}
+		}
+		if total == nil {
+			total = child
+			continue
+		}
+		var err error
+		total, err = add(d, context, total, child)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return total, nil
+}
+
+func addArrayOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	log.Debugf("-- addArrayOperator")
+	results := list.New()
+
+	for el := context.MatchingNodes.Front(); el != nil; el = el.Next() {
+		candidate := el.Value.(*CandidateNode)
+		total, err := addArray(d, context, candidate, false, "add")
+		if err != nil {
+			return Context{}, err
+		}
+		if total == nil {
+			total = candidate.CreateReplacement(&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"})
+		}
+		results.PushBack(total)
+	}
+
+	return context.ChildContext(results), nil
+}
+
+func sumOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	log.Debugf("-- sumOperator")
+	results := list.New()
+
+	for el := context.MatchingNodes.Front(); el != nil; el = el.Next() {
+		candidate := el.Value.(*CandidateNode)
+		total, err := addArray(d, context, candidate, true, "sum")
+		if err != nil {
+			return Context{}, err
+		}
+		if total == nil {
+			total = candidate.CreateReplacement(createScalarNode(0, "0"))
+		}
+		results.PushBack(candidate.CreateReplacement(total.Node))
+	}
+
+	return context.ChildContext(results), nil
+}
+
+func avgOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	log.Debugf("-- avgOperator")
+	results := list.New()
+
+	for el := context.MatchingNodes.Front(); el != nil; el = el.Next() {
+		candidate := el.Value.(*CandidateNode)
+		total, err := addArray(d, context, candidate, true, "avg")
+		if err != nil {
+			return Context{}, err
+		}
+		if total == nil {
+			results.PushBack(candidate.CreateReplacement(&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}))
+			continue
+		}
+		sum, err := parseNumber(unwrapDoc(total.Node), "avg")
+		if err != nil {
+			return Context{}, err
+		}
+		avg := sum.floatValue / float64(len(unwrapDoc(candidate.Node).Content))
+		results.PushBack(candidate.CreateReplacement(createScalarNode(avg, formatFloat(avg))))
+	}
+
+	return context.ChildContext(results), nil
+}
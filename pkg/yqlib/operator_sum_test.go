@@ -0,0 +1,101 @@
+package yqlib
+
+import (
+	"testing"
+)
+
+var sumOperatorScenarios = []expressionScenario{
+	{
+		description:    "Add the elements of an array",
+		subdescription: "Elements are added together with `+`, so this works for numbers, strings, arrays and maps.",
+		document:       `[[a, b], [c]]`,
+		expression:     `add`,
+		expected: []string{
+			"D0, P[0], (!!seq)::[a, b, c]\n",
+		},
+	},
+	{
+		description: "Add strings",
+		document:    `[cat, dog]`,
+		expression:  `add`,
+		expected: []string{
+			"D0, P[0], (!!str)::catdog\n",
+		},
+	},
+	{
+		description: "Add an empty array",
+		document:    `[]`,
+		expression:  `add`,
+		expected: []string{
+			"D0, P[], (!!null)::null\n",
+		},
+	},
+	{
+		description: "Sum numbers",
+		document:    `[1, 2, 3.5]`,
+		expression:  `sum`,
+		expected: []string{
+			"D0, P[], (!!float)::6.5\n",
+		},
+	},
+	{
+		skipDoc:    true,
+		document:   `[1, 2, 3]`,
+		expression: `sum`,
+		expected: []string{
+			"D0, P[], (!!int)::6\n",
+		},
+	},
+	{
+		description: "Sum an empty array",
+		document:    `[]`,
+		expression:  `sum`,
+		expected: []string{
+			"D0, P[], (!!int)::0\n",
+		},
+	},
+	{
+		description: "Average",
+		document:    `[1, 2, 3, 4]`,
+		expression:  `avg`,
+		expected: []string{
+			"D0, P[], (!!float)::2.5\n",
+		},
+	},
+	{
+		description:    "Average per group",
+		subdescription: "Averages are always floats, the average of an empty array is null.",
+		document:       `[{ns: a, mem: 512}, {ns: b, mem: 128}, {ns: a, mem: 1024}]`,
+		expression:     `group_by(.ns) | map({"ns": .[0].ns, "avg": (map(.mem) | avg)})`,
+		expected: []string{
+			"D0, P[], (!!seq)::- ns: a\n  avg: 768.0\n- ns: b\n  avg: 128.0\n",
+		},
+	},
+	{
+		skipDoc:    true,
+		document:   `[0x10, 2]`,
+		expression: `avg`,
+		expected: []string{
+			"D0, P[], (!!float)::9.0\n",
+		},
+	},
+	{
+		skipDoc:       true,
+		document:      `[1, cat]`,
+		expression:    `sum`,
+		expectedError: "sum only works for numbers, got !!str",
+	},
+	{
+		skipDoc:       true,
+		document:      `[1, cat]`,
+		expression:    `add`,
+		expectedError: "!!int cannot be added to !!str",
+	},
+}
+
+func TestSumOperatorScenarios(t *testing.T) {
+	for _, tt := range sumOperatorScenarios {
+		testScenario(t, &tt)
+	}
+	documentOperatorScenarios(t, "sum", sumOperatorScenarios)
+}
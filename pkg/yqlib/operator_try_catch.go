@@ -0,0 +1,82 @@
+package yqlib
+
+import (
+	"container/list"
+	"errors"
+	"fmt"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+func tryOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	log.Debugf("-- tryOperator")
+	return tryCatch(d, context, expressionNode.RHS, nil)
+}
+
+func catchOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	log.Debugf("-- catchOperator")
+	// try <exp> catch <handler>
+	// lhs is the try operator, its rhs is the expression being attempted
+	// rhs is the handler, run against the error message
+	if expressionNode.LHS.Operation.OperationType != tryOpType {
+		return Context{}, fmt.Errorf("catch must follow a try, got %v instead", expressionNode.LHS.Operation.OperationType.Type)
+	}
+	return tryCatch(d, context, expressionNode.LHS.RHS, expressionNode.RHS)
+}
+
+func tryCatch(d *dataTreeNavigator, context Context, tryExp *ExpressionNode, catchExp *ExpressionNode) (Context, error) {
+	var evaluateAllTogether = true
+	for matchEl := context.MatchingNodes.Front(); matchEl != nil; matchEl = matchEl.Next() {
+		evaluateAllTogether = evaluateAllTogether && matchEl.Value.(*CandidateNode).EvaluateTogether
+		if !evaluateAllTogether {
+			break
+		}
+	}
+
+	if evaluateAllTogether {
+		results, err := evaluateTryCatch(d, context, tryExp, catchExp)
+		if err != nil {
+			return Context{}, err
+		}
+		return context.ChildContext(results), nil
+	}
+
+	var results = list.New()
+	for el := context.MatchingNodes.Front(); el != nil; el = el.Next() {
+		candidate := el.Value.(*CandidateNode)
+		candidateResults, err := evaluateTryCatch(d, context.SingleChildContext(candidate), tryExp, catchExp)
+		if err != nil {
+			return Context{}, err
+		}
+		results.PushBackList(candidateResults)
+	}
+	return context.ChildContext(results), nil
+}
+
+func evaluateTryCatch(d *dataTreeNavigator, context Context, tryExp *ExpressionNode, catchExp *ExpressionNode) (*list.List, error) {
+	result, err := d.GetMatchingNodes(context, tryExp)
+	if err == nil {
+		return result.MatchingNodes, nil
+	}
+	log.Debugf("try caught: %v", err)
+	if catchExp == nil {
+		return list.New(), nil
+	}
+
+	owner := &CandidateNode{}
+	if context.MatchingNodes.Front() != nil {
+		owner = context.MatchingNodes.Front().Value.(*CandidateNode)
+	}
+
+	caughtNode := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: err.Error()}
+	var raised *userError
+	if errors.As(err, &raised) {
+		caughtNode = raised.Value
+	}
+
+	handlerResult, err := d.GetMatchingNodes(context.SingleChildContext(owner.CreateReplacement(caughtNode)), catchExp)
+	if err != nil {
+		return nil, err
+	}
+	return handlerResult.MatchingNodes, nil
+}
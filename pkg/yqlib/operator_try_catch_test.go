@@ -0,0 +1,101 @@
+package yqlib
+
+import (
+	"testing"
+)
+
+var tryCatchOperatorScenarios = []expressionScenario{
+	{
+		description:    "Try/catch an error",
+		subdescription: "The error message is passed to the catch expression",
+		document:       `a: [cat, dog]`,
+		expression:     `try .a.b catch ("error: " + .)`,
+		expected: []string{
+			"D0, P[], (!!str)::error: Cannot index array with 'b' (strconv.ParseInt: parsing \"b\": invalid syntax)\n",
+		},
+	},
+	{
+		description:    "Try without a catch",
+		subdescription: "Errors are suppressed and produce no results",
+		document:       `[1, {a: cat}, 3]`,
+		expression:     `[.[] | try (. * 2)]`,
+		expected: []string{
+			"D0, P[], (!!seq)::- 2\n- 6\n",
+		},
+	},
+	{
+		description:    "Optional expression",
+		subdescription: "`exp?` is shorthand for `try exp`",
+		document:       `[1, {a: cat}, 3]`,
+		expression:     `[.[] | (. * 2)?]`,
+		expected: []string{
+			"D0, P[], (!!seq)::- 2\n- 6\n",
+		},
+	},
+	{
+		description:    "Fallback when an expression fails",
+		subdescription: "Each matching node is tried separately",
+		document:       `[1, {a: cat}, 3]`,
+		expression:     `.[] |= (try (. * 2) catch "not a number")`,
+		expected: []string{
+			"D0, P[], (doc)::[2, not a number, 6]\n",
+		},
+	},
+	{
+		description: "Catch a raised error",
+		document:    `{name: bob, age: ~}`,
+		expression:  `try (select(.age == null) | error("missing age for " + .name)) catch .`,
+		expected: []string{
+			"D0, P[], (!!str)::missing age for bob\n",
+		},
+		skipDoc: true,
+	},
+	{
+		description:    "Catch a raised object",
+		subdescription: "The value given to `error` is passed as is to the catch expression",
+		expression:     `try error({"code": 42}) catch .code`,
+		expected: []string{
+			"D0, P[code], (!!int)::42\n",
+		},
+	},
+	{
+		skipDoc:    true,
+		document:   `a: cat`,
+		expression: `try .a catch "unused"`,
+		expected: []string{
+			"D0, P[a], (!!str)::cat\n",
+		},
+	},
+	{
+		skipDoc:    true,
+		expression: `try (try error("inner") catch error("outer: " + .)) catch .`,
+		expected: []string{
+			"D0, P[], (!!str)::outer: inner\n",
+		},
+	},
+	{
+		skipDoc:    true,
+		document:   `a: {b: [1]}`,
+		expression: `try .a.b.c catch "whole path is tried"`,
+		expected: []string{
+			"D0, P[], (!!str)::whole path is tried\n",
+		},
+	},
+	{
+		skipDoc:       true,
+		expression:    `try error("inner") catch error("outer: " + .)`,
+		expectedError: "outer: inner",
+	},
+	{
+		skipDoc:       true,
+		expression:    `.a catch "oops"`,
+		expectedError: "catch must follow a try, got TRAVERSE_PATH instead",
+	},
+}
+
+func TestTryCatchOperatorScenarios(t *testing.T) {
+	for _, tt := range tryCatchOperatorScenarios {
+		testScenario(t, &tt)
+	}
+	documentOperatorScenarios(t, "try-catch", tryCatchOperatorScenarios)
+}
@@ -0,0 +1,141 @@
+package yqlib
+
+import (
+	"container/list"
+	"fmt"
+	"strings"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+// resolveTag returns the tag of the node, guessing the underlying type of custom tagged scalars.
+func resolveTag(node *yaml.Node) string {
+	if node.Kind == yaml.ScalarNode && !strings.HasPrefix(node.Tag, "!!") {
+		return guessTagFromCustomType(node)
+	}
+	return node.Tag
+}
+
+// getTypeName returns the jq style name of the type of the node.
+func getTypeName(node *yaml.Node) string {
+	node = unwrapDoc(node)
+	if node.Kind == yaml.AliasNode && node.Alias != nil {
+		return getTypeName(node.Alias)
+	}
+	switch node.Kind {
+	case yaml.MappingNode:
+		return "object"
+	case yaml.SequenceNode:
+		return "array"
+	}
+	switch resolveTag(node) {
+	case "!!null":
+		return "null"
+	case "!!bool":
+		return "boolean"
+	case "!!int", "!!float":
+		return "number"
+	}
+	return "string"
+}
+
+func getKindName(node *yaml.Node) string {
+	switch unwrapDoc(node).Kind {
+	case yaml.MappingNode:
+		return "map"
+	case yaml.SequenceNode:
+		return "seq"
+	case yaml.AliasNode:
+		return "alias"
+	}
+	return "scalar"
+}
+
+func mapToName(context Context, getName func(node *yaml.Node) string) (Context, error) {
+	var results = list.New()
+	for el := context.MatchingNodes.Front(); el != nil; el = el.Next() {
+		candidate := el.Value.(*CandidateNode)
+		name := getName(candidate.Node)
+		results.PushBack(candidate.CreateReplacement(&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: name}))
+	}
+	return context.ChildContext(results), nil
+}
+
+func typeOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	log.Debugf("-- typeOperator")
+	return mapToName(context, getTypeName)
+}
+
+func kindOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	log.Debugf("-- kindOperator")
+	return mapToName(context, getKindName)
+}
+
+func selectTypes(context Context, typeNames ...string) (Context, error) {
+	var results = list.New()
+	for el := context.MatchingNodes.Front(); el != nil; el = el.Next() {
+		candidate := el.Value.(*CandidateNode)
+		typeName := getTypeName(candidate.Node)
+		for _, name := range typeNames {
+			if typeName == name {
+				results.PushBack(candidate)
+				break
+			}
+		}
+	}
+	return context.ChildContext(results), nil
+}
+
+func arraysOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	return selectTypes(context, "array")
+}
+
+func objectsOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	return selectTypes(context, "object")
+}
+
+func iterablesOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	return selectTypes(context, "array", "object")
+}
+
+func scalarsOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	return selectTypes(context, "null", "boolean", "number", "string")
+}
+
+func stringsOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	return selectTypes(context, "string")
+}
+
+func numbersOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	return selectTypes(context, "number")
+}
+
+func booleansOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	return selectTypes(context, "boolean")
+}
+
+func nullsOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	return selectTypes(context, "null")
+}
+
+func toBoolOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	log.Debugf("-- toBoolOperator")
+	var results = list.New()
+	for el := context.MatchingNodes.Front(); el != nil; el = el.Next() {
+		candidate := el.Value.(*CandidateNode)
+		node := unwrapDoc(candidate.Node)
+		if node.Kind == yaml.ScalarNode && node.Tag == "!!bool" {
+			results.PushBack(candidate)
+			continue
+		} else if node.Kind != yaml.ScalarNode {
+			return Context{}, fmt.Errorf("cannot convert %v to a boolean", node.Tag)
+		}
+
+		value := strings.TrimSpace(node.Value)
+		if guessTagFromCustomType(&yaml.Node{Kind: yaml.ScalarNode, Value: value}) != "!!bool" {
+			return Context{}, fmt.Errorf("cannot parse '%v' as a boolean", node.Value)
+		}
+		results.PushBack(candidate.CreateReplacement(&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: strings.ToLower(value)}))
+	}
+	return context.ChildContext(results), nil
+}
@@ -0,0 +1,100 @@
+package yqlib
+
+import (
+	"testing"
+)
+
+var typeOperatorScenarios = []expressionScenario{
+	{
+		description:    "Get type",
+		subdescription: "Returns the jq style name of the type. Custom tags are ignored, the underlying type is used instead.",
+		document:       `{a: cat, b: !thing 5, c: 3.2, d: true, e: null, f: [], g: {}}`,
+		expression:     `[.[] | type]`,
+		expected: []string{
+			"D0, P[], (!!seq)::- string\n- number\n- number\n- boolean\n- \"null\"\n- array\n- object\n",
+		},
+	},
+	{
+		description: "Get kind",
+		document:    `{a: &x cat, b: *x, c: [], d: {}}`,
+		expression:  `[.[] | kind]`,
+		expected: []string{
+			"D0, P[], (!!seq)::- scalar\n- alias\n- seq\n- map\n",
+		},
+	},
+	{
+		skipDoc:    true,
+		document:   `{a: &x [cat], b: *x}`,
+		expression: `.b | type`,
+		expected: []string{
+			"D0, P[b], (!!str)::array\n",
+		},
+	},
+	{
+		description:    "Select by type",
+		subdescription: "Also see `arrays`, `objects`, `iterables`, `scalars`, `booleans` and `nulls`.",
+		document:       `[1, cat, !thing 2, [3], {a: 4}, true, null]`,
+		expression:     `[.[] | numbers], [.[] | strings]`,
+		expected: []string{
+			"D0, P[], (!!seq)::- 1\n- !thing 2\n",
+			"D0, P[], (!!seq)::- cat\n",
+		},
+	},
+	{
+		skipDoc:    true,
+		document:   `[1, cat, [3], {a: 4}, true, null]`,
+		expression: `[.[] | arrays], [.[] | objects], [.[] | iterables], [.[] | scalars], [.[] | booleans], [.[] | nulls]`,
+		expected: []string{
+			"D0, P[], (!!seq)::- [3]\n",
+			"D0, P[], (!!seq)::- {a: 4}\n",
+			"D0, P[], (!!seq)::- [3]\n- {a: 4}\n",
+			"D0, P[], (!!seq)::- 1\n- cat\n- true\n- null\n",
+			"D0, P[], (!!seq)::- true\n",
+			"D0, P[], (!!seq)::- null\n",
+		},
+	},
+	{
+		description:    "Convert to number",
+		subdescription: "Strings are parsed, custom tagged numbers are retagged.",
+		document:       `["5", !thing 3.2, 7]`,
+		expression:     `map(to_number | tag)`,
+		expected: []string{
+			"D0, P[], (!!seq)::['!!int', '!!float', '!!int']\n",
+		},
+	},
+	{
+		description: "Convert to string",
+		document:    `[5, true, !thing 3, {a: 1}]`,
+		expression:  `map(to_string)`,
+		expected: []string{
+			"D0, P[], (!!seq)::[\"5\", \"true\", \"3\", '{\"a\":1}']\n",
+		},
+	},
+	{
+		description: "Convert to boolean",
+		document:    `["true", "False", !thing true, false]`,
+		expression:  `map(to_bool)`,
+		expected: []string{
+			"D0, P[], (!!seq)::[true, false, true, false]\n",
+		},
+	},
+	{
+		skipDoc:       true,
+		document:      `[cat]`,
+		expression:    `map(to_bool)`,
+		expectedError: "cannot parse 'cat' as a boolean",
+	},
+	{
+		skipDoc:       true,
+		document:      `[[cat]]`,
+		expression:    `map(to_bool)`,
+		expectedError: "cannot convert !!seq to a boolean",
+	},
+}
+
+func TestTypeOperatorScenarios(t *testing.T) {
+	for _, tt := range typeOperatorScenarios {
+		testScenario(t, &tt)
+	}
+	documentOperatorScenarios(t, "type", typeOperatorScenarios)
+}
@@ -0,0 +1,34 @@
+package yqlib
+
+import (
+	"container/list"
+	"fmt"
+)
+
+func validateOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	log.Debugf("-- validateOperator")
+	results := list.New()
+
+	for el := context.MatchingNodes.Front(); el != nil; el = el.Next() {
+		candidate := el.Value.(*CandidateNode)
+		schemas, err := d.GetMatchingNodes(context.SingleReadonlyChildContext(candidate), expressionNode.RHS)
+		if err != nil {
+			return Context{}, err
+		}
+		if schemas.MatchingNodes.Front() == nil {
+			return Context{}, fmt.Errorf("validate expects a schema, but got nothing")
+		}
+		// the filename of a schema from load is used to find the files of its $refs
+		schemaCandidate := schemas.MatchingNodes.Front().Value.(*CandidateNode)
+		schema, err := NewJSONSchema(schemaCandidate.Node, schemaCandidate.Filename)
+		if err != nil {
+			return Context{}, err
+		}
+		violations, err := schema.Validate(candidate)
+		if err != nil {
+			return Context{}, err
+		}
+		results.PushBack(candidate.CreateReplacement(schemaViolationsToNode(violations, false)))
+	}
+	return context.ChildContext(results), nil
+}
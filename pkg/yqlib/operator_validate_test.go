@@ -0,0 +1,159 @@
+package yqlib
+
+import (
+	"testing"
+)
+
+var validateOperatorScenarios = []expressionScenario{
+	{
+		description:    "Validate against a schema file",
+		subdescription: "Relative `$ref`s are loaded from files next to the schema. Each violation has the path, line and column of the value.",
+		document:       "name: app\nimage:\n  tag: 1\n",
+		expression:     `validate(load("../../examples/schema.json"))`,
+		expected: []string{
+			"D0, P[], (!!seq)::- path: .image.tag\n  line: 3\n  column: 8\n  message: expected string, got integer\n- path: .image\n  line: 3\n  column: 3\n  message: missing required property 'repository'\n",
+		},
+	},
+	{
+		description:    "Valid documents have no violations",
+		subdescription: "Use `length` to check if a document is valid.",
+		document:       "name: app\nimage: {repository: app}\n",
+		expression:     `validate(load("../../examples/schema.json")) | length == 0`,
+		expected: []string{
+			"D0, P[], (!!bool)::true\n",
+		},
+	},
+	{
+		description:    "Validate part of a document",
+		subdescription: "Paths start from the validated node.",
+		document:       `{items: [{id: 1}, {id: x}]}`,
+		expression:     `.items[] | validate({"properties": {"id": {"type": "integer"}}}) | .[] | .path`,
+		expected: []string{
+			"D0, P[items 1 0 path], (!!str)::.items[1].id\n",
+		},
+	},
+	{
+		description: "Objects",
+		skipDoc:     true,
+		document:    `{a: 1, b: 2, x-c: 3}`,
+		expression:  `validate({"properties": {"a": {"const": 1}}, "patternProperties": {"^x-": {"type": "string"}}, "additionalProperties": false, "required": ["a", "z"], "maxProperties": 2, "propertyNames": {"maxLength": 1}}) | .[] | .path + " " + .message`,
+		expected: []string{
+			"D0, P[0 path], (!!str)::.b property 'b' is not allowed\n",
+			"D0, P[1 path], (!!str)::.x-c expected string, got integer\n",
+			"D0, P[2 path], (!!str)::.x-c property name 'x-c' must be at most 1 characters long\n",
+			"D0, P[3 path], (!!str)::. missing required property 'z'\n",
+			"D0, P[4 path], (!!str)::. must have at most 2 properties\n",
+		},
+	},
+	{
+		description: "Dependencies",
+		skipDoc:     true,
+		document:    `{a: 1}`,
+		expression:  `validate({"dependentRequired": {"a": ["b"]}, "dependentSchemas": {"a": {"required": ["c"]}}}) | .[] | .message`,
+		expected: []string{
+			"D0, P[0 message], (!!str)::missing required property 'b', as 'a' is present\n",
+			"D0, P[1 message], (!!str)::missing required property 'c'\n",
+		},
+	},
+	{
+		description: "Arrays",
+		skipDoc:     true,
+		document:    `[1, "a", 3, 3]`,
+		expression:  `validate({"prefixItems": [{"type": "integer"}, {"type": "string"}], "items": {"maximum": 2}, "contains": {"const": 5}, "maxItems": 3, "uniqueItems": true}) | .[] | .path + " " + .message`,
+		expected: []string{
+			"D0, P[0 path], (!!str)::.[2] must be <= 2\n",
+			"D0, P[1 path], (!!str)::.[3] must be <= 2\n",
+			"D0, P[2 path], (!!str)::. must contain at least 1 matching item(s), found 0\n",
+			"D0, P[3 path], (!!str)::. must have at most 3 items\n",
+			"D0, P[4 path], (!!str)::.[3] must be unique, it is the same as item 2\n",
+		},
+	},
+	{
+		description: "Draft-07 tuples",
+		skipDoc:     true,
+		document:    `[1, "a", "b"]`,
+		expression:  `validate({"$schema": "http://json-schema.org/draft-07/schema#", "items": [{"type": "integer"}], "additionalItems": {"type": "integer"}}) | .[] | .path + " " + .message`,
+		expected: []string{
+			"D0, P[0 path], (!!str)::.[1] expected integer, got string\n",
+			"D0, P[1 path], (!!str)::.[2] expected integer, got string\n",
+		},
+	},
+	{
+		description: "An array of items is draft-07, even without $schema",
+		skipDoc:     true,
+		document:    `[1, "a", "b"]`,
+		expression:  `validate({"items": [{"type": "integer"}], "additionalItems": {"type": "integer"}}) | .[] | .path + " " + .message`,
+		expected: []string{
+			"D0, P[0 path], (!!str)::.[1] expected integer, got string\n",
+			"D0, P[1 path], (!!str)::.[2] expected integer, got string\n",
+		},
+	},
+	{
+		description: "Draft-07 ignores keywords next to $ref",
+		skipDoc:     true,
+		document:    `{a: 1}`,
+		expression:  `validate({"$schema": "http://json-schema.org/draft-07/schema#", "definitions": {"a": {"type": "object"}}, "$ref": "#/definitions/a", "required": ["b"]}) | length`,
+		expected: []string{
+			"D0, P[], (!!int)::0\n",
+		},
+	},
+	{
+		description: "Strings and numbers",
+		skipDoc:     true,
+		document:    `[ab, 1.5, 4, 10]`,
+		expression:  `validate({"items": {"anyOf": [{"type": "string", "minLength": 3}, {"type": "integer", "multipleOf": 2, "exclusiveMaximum": 10}]}}) | .[] | .path + " " + .message`,
+		expected: []string{
+			"D0, P[0 path], (!!str)::.[0] must match at least one of the anyOf schemas\n",
+			"D0, P[1 path], (!!str)::.[1] must match at least one of the anyOf schemas\n",
+			"D0, P[2 path], (!!str)::.[3] must match at least one of the anyOf schemas\n",
+		},
+	},
+	{
+		description: "Combinators",
+		skipDoc:     true,
+		document:    `{kind: a, size: 5}`,
+		expression:  `validate({"oneOf": [{"required": ["kind"]}, {"required": ["size"]}], "not": {"required": ["size"]}, "if": {"properties": {"kind": {"const": "a"}}}, "then": {"properties": {"size": {"type": "integer", "minimum": 10}}}, "else": false}) | .[] | .message`,
+		expected: []string{
+			"D0, P[0 message], (!!str)::must match exactly one of the oneOf schemas, but matched 2\n",
+			"D0, P[1 message], (!!str)::must not match the schema in not\n",
+			"D0, P[2 message], (!!str)::must be >= 10\n",
+		},
+	},
+	{
+		description: "Integers can be written as whole floats, and numbers compare by value",
+		skipDoc:     true,
+		document:    `[1.0, 2]`,
+		expression:  `validate({"items": {"type": "integer", "enum": [1, 2.0]}}) | length`,
+		expected: []string{
+			"D0, P[], (!!int)::0\n",
+		},
+	},
+	{
+		description:   "Missing refs",
+		skipDoc:       true,
+		document:      `{a: 1}`,
+		expression:    `validate({"$ref": "#/$defs/nope"})`,
+		expectedError: "cannot resolve $ref '#/$defs/nope'",
+	},
+	{
+		description:   "Recursive refs",
+		skipDoc:       true,
+		document:      `{a: 1}`,
+		expression:    `validate({"$ref": "#"})`,
+		expectedError: "$ref '#' is nested too deeply, is it recursive?",
+	},
+	{
+		description:   "Invalid schema",
+		skipDoc:       true,
+		document:      `{a: 1}`,
+		expression:    `validate("cat")`,
+		expectedError: "a json schema must be an object or a boolean, got !!str",
+	},
+}
+
+func TestValidateOperatorScenarios(t *testing.T) {
+	for _, tt := range validateOperatorScenarios {
+		testScenario(t, &tt)
+	}
+	documentOperatorScenarios(t, "validate", validateOperatorScenarios)
+}
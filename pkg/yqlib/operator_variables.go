@@ -3,13 +3,17 @@ package yqlib
 import (
 	"container/list"
 	"fmt"
+
+	yaml "gopkg.in/yaml.v3"
 )
 
 func getVariableOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
 	variableName := expressionNode.Operation.StringValue
 	log.Debug("getVariableOperator %v", variableName)
 	result := context.GetVariable(variableName)
-	if result == nil {
+	if result == nil && variableName == "ENV" {
+		result = (&CandidateNode{Node: createEnvMapNode()}).AsList()
+	} else if result == nil {
 		result = list.New()
 	}
 	return context.ChildContext(result), nil
@@ -19,24 +23,286 @@ type assignVarPreferences struct {
 	IsReference bool
 }
 
-func assignVariableOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+func getVariableValue(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (*list.List, error) {
 	lhs, err := d.GetMatchingNodes(context.ReadOnlyClone(), expressionNode.LHS)
+	if err != nil {
+		return nil, err
+	}
+
+	prefs := expressionNode.Operation.Preferences.(assignVarPreferences)
+	if prefs.IsReference {
+		return lhs.MatchingNodes, nil
+	}
+	return lhs.DeepClone().MatchingNodes, nil
+}
+
+func assignVariableOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	variableValue, err := getVariableValue(d, context, expressionNode)
 	if err != nil {
 		return Context{}, err
 	}
-	if expressionNode.RHS.Operation.OperationType.Type != "GET_VARIABLE" {
-		return Context{}, fmt.Errorf("RHS of 'as' operator must be a variable name e.g. $foo")
+
+	if expressionNode.RHS.Operation.OperationType == getVariableOpType {
+		context.SetVariable(expressionNode.RHS.Operation.StringValue, variableValue)
+		return context, nil
 	}
-	variableName := expressionNode.RHS.Operation.StringValue
+	return bindPatternToAll(d, context, expressionNode.RHS, variableValue)
+}
 
-	prefs := expressionNode.Operation.Preferences.(assignVarPreferences)
+func isAlternativePatternBinding(expressionNode *ExpressionNode) bool {
+	_, isAssignVariable := expressionNode.Operation.Preferences.(assignVarPreferences)
+	return isAssignVariable && expressionNode.RHS.Operation.OperationType == alternativePatternOpType
+}
 
-	var variableValue *list.List
-	if prefs.IsReference {
-		variableValue = lhs.MatchingNodes
-	} else {
-		variableValue = lhs.DeepClone().MatchingNodes
+// pipeAlternativePatternsHandler is set in init, as binding patterns refers to the pipe operation
+// type, so pipeOperator can't refer to pipeAlternativePatterns directly.
+var pipeAlternativePatternsHandler operatorHandler
+
+func init() {
+	pipeAlternativePatternsHandler = pipeAlternativePatterns
+}
+
+// pipeAlternativePatterns runs `exp as $a ?// [$a] | body`. Like jq, when the body fails the
+// binding is tried again from the next alternative, and the error from the last one is returned.
+func pipeAlternativePatterns(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	assign := expressionNode.LHS
+	variableValue, err := getVariableValue(d, context, assign)
+	if err != nil {
+		return Context{}, err
+	}
+	names := patternVariableNames(assign.RHS)
+
+	for pattern := assign.RHS; pattern != nil; pattern = remainingAlternatives(pattern) {
+		attempt := context.ChildContext(context.MatchingNodes)
+		for _, name := range names {
+			nullCandidate := &CandidateNode{Node: &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}}
+			attempt.SetVariable(name, nullCandidate.AsList())
+		}
+		var bound Context
+		bound, err = bindPatternToAll(d, attempt, pattern, variableValue)
+		if err == nil {
+			var result Context
+			result, err = d.GetMatchingNodes(bound, expressionNode.RHS)
+			if err == nil {
+				return context.ChildContext(result.MatchingNodes), nil
+			}
+		}
+		log.Debugf("destructuring alternative failed: %v", err.Error())
+	}
+	return Context{}, err
+}
+
+// remainingAlternatives drops the first alternative of a pattern, returning nil if there are no more.
+func remainingAlternatives(pattern *ExpressionNode) *ExpressionNode {
+	if pattern.Operation.OperationType == alternativePatternOpType {
+		return pattern.RHS
+	}
+	return nil
+}
+
+// bindPatternToAll destructures each of the matches separately,
+// the variables are set to the values collected from all of them.
+func bindPatternToAll(d *dataTreeNavigator, context Context, pattern *ExpressionNode, matches *list.List) (Context, error) {
+	names := patternVariableNames(pattern)
+	values := make(map[string]*list.List)
+	for _, name := range names {
+		values[name] = list.New()
+	}
+
+	for el := matches.Front(); el != nil; el = el.Next() {
+		bound, err := bindPattern(d, context.ChildContext(nil), pattern, el.Value.(*CandidateNode))
+		if err != nil {
+			return Context{}, err
+		}
+		for _, name := range names {
+			if value := bound.GetVariable(name); value != nil {
+				values[name].PushBackList(value)
+			}
+		}
+	}
+
+	for name, value := range values {
+		context.SetVariable(name, value)
+	}
+	return context, nil
+}
+
+func alternativePatternOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	return Context{}, fmt.Errorf("?// can only be used as the RHS of 'as' e.g. . as [$a] ?// $a")
+}
+
+func flattenUnion(expressionNode *ExpressionNode) []*ExpressionNode {
+	if expressionNode.Operation.OperationType == unionOpType {
+		return append(flattenUnion(expressionNode.LHS), flattenUnion(expressionNode.RHS)...)
+	}
+	return []*ExpressionNode{expressionNode}
+}
+
+func isObjectPattern(pattern *ExpressionNode) bool {
+	return pattern.Operation.OperationType == shortPipeOpType &&
+		pattern.RHS != nil && pattern.RHS.Operation.OperationType == collectObjectOpType
+}
+
+// patternVariableNames lists the variables a pattern binds, in the order they first appear.
+func patternVariableNames(pattern *ExpressionNode) []string {
+	names := make([]string, 0)
+	for _, name := range allPatternVariableNames(pattern) {
+		if !containsString(names, name) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func allPatternVariableNames(pattern *ExpressionNode) []string {
+	switch {
+	case pattern.Operation.OperationType == getVariableOpType:
+		return []string{pattern.Operation.StringValue}
+	case pattern.Operation.OperationType == alternativePatternOpType:
+		return append(allPatternVariableNames(pattern.LHS), allPatternVariableNames(pattern.RHS)...)
+	case pattern.Operation.OperationType == collectOpType && pattern.RHS != nil:
+		names := make([]string, 0)
+		for _, elementPattern := range flattenUnion(pattern.RHS) {
+			names = append(names, allPatternVariableNames(elementPattern)...)
+		}
+		return names
+	case isObjectPattern(pattern):
+		names := make([]string, 0)
+		for _, entryPattern := range flattenUnion(pattern.LHS) {
+			if entryPattern.Operation.OperationType == getVariableOpType {
+				names = append(names, entryPattern.Operation.StringValue)
+			} else if entryPattern.Operation.OperationType == createMapOpType {
+				if entryPattern.LHS.Operation.OperationType == getVariableOpType {
+					names = append(names, entryPattern.LHS.Operation.StringValue)
+				}
+				names = append(names, allPatternVariableNames(entryPattern.RHS)...)
+			}
+		}
+		return names
+	}
+	return []string{}
+}
+
+// bindAlternativePatterns tries each pattern in turn until one binds without error.
+// Like jq, variables from all the alternatives are set, those not in the matching pattern are null.
+// Retrying when the expression after the binding fails is done by pipeAlternativePatterns.
+func bindAlternativePatterns(d *dataTreeNavigator, context Context, pattern *ExpressionNode, value *CandidateNode) (Context, error) {
+	names := patternVariableNames(pattern)
+	alternatives := make([]*ExpressionNode, 0)
+	for current := pattern; current != nil; current = remainingAlternatives(current) {
+		if current.Operation.OperationType == alternativePatternOpType {
+			alternatives = append(alternatives, current.LHS)
+		} else {
+			alternatives = append(alternatives, current)
+		}
+	}
+
+	var err error
+	for _, alternative := range alternatives {
+		for _, name := range names {
+			nullCandidate := &CandidateNode{Node: &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}}
+			context.SetVariable(name, nullCandidate.AsList())
+		}
+		var bound Context
+		bound, err = bindPattern(d, context, alternative, value)
+		if err == nil {
+			return bound, nil
+		}
+		log.Debugf("destructuring alternative failed: %v", err.Error())
+	}
+	return Context{}, err
+}
+
+// bindPattern sets the variables in the given pattern against the value.
+// Patterns are either a variable e.g. $foo, destructuring arrays and maps
+// like [$a, $b] and {"a": $a, "b": [$c]}, or alternatives of those separated by ?//.
+func bindPattern(d *dataTreeNavigator, context Context, pattern *ExpressionNode, value *CandidateNode) (Context, error) {
+	switch {
+	case pattern.Operation.OperationType == getVariableOpType:
+		log.Debug("binding %v", pattern.Operation.StringValue)
+		context.SetVariable(pattern.Operation.StringValue, value.AsList())
+		return context, nil
+	case pattern.Operation.OperationType == collectOpType:
+		return bindArrayPattern(d, context, flattenUnion(pattern.RHS), value)
+	case isObjectPattern(pattern):
+		return bindObjectPattern(d, context, flattenUnion(pattern.LHS), value)
+	case pattern.Operation.OperationType == alternativePatternOpType:
+		return bindAlternativePatterns(d, context, pattern, value)
+	}
+	return Context{}, fmt.Errorf("RHS of 'as' operator must be a variable name or destructuring pattern e.g. $foo, [$a, $b] or {\"a\": $a}")
+}
+
+func bindArrayPattern(d *dataTreeNavigator, context Context, elementPatterns []*ExpressionNode, value *CandidateNode) (Context, error) {
+	node := unwrapDoc(value.Node)
+	if node.Tag != "!!null" && node.Kind != yaml.SequenceNode {
+		return Context{}, fmt.Errorf("cannot destructure %v as an array", node.Tag)
+	}
+	var err error
+	for index, elementPattern := range elementPatterns {
+		child := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}
+		if index < len(node.Content) {
+			child = node.Content[index]
+		}
+		context, err = bindPattern(d, context, elementPattern, value.CreateChildInArray(index, child))
+		if err != nil {
+			return Context{}, err
+		}
+	}
+	return context, nil
+}
+
+func bindObjectPattern(d *dataTreeNavigator, context Context, entryPatterns []*ExpressionNode, value *CandidateNode) (Context, error) {
+	node := unwrapDoc(value.Node)
+	if node.Tag != "!!null" && node.Kind != yaml.MappingNode {
+		return Context{}, fmt.Errorf("cannot destructure %v as an object", node.Tag)
+	}
+	mapCandidate := value.CreateReplacement(node)
+
+	var err error
+	for _, entryPattern := range entryPatterns {
+		keyExp := entryPattern
+		valuePattern := entryPattern
+		if entryPattern.Operation.OperationType == createMapOpType {
+			keyExp = entryPattern.LHS
+			valuePattern = entryPattern.RHS
+		} else if entryPattern.Operation.OperationType != getVariableOpType {
+			return Context{}, fmt.Errorf("destructuring object entries must be in the form key: pattern, got %v instead", entryPattern.Operation.OperationType.Type)
+		}
+
+		key := keyExp.Operation.StringValue
+		if keyExp.Operation.OperationType != getVariableOpType {
+			keyResults, err := d.GetMatchingNodes(context.SingleReadonlyChildContext(mapCandidate), keyExp)
+			if err != nil {
+				return Context{}, err
+			}
+			if keyResults.MatchingNodes.Front() == nil {
+				return Context{}, fmt.Errorf("destructuring key expression returned no results")
+			}
+			key = unwrapDoc(keyResults.MatchingNodes.Front().Value.(*CandidateNode).Node).Value
+		}
+
+		child := mapCandidate.CreateChildInMap(&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key},
+			&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"})
+		if node.Kind == yaml.MappingNode {
+			matches, err := traverseMap(Context{}, mapCandidate, key, traversePreferences{DontAutoCreate: true}, false)
+			if err != nil {
+				return Context{}, err
+			}
+			if matches.Front() != nil {
+				child = matches.Front().Value.(*CandidateNode)
+			}
+		}
+
+		if keyExp.Operation.OperationType == getVariableOpType {
+			// {$name} and {$name: pattern} also bind the whole value to $name
+			context.SetVariable(key, child.AsList())
+		}
+		if valuePattern != keyExp {
+			context, err = bindPattern(d, context, valuePattern, child)
+			if err != nil {
+				return Context{}, err
+			}
+		}
 	}
-	context.SetVariable(variableName, variableValue)
 	return context, nil
 }
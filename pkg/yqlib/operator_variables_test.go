@@ -66,6 +66,122 @@ var variableOperatorScenarios = []expressionScenario{
 			"D0, P[], (doc)::a: {b: \"new\", c: something}\n",
 		},
 	},
+	{
+		description: "Destructuring arrays",
+		document:    `[cat, dog]`,
+		expression:  `. as [$first, $second] | {"first": $first, "second": $second}`,
+		expected: []string{
+			"D0, P[], (!!map)::first: cat\nsecond: dog\n",
+		},
+	},
+	{
+		description:    "Destructuring maps",
+		subdescription: "Patterns can be nested, and keys may be bare words, strings or expressions. Missing values are bound to null.",
+		document:       `{a: cat, b: [dog, mouse]}`,
+		expression:     `. as {a: $a, "b": [$b0], c: $c} | [$a, $b0, $c]`,
+		expected: []string{
+			"D0, P[], (!!seq)::- cat\n- dog\n- null\n",
+		},
+	},
+	{
+		description:    "Destructuring maps by variable name",
+		subdescription: "`{$name}` is shorthand for `{name: $name}`, and `{$name: pattern}` binds both the value and its contents.",
+		document:       `{name: Mike, pets: [cat]}`,
+		expression:     `. as {$name, $pets: [$firstPet]} | [$name, $pets, $firstPet]`,
+		expected: []string{
+			"D0, P[], (!!seq)::- Mike\n- [cat]\n- cat\n",
+		},
+	},
+	{
+		description:    "Destructuring each match",
+		subdescription: "When there are multiple matches, each one is destructured and the variables contain all the values.",
+		document:       `[{name: cat}, {name: dog}]`,
+		expression:     `.[] as {name: $n} | [$n]`,
+		expected: []string{
+			"D0, P[], (!!seq)::- cat\n- dog\n",
+		},
+	},
+	{
+		description:    "Destructuring alternatives",
+		subdescription: "Each pattern is tried in turn until one matches. Variables that are not in the matching pattern are set to null.",
+		document:       `[{a: 1}, [2]]`,
+		expression:     `.[] |= (. as {a: $a} ?// [$a] | $a)`,
+		expected: []string{
+			"D0, P[], (doc)::[1, 2]\n",
+		},
+	},
+	{
+		description:    "Destructuring alternatives when the expression fails",
+		subdescription: "Like jq, if the expression after the pattern fails, the next pattern is tried. The error from the last pattern is returned.",
+		document:       `[1, 2]`,
+		expression:     `. as [$a] ?// $a | $a | keys`,
+		expected: []string{
+			"D0, P[], (!!seq)::- 0\n- 1\n",
+		},
+	},
+	{
+		skipDoc:       true,
+		document:      `a: cat`,
+		expression:    `. as [$a] ?// $a | $a | .a | keys`,
+		expectedError: "Cannot get keys of !!str, keys only works for maps and arrays",
+	},
+	{
+		skipDoc:    true,
+		document:   `[5]`,
+		expression: `. as [$a] ?// $a | $a`,
+		expected: []string{
+			"D0, P[0], (!!int)::5\n",
+		},
+	},
+	{
+		skipDoc:    true,
+		document:   `5`,
+		expression: `. as [$a] ?// $a | $a`,
+		expected: []string{
+			"D0, P[], (doc)::5\n",
+		},
+	},
+	{
+		skipDoc:     true,
+		description: "the last binding of a repeated variable wins",
+		document:    `[5]`,
+		expression:  `. as [$a, $a] | $a`,
+		expected: []string{
+			"D0, P[1], (!!null)::null\n",
+		},
+	},
+	{
+		skipDoc:    true,
+		document:   `[3]`,
+		expression: `. as {a: $a} ?// [$b] ?// $c | [$a, $b, $c]`,
+		expected: []string{
+			"D0, P[], (!!seq)::- null\n- 3\n- null\n",
+		},
+	},
+	{
+		skipDoc:       true,
+		document:      `a: cat`,
+		expression:    `. as [$a] | $a`,
+		expectedError: "cannot destructure !!map as an array",
+	},
+	{
+		skipDoc:       true,
+		document:      `a: cat`,
+		expression:    `. as [$a] ?// [$b] | $a`,
+		expectedError: "cannot destructure !!map as an array",
+	},
+	{
+		skipDoc:       true,
+		document:      `a: cat`,
+		expression:    `. as .a`,
+		expectedError: `RHS of 'as' operator must be a variable name or destructuring pattern e.g. $foo, [$a, $b] or {"a": $a}`,
+	},
+	{
+		skipDoc:       true,
+		document:      `a: cat`,
+		expression:    `[1] ?// 2`,
+		expectedError: "?// can only be used as the RHS of 'as' e.g. . as [$a] ?// $a",
+	},
 }
 
 func TestVariableOperatorScenarios(t *testing.T) {
@@ -0,0 +1,150 @@
+package yqlib
+
+import (
+	"container/list"
+	"fmt"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+type walker struct {
+	d       *dataTreeNavigator
+	context Context
+	walkExp *ExpressionNode
+	// anchored nodes that have been rebuilt, so aliases can be pointed at the new versions
+	rebuiltAnchors map[*yaml.Node]*yaml.Node
+}
+
+// walk rebuilds the candidate bottom up, applying the expression to each node after its children.
+// Nodes are shallow copied rather than updated in place, so comments, anchors and styles are kept
+// on anything the expression leaves alone. Aliases are not passed to the expression, instead they
+// are updated to refer to the rebuilt anchored node.
+func (w *walker) walk(candidate *CandidateNode) ([]*CandidateNode, error) {
+	original := candidate.Node
+	if original.Kind == yaml.DocumentNode {
+		walked, err := w.walk(candidate.CreateReplacement(original.Content[0]))
+		if err != nil {
+			return nil, err
+		}
+		// keep the document (and its comments) wrapping the results
+		for i, result := range walked {
+			document := *original
+			document.Content = []*yaml.Node{result.Node}
+			walked[i] = result.CreateReplacement(&document)
+		}
+		return walked, nil
+	}
+
+	copied := *original
+	rebuilt := candidate.CreateReplacement(&copied)
+
+	switch original.Kind {
+	case yaml.AliasNode:
+		if anchor, found := w.rebuiltAnchors[original.Alias]; found {
+			copied.Alias = anchor
+		}
+		return []*CandidateNode{rebuilt}, nil
+	case yaml.MappingNode:
+		copied.Content = make([]*yaml.Node, 0, len(original.Content))
+		for index := 0; index < len(original.Content); index = index + 2 {
+			key := original.Content[index]
+			walkedValues, err := w.walk(candidate.CreateChildInMap(key, original.Content[index+1]))
+			if err != nil {
+				return nil, err
+			}
+			// like map_values, the first result is used and no results removes the entry
+			if len(walkedValues) > 0 {
+				copied.Content = append(copied.Content, key, walkedValues[0].Node)
+			}
+		}
+	case yaml.SequenceNode:
+		copied.Content = make([]*yaml.Node, 0, len(original.Content))
+		for index, child := range original.Content {
+			walkedValues, err := w.walk(candidate.CreateChildInArray(index, child))
+			if err != nil {
+				return nil, err
+			}
+			for _, walkedValue := range walkedValues {
+				copied.Content = append(copied.Content, walkedValue.Node)
+			}
+		}
+	}
+
+	results, err := getUpdateResults(w.d, w.context, rebuilt, w.walkExp)
+	if err != nil {
+		return nil, err
+	}
+	if original.Anchor != "" && len(results) > 0 {
+		anchored := results[0].Node
+		if anchored.Anchor == "" && anchored.Kind != yaml.AliasNode {
+			// a new node from the expression needs the anchor, as the aliases will refer to it
+			withAnchor := *anchored
+			withAnchor.Anchor = original.Anchor
+			anchored = &withAnchor
+			results[0] = results[0].CreateReplacement(anchored)
+		}
+		w.rebuiltAnchors[original] = anchored
+	}
+	return results, nil
+}
+
+func walkOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	log.Debugf("-- walkOperator")
+	var results = list.New()
+	for el := context.MatchingNodes.Front(); el != nil; el = el.Next() {
+		candidate := el.Value.(*CandidateNode)
+		w := &walker{d: d, context: context, walkExp: expressionNode.RHS, rebuiltAnchors: make(map[*yaml.Node]*yaml.Node)}
+		walked, err := w.walk(candidate)
+		if err != nil {
+			return Context{}, err
+		}
+		for _, result := range walked {
+			result.LeadingContent = candidate.LeadingContent
+			results.PushBack(result)
+		}
+	}
+	return context.ChildContext(results), nil
+}
+
+func recurseOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	log.Debugf("-- recurseOperator")
+	// like .. but does not follow aliases
+	var results = list.New()
+	for el := context.MatchingNodes.Front(); el != nil; el = el.Next() {
+		stack := []*CandidateNode{el.Value.(*CandidateNode)}
+		for len(stack) > 0 {
+			current := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			results.PushBack(current)
+			stack = pushInOrder(stack, getChildCandidates(current))
+		}
+	}
+	return context.ChildContext(results), nil
+}
+
+func recurseWithOperator(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode) (Context, error) {
+	log.Debugf("-- recurseWithOperator")
+	// recurse(f) or recurse(f; cond)
+	updateExp := expressionNode.RHS
+	if updateExp.Operation.OperationType == blockOpType {
+		params := flattenBlock(updateExp)
+		if len(params) != 2 {
+			return Context{}, fmt.Errorf("recurse expects at most 2 parameters, got %v", len(params))
+		}
+		// recurse(f; cond) is recurse(f | select(cond))
+		updateExp = &ExpressionNode{
+			Operation: &Operation{OperationType: pipeOpType},
+			LHS:       params[0],
+			RHS:       &ExpressionNode{Operation: &Operation{OperationType: selectOpType}, RHS: params[1]},
+		}
+	}
+
+	results := list.New()
+	for el := context.MatchingNodes.Front(); el != nil; el = el.Next() {
+		err := repeat(d, context, el.Value.(*CandidateNode), updateExp, results, -1)
+		if err != nil {
+			return Context{}, err
+		}
+	}
+	return context.ChildContext(results), nil
+}
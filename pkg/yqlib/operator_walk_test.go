@@ -0,0 +1,89 @@
+package yqlib
+
+import (
+	"testing"
+)
+
+var walkOperatorScenarios = []expressionScenario{
+	{
+		description:    "Sort every array",
+		subdescription: "Walk applies the expression to every node, bottom up (children before their parents).",
+		document:       `{a: [c, b, a], b: {c: [3, 1, 2]}}`,
+		expression:     `walk(select(type == "array") |= sort)`,
+		expected: []string{
+			"D0, P[], (doc)::{a: [a, b, c], b: {c: [1, 2, 3]}}\n",
+		},
+	},
+	{
+		description:    "Remove nulls everywhere",
+		subdescription: "Map entries and array elements are removed when the expression returns nothing for them.",
+		document:       "a: null\nb:\n  c: cat # keep me\n  d: ~\n  e: [1, null, 2]\n",
+		expression:     `walk(select(. != null))`,
+		expected: []string{
+			"D0, P[], (doc)::b:\n    c: cat # keep me\n    e: [1, 2]\n",
+		},
+	},
+	{
+		description:    "Walk with aliases",
+		subdescription: "Aliases are not walked, rather they refer to the walked anchored node.",
+		document:       "a: &x [b, a]\nb: *x\n",
+		expression:     `walk(select(type == "array") |= sort) | (., .b[0])`,
+		expected: []string{
+			"D0, P[], (doc)::a: &x [a, b]\nb: *x\n",
+			"D0, P[b 0], (!!str)::a\n",
+		},
+	},
+	{
+		description: "Walk replacing an anchored node",
+		skipDoc:     true,
+		document:    "a: &x [3, 1]\nb: *x\n",
+		expression:  `walk((select(tag == "!!seq") | sort) // .) | (., .b[0])`,
+		expected: []string{
+			"D0, P[], (doc)::a: &x [1, 3]\nb: *x\n",
+			"D0, P[b 0], (!!int)::1\n",
+		},
+	},
+	{
+		skipDoc:    true,
+		document:   `{a: [1, 2]}`,
+		expression: `walk(select(tag == "!!int") |= . * 10), .`,
+		expected: []string{
+			"D0, P[], (doc)::{a: [10, 20]}\n",
+			"D0, P[], (doc)::{a: [1, 2]}\n",
+		},
+	},
+	{
+		description:    "Recurse",
+		subdescription: "Without arguments, recurse is the same as `..` but does not follow aliases.",
+		document:       `{a: [b]}`,
+		expression:     `[recurse | kind]`,
+		expected: []string{
+			"D0, P[], (!!seq)::- map\n- seq\n- scalar\n",
+		},
+	},
+	{
+		description:    "Recurse with an expression",
+		subdescription: "Outputs the node, then recursively the results of applying the expression to it",
+		document:       `{name: a, child: {name: b, child: {name: c}}}`,
+		expression:     `[recurse(.child | select(. != null)) | .name]`,
+		expected: []string{
+			"D0, P[], (!!seq)::- a\n- b\n- c\n",
+		},
+	},
+	{
+		description:    "Recurse with a condition",
+		subdescription: "Stops recursing once the condition is false",
+		document:       `2`,
+		expression:     `[recurse(. * .; . != 256)]`,
+		expected: []string{
+			"D0, P[], (!!seq)::- 2\n- 4\n- 16\n",
+		},
+	},
+}
+
+func TestWalkOperatorScenarios(t *testing.T) {
+	for _, tt := range walkOperatorScenarios {
+		testScenario(t, &tt)
+	}
+	documentOperatorScenarios(t, "walk", walkOperatorScenarios)
+}
@@ -13,6 +13,14 @@ type operatorHandler func(d *dataTreeNavigator, context Context, expressionNode
 type compoundCalculation func(lhs *ExpressionNode, rhs *ExpressionNode) *ExpressionNode
 
 func compoundAssignFunction(d *dataTreeNavigator, context Context, expressionNode *ExpressionNode, calculation compoundCalculation) (Context, error) {
+	if isSliceExpression(expressionNode.LHS) {
+		err := updateSlices(d, context, expressionNode.LHS, func(slice *CandidateNode) (*CandidateNode, error) {
+			valueExpression := &ExpressionNode{Operation: &Operation{OperationType: valueOpType, CandidateNode: slice}}
+			return firstResult(d, context.ReadOnlyClone(), calculation(valueExpression, expressionNode.RHS))
+		})
+		return context, err
+	}
+
 	lhs, err := d.GetMatchingNodes(context, expressionNode.LHS)
 	if err != nil {
 		return Context{}, err
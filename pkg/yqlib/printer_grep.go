@@ -0,0 +1,96 @@
+package yqlib
+
+import (
+	"container/list"
+	"fmt"
+	"io"
+)
+
+type GrepOutputMode uint
+
+const (
+	// GrepMatches prints each match, e.g. "file.yml:0:3: .a.b = cat"
+	GrepMatches GrepOutputMode = 1 << iota
+	// GrepFilesWithMatches prints the name of each file with a match
+	GrepFilesWithMatches
+	// GrepCount prints the number of matches in each file, e.g. "file.yml:2"
+	GrepCount
+)
+
+// GrepPrinter prints the results that aren't null or false, like grep prints matching lines.
+type GrepPrinter interface {
+	Printer
+	// FinishFile prints the file's name or count in the GrepFilesWithMatches and GrepCount modes.
+	FinishFile(filename string) error
+}
+
+type grepPrinter struct {
+	printerWriter PrinterWriter
+	mode          GrepOutputMode
+	fileMatches   int
+	totalMatches  int
+}
+
+func NewGrepPrinter(printerWriter PrinterWriter, mode GrepOutputMode) GrepPrinter {
+	return &grepPrinter{printerWriter: printerWriter, mode: mode}
+}
+
+// SetAppendix does nothing, the appendix isn't a match.
+func (p *grepPrinter) SetAppendix(reader io.Reader) {}
+
+func (p *grepPrinter) PrintedAnything() bool {
+	return p.totalMatches > 0
+}
+
+func (p *grepPrinter) print(candidate *CandidateNode, line string) error {
+	writer, err := p.printerWriter.GetWriter(candidate)
+	if err != nil {
+		return err
+	}
+	if err := writeString(writer, line); err != nil {
+		return err
+	}
+	return writer.Flush()
+}
+
+func (p *grepPrinter) PrintResults(matchingNodes *list.List) error {
+	for el := matchingNodes.Front(); el != nil; el = el.Next() {
+		candidate := el.Value.(*CandidateNode)
+		node := unwrapDoc(candidate.Node)
+		if node.Tag == "!!null" || (node.Tag == "!!bool" && node.Value == "false") {
+			continue
+		}
+		p.fileMatches++
+		p.totalMatches++
+		if p.mode != GrepMatches {
+			continue
+		}
+
+		line := candidate.GetLine()
+		if line == 0 {
+			// a computed value, e.g. from `.image == "foo"`, is reported where the value it came from is
+			line = candidate.GetKeyLine()
+		}
+		value, err := formatFlowNode(node)
+		if err != nil {
+			return err
+		}
+		output := fmt.Sprintf("%v:%v:%v: %v = %v\n", candidate.Filename, candidate.Document, line, PathToString(candidate.Path), value)
+		if err := p.print(candidate, output); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *grepPrinter) FinishFile(filename string) error {
+	matches := p.fileMatches
+	p.fileMatches = 0
+	candidate := &CandidateNode{Filename: filename}
+	if p.mode == GrepCount {
+		return p.print(candidate, fmt.Sprintf("%v:%v\n", filename, matches))
+	} else if p.mode == GrepFilesWithMatches && matches > 0 {
+		return p.print(candidate, fmt.Sprintf("%v\n", filename))
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package yqlib
+
+import (
+	"container/list"
+	"fmt"
+	"io"
+)
+
+type positionPrinter struct {
+	printerWriter  PrinterWriter
+	printedMatches bool
+}
+
+// NewPositionPrinter creates a printer that prints each result on one line, with where it is
+// in its file, e.g. "file.yml:3:5: .a.b = cat". This is the format editors expect from grep-like tools.
+func NewPositionPrinter(printerWriter PrinterWriter) Printer {
+	return &positionPrinter{printerWriter: printerWriter}
+}
+
+// SetAppendix does nothing, the appendix isn't a result so has no position.
+func (p *positionPrinter) SetAppendix(reader io.Reader) {}
+
+func (p *positionPrinter) PrintedAnything() bool {
+	return p.printedMatches
+}
+
+// FormatPosition formats where the candidate is, e.g. "file.yml:3:5".
+func FormatPosition(candidate *CandidateNode) string {
+	return fmt.Sprintf("%v:%v:%v", candidate.Filename, candidate.GetLine(), candidate.GetColumn())
+}
+
+func (p *positionPrinter) PrintResults(matchingNodes *list.List) error {
+	for el := matchingNodes.Front(); el != nil; el = el.Next() {
+		candidate := el.Value.(*CandidateNode)
+		node := unwrapDoc(candidate.Node)
+		p.printedMatches = p.printedMatches || (node.Tag != "!!null" &&
+			(node.Tag != "!!bool" || node.Value != "false"))
+
+		writer, err := p.printerWriter.GetWriter(candidate)
+		if err != nil {
+			return err
+		}
+		value, err := formatFlowNode(node)
+		if err != nil {
+			return err
+		}
+		if err := writeString(writer, fmt.Sprintf("%v: %v = %v\n", FormatPosition(candidate), PathToString(candidate.Path), value)); err != nil {
+			return err
+		}
+		if err := writer.Flush(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -336,3 +336,110 @@ func TestPrinterMultipleDocsJson(t *testing.T) {
 	writer.Flush()
 	test.AssertResult(t, expected, output.String())
 }
+
+func TestPositionPrinter(t *testing.T) {
+	var output bytes.Buffer
+	var writer = bufio.NewWriter(&output)
+	printer := NewPositionPrinter(NewSinglePrinterWriter(writer))
+
+	inputs, err := readDocumentWithLeadingContent("# go cats\n---\na: banana\n---\nb: [apple, {c: coconut}]\n", "sample.yml", 0)
+	if err != nil {
+		panic(err)
+	}
+
+	results := list.New()
+	for el := inputs.Front(); el != nil; el = el.Next() {
+		for _, child := range getChildCandidates(el.Value.(*CandidateNode)) {
+			results.PushBack(child)
+		}
+	}
+	results.PushBack(inputs.Front().Value.(*CandidateNode).CreateReplacement(createScalarNode(false, "false")))
+
+	err = printer.PrintResults(results)
+	if err != nil {
+		panic(err)
+	}
+
+	// line numbers include the leading content
+	expected := `sample.yml:3:4: .a = banana
+sample.yml:5:4: .b = [apple, {c: coconut}]
+sample.yml:0:0: . = false
+`
+
+	writer.Flush()
+	test.AssertResult(t, expected, output.String())
+	test.AssertResult(t, true, printer.PrintedAnything())
+}
+
+func TestGrepPrinter(t *testing.T) {
+	var output bytes.Buffer
+	var writer = bufio.NewWriter(&output)
+	printer := NewGrepPrinter(NewSinglePrinterWriter(writer), GrepMatches)
+
+	inputs, err := readDocumentWithLeadingContent("a: banana\n---\nb: [apple, {c: coconut}]\nc: ~\n", "sample.yml", 0)
+	if err != nil {
+		panic(err)
+	}
+
+	results := list.New()
+	for el := inputs.Front(); el != nil; el = el.Next() {
+		for _, child := range getChildCandidates(el.Value.(*CandidateNode)) {
+			results.PushBack(child)
+		}
+	}
+	a := results.Front().Value.(*CandidateNode)
+	results.PushBack(a.CreateReplacement(createScalarNode(true, "true")))
+	results.PushBack(a.CreateReplacement(createScalarNode(false, "false")))
+
+	err = printer.PrintResults(results)
+	if err != nil {
+		panic(err)
+	}
+	err = printer.FinishFile("sample.yml")
+	if err != nil {
+		panic(err)
+	}
+
+	// null and false aren't matches, computed values are on the line of their key
+	expected := `sample.yml:0:1: .a = banana
+sample.yml:1:3: .b = [apple, {c: coconut}]
+sample.yml:0:1: .a = true
+`
+
+	writer.Flush()
+	test.AssertResult(t, expected, output.String())
+	test.AssertResult(t, true, printer.PrintedAnything())
+}
+
+func TestGrepPrinterCount(t *testing.T) {
+	var output bytes.Buffer
+	var writer = bufio.NewWriter(&output)
+	printer := NewGrepPrinter(NewSinglePrinterWriter(writer), GrepCount)
+
+	inputs, err := readDocumentWithLeadingContent("a: banana\nb: ~\nc: cat\n", "sample.yml", 0)
+	if err != nil {
+		panic(err)
+	}
+
+	results := list.New()
+	for _, child := range getChildCandidates(inputs.Front().Value.(*CandidateNode)) {
+		results.PushBack(child)
+	}
+	err = printer.PrintResults(results)
+	if err != nil {
+		panic(err)
+	}
+	// files without matches are counted too, like grep does
+	for _, filename := range []string{"sample.yml", "empty.yml"} {
+		if err := printer.FinishFile(filename); err != nil {
+			panic(err)
+		}
+	}
+
+	expected := `sample.yml:2
+empty.yml:0
+`
+
+	writer.Flush()
+	test.AssertResult(t, expected, output.String())
+}
@@ -43,7 +43,11 @@ func (s *streamEvaluator) EvaluateNew(expression string, printer Printer, leadin
 	inputList := list.New()
 	inputList.PushBack(candidateNode)
 
-	result, errorParsing := s.treeNavigator.GetMatchingNodes(Context{MatchingNodes: inputList}, node)
+	context, err := NamedArguments.createContext(inputList)
+	if err != nil {
+		return err
+	}
+	result, errorParsing := s.treeNavigator.GetMatchingNodes(context, node)
 	if errorParsing != nil {
 		return errorParsing
 	}
@@ -115,11 +119,15 @@ func (s *streamEvaluator) Evaluate(filename string, reader io.Reader, node *Expr
 		inputList := list.New()
 		inputList.PushBack(candidateNode)
 
-		result, errorParsing := s.treeNavigator.GetMatchingNodes(Context{MatchingNodes: inputList}, node)
+		context, err := NamedArguments.createContext(inputList)
+		if err != nil {
+			return currentIndex, err
+		}
+		result, errorParsing := s.treeNavigator.GetMatchingNodes(context, node)
 		if errorParsing != nil {
 			return currentIndex, errorParsing
 		}
-		err := printer.PrintResults(result.MatchingNodes)
+		err = printer.PrintResults(result.MatchingNodes)
 
 		if err != nil {
 			return currentIndex, err
@@ -38,34 +38,43 @@ func writeString(writer io.Writer, txt string) error {
 	return errorWriting
 }
 
+// processReadStream reads the leading comments and document separators, which yaml.v3 would lose, so that they can be
+// printed before the results.
 func processReadStream(reader *bufio.Reader) (io.Reader, string, error) {
+	leadingContent, err := readLeadingContent(reader)
+	// a blank line for each line read, so the line numbers of the nodes are the same as in the file
+	blankLines := strings.NewReader(strings.Repeat("\n", strings.Count(leadingContent, "\n")))
+	return io.MultiReader(blankLines, reader), leadingContent, err
+}
+
+func readLeadingContent(reader *bufio.Reader) (string, error) {
 	var commentLineRegEx = regexp.MustCompile(`^\s*#`)
 	var sb strings.Builder
 	for {
 		peekBytes, err := reader.Peek(3)
 		if errors.Is(err, io.EOF) {
 			// EOF are handled else where..
-			return reader, sb.String(), nil
+			return sb.String(), nil
 		} else if err != nil {
-			return reader, sb.String(), err
+			return sb.String(), err
 		} else if string(peekBytes) == "---" {
 			_, err := reader.ReadString('\n')
 			sb.WriteString("$yqDocSeperator$\n")
 			if errors.Is(err, io.EOF) {
-				return reader, sb.String(), nil
+				return sb.String(), nil
 			} else if err != nil {
-				return reader, sb.String(), err
+				return sb.String(), err
 			}
 		} else if commentLineRegEx.MatchString(string(peekBytes)) {
 			line, err := reader.ReadString('\n')
 			sb.WriteString(line)
 			if errors.Is(err, io.EOF) {
-				return reader, sb.String(), nil
+				return sb.String(), nil
 			} else if err != nil {
-				return reader, sb.String(), err
+				return sb.String(), err
 			}
 		} else {
-			return reader, sb.String(), nil
+			return sb.String(), nil
 		}
 	}
 }
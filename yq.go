@@ -1,6 +1,7 @@
 package main
 
 import (
+	"fmt"
 	"os"
 
 	command "github.com/mikefarah/yq/v4/cmd"
@@ -9,17 +10,22 @@ import (
 func main() {
 	cmd := command.New()
 
-	args := os.Args[1:]
+	args, err := command.ExtractNamedArguments(cmd, os.Args[1:])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	cmd.SetArgs(args)
 
-	_, _, err := cmd.Find(args)
+	_, _, err = cmd.Find(args)
 	if err != nil {
 		// default command when nothing matches...
 		newArgs := []string{"eval"}
-		cmd.SetArgs(append(newArgs, os.Args[1:]...))
+		cmd.SetArgs(append(newArgs, args...))
 
 	}
 
 	if err := cmd.Execute(); err != nil {
-		os.Exit(1)
+		os.Exit(command.GetExitStatus(err))
 	}
 }